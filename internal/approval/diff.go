@@ -0,0 +1,118 @@
+package approval
+
+import (
+	"strings"
+)
+
+// diffLine is one line of a line diff: op is ' ', '-' or '+'.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// maxDiffCells bounds the LCS table; larger inputs are shown as a full
+// removal followed by a full addition.
+const maxDiffCells = 4_000_000
+
+// lineDiff computes a line diff of before and after.
+func lineDiff(before, after string) []diffLine {
+	a, b := splitLines(before), splitLines(after)
+	if len(a)*len(b) > maxDiffCells {
+		lines := make([]diffLine, 0, len(a)+len(b))
+		for _, line := range a {
+			lines = append(lines, diffLine{'-', line})
+		}
+		for _, line := range b {
+			lines = append(lines, diffLine{'+', line})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffContext is the number of unchanged lines kept around each change.
+const diffContext = 3
+
+// renderDiff writes a diff, collapsing long unchanged runs, with optional
+// ANSI colors. Line text is escaped with escapeLine.
+func renderDiff(b *strings.Builder, lines []diffLine, color bool) {
+	changed := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.op != ' ' {
+			for k := max(0, i-diffContext); k <= min(len(lines)-1, i+diffContext); k++ {
+				changed[k] = true
+			}
+		}
+	}
+	skipped := false
+	for i, line := range lines {
+		if !changed[i] {
+			if !skipped {
+				b.WriteString("  ...\n")
+				skipped = true
+			}
+			continue
+		}
+		skipped = false
+		prefix, suffix := "", ""
+		if color {
+			switch line.op {
+			case '-':
+				prefix, suffix = "\x1b[31m", "\x1b[0m"
+			case '+':
+				prefix, suffix = "\x1b[32m", "\x1b[0m"
+			}
+		}
+		b.WriteString(prefix)
+		b.WriteByte(line.op)
+		b.WriteByte(' ')
+		b.WriteString(escapeLine(line.text))
+		b.WriteString(suffix)
+		b.WriteByte('\n')
+	}
+}
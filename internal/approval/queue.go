@@ -0,0 +1,423 @@
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// Outcomes recorded for queued requests.
+const (
+	OutcomeApproved  = "approved"
+	OutcomeDenied    = "denied"
+	OutcomeTimeout   = "timeout"
+	OutcomeCancelled = "cancelled"
+)
+
+// DefaultQueueTimeout is how long a request waits for a reviewer when
+// QueueOptions.Timeout is zero.
+const DefaultQueueTimeout = 5 * time.Minute
+
+// ErrRequestNotFound is returned by Queue.Resolve for an unknown or already
+// decided request.
+var ErrRequestNotFound = errors.New("approval request not found")
+
+// QueueOptions configures a Queue.
+type QueueOptions struct {
+	// Timeout is how long a request waits before it is denied. Zero means
+	// DefaultQueueTimeout; negative means wait indefinitely.
+	Timeout time.Duration
+	// Token must be presented as "Authorization: Bearer <token>" on every
+	// API request. When empty, NewQueue generates a random token; read it
+	// with Queue.Token.
+	Token string
+	// Audit receives one JSON Record per line for every finished request.
+	Audit io.Writer
+	// OnRequest is called when a request is queued, e.g. to notify
+	// reviewers.
+	OnRequest func(request PendingRequest)
+	// HistorySize bounds the records kept for GET /decisions. Zero means
+	// 1000.
+	HistorySize int
+}
+
+// PendingRequest is a permission request waiting for a reviewer.
+type PendingRequest struct {
+	ID             string                    `json:"id"`
+	ToolName       string                    `json:"tool_name"`
+	Input          map[string]any            `json:"input"`
+	Title          string                    `json:"title,omitempty"`
+	Description    string                    `json:"description,omitempty"`
+	DecisionReason string                    `json:"decision_reason,omitempty"`
+	BlockedPath    string                    `json:"blocked_path,omitempty"`
+	ToolUseID      string                    `json:"tool_use_id,omitempty"`
+	AgentID        string                    `json:"agent_id,omitempty"`
+	Suggestions    []shared.PermissionUpdate `json:"suggestions,omitempty"`
+	CreatedAt      time.Time                 `json:"created_at"`
+	ExpiresAt      *time.Time                `json:"expires_at,omitempty"`
+}
+
+// Resolution is a reviewer's answer to a pending request.
+type Resolution struct {
+	// Approve allows the call; otherwise it is denied.
+	Approve bool `json:"-"`
+	// Message is the deny reason shown to Claude.
+	Message string `json:"message,omitempty"`
+	// Interrupt stops the run on deny.
+	Interrupt bool `json:"interrupt,omitempty"`
+	// UpdatedInput replaces the tool input on approve.
+	UpdatedInput map[string]any `json:"updated_input,omitempty"`
+	// UpdatedPermissions are permission updates applied on approve.
+	UpdatedPermissions []shared.PermissionUpdate `json:"updated_permissions,omitempty"`
+	// Reviewer identifies who decided, for the audit trail.
+	Reviewer string `json:"reviewer,omitempty"`
+}
+
+// Record is the audit entry of a finished request.
+type Record struct {
+	PendingRequest
+	Outcome      string         `json:"outcome"`
+	Message      string         `json:"message,omitempty"`
+	UpdatedInput map[string]any `json:"updated_input,omitempty"`
+	Reviewer     string         `json:"reviewer,omitempty"`
+	DecidedAt    time.Time      `json:"decided_at"`
+}
+
+type queuedRequest struct {
+	PendingRequest
+	seq      int64
+	resolved chan Resolution
+}
+
+// Queue parks permission requests until a reviewer resolves them through
+// Resolve or the HTTP API returned by Handler.
+type Queue struct {
+	opts QueueOptions
+
+	mu      sync.Mutex
+	pending map[string]*queuedRequest
+	history []Record
+	nextSeq int64
+
+	auditMu sync.Mutex
+}
+
+// NewQueue creates an approval queue.
+func NewQueue(opts QueueOptions) *Queue {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultQueueTimeout
+	}
+	if opts.HistorySize <= 0 {
+		opts.HistorySize = 1000
+	}
+	if opts.Token == "" {
+		opts.Token = randomHex(32)
+	}
+	return &Queue{opts: opts, pending: make(map[string]*queuedRequest)}
+}
+
+// Token returns the bearer token the HTTP API requires.
+func (q *Queue) Token() string {
+	return q.opts.Token
+}
+
+// randomHex returns n random bytes, hex encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("approval: failed to read random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// CanUseTool queues the request and waits for a reviewer. It denies the
+// call when Timeout elapses and abandons it when the request is aborted,
+// e.g. by a control_cancel_request from the CLI.
+func (q *Queue) CanUseTool(toolName string, input map[string]any, permCtx shared.ToolPermissionContext) (shared.PermissionResult, error) {
+	ctx := permCtx.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	request := q.enqueue(toolName, input, permCtx)
+	if q.opts.OnRequest != nil {
+		q.opts.OnRequest(request.PendingRequest)
+	}
+
+	var expired <-chan time.Time
+	if q.opts.Timeout > 0 {
+		timer := time.NewTimer(q.opts.Timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case resolution := <-request.resolved:
+		shared.RecordApprover(ctx, reviewerApprover(resolution))
+		return resolutionResult(resolution, input), nil
+
+	case <-expired:
+		message := fmt.Sprintf("No reviewer decision within %s.", q.opts.Timeout)
+		if q.finish(request.ID, OutcomeTimeout, Resolution{Message: message}) {
+			shared.RecordApprover(ctx, "queue:timeout")
+			return shared.NewPermissionDeny(message, false), nil
+		}
+		// A reviewer resolved it at the same moment.
+		resolution := <-request.resolved
+		shared.RecordApprover(ctx, reviewerApprover(resolution))
+		return resolutionResult(resolution, input), nil
+
+	case <-ctx.Done():
+		message := "Permission request aborted."
+		if cause := context.Cause(ctx); cause != nil {
+			message = fmt.Sprintf("Permission request aborted: %v.", cause)
+		}
+		if q.finish(request.ID, OutcomeCancelled, Resolution{Message: message}) {
+			shared.RecordApprover(ctx, "queue:cancelled")
+			return shared.NewPermissionDeny(message, false), nil
+		}
+		resolution := <-request.resolved
+		shared.RecordApprover(ctx, reviewerApprover(resolution))
+		return resolutionResult(resolution, input), nil
+	}
+}
+
+// reviewerApprover names the reviewer of a resolution for audit records.
+func reviewerApprover(resolution Resolution) string {
+	if resolution.Reviewer == "" {
+		return "queue"
+	}
+	return "queue:" + resolution.Reviewer
+}
+
+// resolutionResult converts a reviewer's resolution into a permission
+// result.
+func resolutionResult(resolution Resolution, input map[string]any) shared.PermissionResult {
+	if resolution.Approve {
+		if resolution.UpdatedInput != nil {
+			input = resolution.UpdatedInput
+		}
+		return shared.NewPermissionAllow(input, resolution.UpdatedPermissions)
+	}
+	message := resolution.Message
+	if message == "" {
+		message = "Denied by reviewer."
+	}
+	return shared.NewPermissionDeny(message, resolution.Interrupt)
+}
+
+func (q *Queue) enqueue(toolName string, input map[string]any, permCtx shared.ToolPermissionContext) *queuedRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.nextSeq++
+	now := time.Now()
+	request := &queuedRequest{
+		PendingRequest: PendingRequest{
+			ID:             "req_" + randomHex(12),
+			ToolName:       toolName,
+			Input:          input,
+			Title:          deref(permCtx.Title),
+			Description:    deref(permCtx.Description),
+			DecisionReason: deref(permCtx.DecisionReason),
+			BlockedPath:    deref(permCtx.BlockedPath),
+			ToolUseID:      deref(permCtx.ToolUseID),
+			AgentID:        deref(permCtx.AgentID),
+			Suggestions:    permCtx.Suggestions,
+			CreatedAt:      now,
+		},
+		seq:      q.nextSeq,
+		resolved: make(chan Resolution, 1),
+	}
+	if q.opts.Timeout > 0 {
+		expires := now.Add(q.opts.Timeout)
+		request.ExpiresAt = &expires
+	}
+	q.pending[request.ID] = request
+	return request
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Pending returns the requests waiting for a reviewer, oldest first.
+func (q *Queue) Pending() []PendingRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queued := make([]*queuedRequest, 0, len(q.pending))
+	for _, request := range q.pending {
+		queued = append(queued, request)
+	}
+	sort.Slice(queued, func(i, j int) bool { return queued[i].seq < queued[j].seq })
+	requests := make([]PendingRequest, len(queued))
+	for i, request := range queued {
+		requests[i] = request.PendingRequest
+	}
+	return requests
+}
+
+// History returns the most recent finished requests, oldest first.
+func (q *Queue) History() []Record {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]Record(nil), q.history...)
+}
+
+// Resolve answers a pending request. It returns ErrRequestNotFound if the
+// request is unknown or already finished.
+func (q *Queue) Resolve(id string, resolution Resolution) error {
+	outcome := OutcomeDenied
+	if resolution.Approve {
+		outcome = OutcomeApproved
+	}
+	if !q.finish(id, outcome, resolution) {
+		return ErrRequestNotFound
+	}
+	return nil
+}
+
+// finish removes a pending request, records its outcome and, for reviewer
+// outcomes, hands the resolution to the waiting callback. It reports
+// whether the request was still pending.
+func (q *Queue) finish(id, outcome string, resolution Resolution) bool {
+	q.mu.Lock()
+	request, ok := q.pending[id]
+	if !ok {
+		q.mu.Unlock()
+		return false
+	}
+	delete(q.pending, id)
+	record := Record{
+		PendingRequest: request.PendingRequest,
+		Outcome:        outcome,
+		Message:        resolution.Message,
+		UpdatedInput:   resolution.UpdatedInput,
+		Reviewer:       resolution.Reviewer,
+		DecidedAt:      time.Now(),
+	}
+	q.history = append(q.history, record)
+	if len(q.history) > q.opts.HistorySize {
+		q.history = q.history[len(q.history)-q.opts.HistorySize:]
+	}
+	q.mu.Unlock()
+
+	if outcome == OutcomeApproved || outcome == OutcomeDenied {
+		request.resolved <- resolution
+	}
+	q.audit(record)
+	return true
+}
+
+func (q *Queue) audit(record Record) {
+	if q.opts.Audit == nil {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	q.auditMu.Lock()
+	defer q.auditMu.Unlock()
+	_, _ = q.opts.Audit.Write(append(data, '\n'))
+}
+
+// Handler returns the queue's HTTP/JSON API:
+//
+//	GET  /requests               pending requests, oldest first
+//	GET  /requests/{id}          one pending request
+//	POST /requests/{id}/approve  {"updated_input":{...},"updated_permissions":[...],"reviewer":"..."}
+//	POST /requests/{id}/deny     {"message":"...","interrupt":false,"reviewer":"..."}
+//	GET  /decisions              recent finished requests
+//
+// Every request needs the bearer token (see Token), and POSTs must be sent
+// as Content-Type application/json, which browsers cannot send cross-site
+// without a preflight. Request bodies are optional. Resolving an unknown
+// or finished request returns 404.
+func (q *Queue) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /requests", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, q.Pending())
+	})
+	mux.HandleFunc("GET /requests/{id}", func(w http.ResponseWriter, r *http.Request) {
+		q.mu.Lock()
+		request, ok := q.pending[r.PathValue("id")]
+		q.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, ErrRequestNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, request.PendingRequest)
+	})
+	mux.HandleFunc("POST /requests/{id}/approve", func(w http.ResponseWriter, r *http.Request) {
+		q.serveResolve(w, r, true)
+	})
+	mux.HandleFunc("POST /requests/{id}/deny", func(w http.ResponseWriter, r *http.Request) {
+		q.serveResolve(w, r, false)
+	})
+	mux.HandleFunc("GET /decisions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, q.History())
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !q.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (q *Queue) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(q.opts.Token)) == 1
+}
+
+func (q *Queue) serveResolve(w http.ResponseWriter, r *http.Request, approve bool) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, errors.New("Content-Type must be application/json"))
+		return
+	}
+	var resolution Resolution
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &resolution); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+			return
+		}
+	}
+	resolution.Approve = approve
+	if err := q.Resolve(r.PathValue("id"), resolution); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
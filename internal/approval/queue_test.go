@@ -0,0 +1,224 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// waitPending polls until n requests are queued.
+func waitPending(t *testing.T, q *Queue, n int) []PendingRequest {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if pending := q.Pending(); len(pending) == n {
+			return pending
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d pending requests, have %d", n, len(q.Pending()))
+	return nil
+}
+
+// safeBuffer is a bytes.Buffer safe for concurrent writes.
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) records(t *testing.T) []Record {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var records []Record
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var record Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("audit line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestQueueHTTPApproveAndDeny(t *testing.T) {
+	audit := &safeBuffer{}
+	notified := make(chan PendingRequest, 2)
+	q := NewQueue(QueueOptions{Token: "secret", Audit: audit, OnRequest: func(r PendingRequest) { notified <- r }})
+	server := httptest.NewServer(q.Handler())
+	defer server.Close()
+
+	call := func(method, path, body string) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		if method == http.MethodPost {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	results := make(chan shared.PermissionResult, 2)
+	title := "Claude wants to run rm"
+	go func() {
+		result, _ := q.CanUseTool("Bash", map[string]any{"command": "rm -rf build"}, shared.ToolPermissionContext{Title: &title})
+		results <- result
+	}()
+	pending := waitPending(t, q, 1)
+	if first := <-notified; first.ID != pending[0].ID || first.ExpiresAt == nil {
+		t.Errorf("notified = %+v", first)
+	}
+	go func() {
+		result, _ := q.CanUseTool("Write", map[string]any{"file_path": "/x"}, shared.ToolPermissionContext{})
+		results <- result
+	}()
+	pending = waitPending(t, q, 2)
+
+	// Unauthorized requests are rejected.
+	resp, _ := http.Get(server.URL + "/requests")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("no token status = %d", resp.StatusCode)
+	}
+
+	resp = call("GET", "/requests", "")
+	var listed []PendingRequest
+	_ = json.NewDecoder(resp.Body).Decode(&listed)
+	resp.Body.Close()
+	if len(listed) != 2 || listed[0].ToolName != "Bash" || listed[0].Title != title || listed[1].ToolName != "Write" {
+		t.Fatalf("listed = %+v", listed)
+	}
+
+	// A form post, as a cross-site page could send, is refused.
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/requests/"+pending[0].ID+"/approve", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "text/plain")
+	if resp, _ = http.DefaultClient.Do(req); resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain status = %d", resp.StatusCode)
+	}
+
+	resp = call("POST", "/requests/"+pending[0].ID+"/approve", `{"updated_input":{"command":"rm -rf build/tmp"},"reviewer":"ada"}`)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("approve status = %d", resp.StatusCode)
+	}
+	allow := (<-results).(*shared.PermissionResultAllow)
+	if allow.UpdatedInput.(map[string]any)["command"] != "rm -rf build/tmp" {
+		t.Errorf("approve result = %+v", allow)
+	}
+
+	resp = call("POST", "/requests/"+pending[1].ID+"/deny", `{"message":"not now","reviewer":"bob"}`)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("deny status = %d", resp.StatusCode)
+	}
+	if deny := (<-results).(*shared.PermissionResultDeny); deny.Message != "not now" {
+		t.Errorf("deny result = %+v", deny)
+	}
+
+	if resp = call("POST", "/requests/"+pending[1].ID+"/deny", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("second resolve status = %d", resp.StatusCode)
+	}
+	if resp = call("POST", "/requests/"+pending[0].ID+"/approve", "{bad"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad body status = %d", resp.StatusCode)
+	}
+
+	records := audit.records(t)
+	if len(records) != 2 || records[0].Outcome != OutcomeApproved || records[0].Reviewer != "ada" ||
+		records[1].Outcome != OutcomeDenied || records[1].Message != "not now" {
+		t.Errorf("audit = %+v", records)
+	}
+	resp = call("GET", "/decisions", "")
+	var history []Record
+	_ = json.NewDecoder(resp.Body).Decode(&history)
+	if len(history) != 2 {
+		t.Errorf("history = %+v", history)
+	}
+}
+
+func TestQueueGeneratesTokenAndRandomIDs(t *testing.T) {
+	q := NewQueue(QueueOptions{Timeout: -1})
+	if len(q.Token()) != 64 || NewQueue(QueueOptions{}).Token() == q.Token() {
+		t.Fatalf("expected a random token, got %q", q.Token())
+	}
+	server := httptest.NewServer(q.Handler())
+	defer server.Close()
+	if resp, _ := http.Get(server.URL + "/requests"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("no token status = %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for range 2 {
+		go func() { _, _ = q.CanUseTool("Bash", nil, shared.ToolPermissionContext{Context: ctx}) }()
+	}
+	pending := waitPending(t, q, 2)
+	for _, request := range pending {
+		if len(request.ID) != len("req_")+24 || request.ID == "req_1" || request.ID == "req_2" {
+			t.Errorf("expected a random id, got %q", request.ID)
+		}
+	}
+}
+
+func TestQueueTimeoutDenies(t *testing.T) {
+	audit := &safeBuffer{}
+	q := NewQueue(QueueOptions{Timeout: 30 * time.Millisecond, Audit: audit})
+	ctx, approver := shared.WithApproverRecorder(context.Background())
+	result, err := q.CanUseTool("Bash", map[string]any{"command": "ls"}, shared.ToolPermissionContext{Context: ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deny, ok := result.(*shared.PermissionResultDeny); !ok || !strings.Contains(deny.Message, "No reviewer decision") {
+		t.Errorf("result = %#v", result)
+	}
+	if approver() != "queue:timeout" {
+		t.Errorf("approver = %q", approver())
+	}
+	if records := audit.records(t); len(records) != 1 || records[0].Outcome != OutcomeTimeout {
+		t.Errorf("audit = %+v", records)
+	}
+	if len(q.Pending()) != 0 {
+		t.Error("timed out request still pending")
+	}
+}
+
+func TestQueueCancelRemovesRequest(t *testing.T) {
+	q := NewQueue(QueueOptions{Timeout: -1})
+	ctx, cancel := context.WithCancelCause(context.Background())
+	done := make(chan shared.PermissionResult, 1)
+	go func() {
+		result, _ := q.CanUseTool("Bash", nil, shared.ToolPermissionContext{Context: ctx})
+		done <- result
+	}()
+	pending := waitPending(t, q, 1)
+	if pending[0].ExpiresAt != nil {
+		t.Error("request without timeout has ExpiresAt")
+	}
+	cancel(shared.ErrAbortCancelled)
+
+	result := <-done
+	if deny, ok := result.(*shared.PermissionResultDeny); !ok || !strings.Contains(deny.Message, "cancelled by CLI") {
+		t.Errorf("result = %#v", result)
+	}
+	if err := q.Resolve(pending[0].ID, Resolution{Approve: true}); !errors.Is(err, ErrRequestNotFound) {
+		t.Errorf("Resolve after cancel = %v", err)
+	}
+	if history := q.History(); len(history) != 1 || history[0].Outcome != OutcomeCancelled {
+		t.Errorf("history = %+v", history)
+	}
+}
@@ -0,0 +1,329 @@
+// Package approval implements human-in-the-loop CanUseToolCallbacks.
+package approval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// TerminalOptions configures a Terminal approver.
+type TerminalOptions struct {
+	// In and Out are the terminal; they default to os.Stdin and os.Stderr.
+	In  io.Reader
+	Out io.Writer
+	// Destination is where "always" answers are persisted. Empty means
+	// PermissionDestinationLocalSettings.
+	Destination shared.PermissionDestination
+	// Editor, if set (e.g. os.Getenv("EDITOR")), is run on a temporary
+	// JSON file to edit a tool's input. Otherwise the edited input is read
+	// as one line of JSON from In.
+	Editor string
+	// Color enables ANSI colors in diffs.
+	Color bool
+}
+
+// Terminal prompts on a terminal for each permission request. Concurrent
+// requests are asked one at a time.
+type Terminal struct {
+	opts TerminalOptions
+
+	// turn serializes prompts; the fields below are owned by its holder.
+	turn    chan struct{}
+	reader  *bufio.Reader
+	pending chan lineResult
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+// NewTerminal creates a terminal approver.
+func NewTerminal(opts TerminalOptions) *Terminal {
+	if opts.In == nil {
+		opts.In = os.Stdin
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stderr
+	}
+	if opts.Destination == "" {
+		opts.Destination = shared.PermissionDestinationLocalSettings
+	}
+	return &Terminal{
+		opts:   opts,
+		turn:   make(chan struct{}, 1),
+		reader: bufio.NewReader(opts.In),
+	}
+}
+
+// CanUseTool renders the request and asks the user to allow it once,
+// always, deny it or edit its input. An aborted request is denied.
+func (t *Terminal) CanUseTool(toolName string, input map[string]any, permCtx shared.ToolPermissionContext) (shared.PermissionResult, error) {
+	ctx := permCtx.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	select {
+	case t.turn <- struct{}{}:
+		defer func() { <-t.turn }()
+	case <-ctx.Done():
+		return shared.NewPermissionDeny("permission request aborted", false), nil
+	}
+
+	shared.RecordApprover(ctx, "terminal")
+	t.printf("%s", t.render(toolName, input, permCtx))
+	for {
+		t.printf("[y] allow once  [a] always allow  [n] deny  [e] edit input > ")
+		answer, err := t.readLine(ctx)
+		if err != nil {
+			t.printf("\n")
+			return shared.NewPermissionDeny("permission request aborted", false), nil
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return shared.NewPermissionAllow(input, nil), nil
+
+		case "a", "always":
+			updates := t.alwaysUpdates(toolName, input, permCtx.Suggestions)
+			t.printf("Saving %d permission update(s) to %s.\n", len(updates), t.opts.Destination)
+			return shared.NewPermissionAllow(input, updates), nil
+
+		case "n", "no":
+			t.printf("Reason (optional) > ")
+			reason, err := t.readLine(ctx)
+			if err != nil || strings.TrimSpace(reason) == "" {
+				reason = "The user denied this tool call."
+			}
+			return shared.NewPermissionDeny(strings.TrimSpace(reason), false), nil
+
+		case "e", "edit":
+			edited, err := t.editInput(ctx, input)
+			if err != nil {
+				t.printf("Edit failed: %v\n", err)
+				continue
+			}
+			return shared.NewPermissionAllow(edited, nil), nil
+
+		default:
+			t.printf("Please answer y, a, n or e.\n")
+		}
+	}
+}
+
+func (t *Terminal) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(t.opts.Out, format, args...)
+}
+
+// readLine returns the next line from In, or the abort cause when ctx is
+// done first. A read abandoned by an aborted prompt is picked up by the
+// next one, so no input is lost and In is never read concurrently.
+func (t *Terminal) readLine(ctx context.Context) (string, error) {
+	if t.pending == nil {
+		pending := make(chan lineResult, 1)
+		t.pending = pending
+		go func() {
+			line, err := t.reader.ReadString('\n')
+			if err == io.EOF && line != "" {
+				err = nil
+			}
+			pending <- lineResult{line: strings.TrimRight(line, "\r\n"), err: err}
+		}()
+	}
+	select {
+	case result := <-t.pending:
+		t.pending = nil
+		return result.line, result.err
+	case <-ctx.Done():
+		return "", context.Cause(ctx)
+	}
+}
+
+// render formats the request for display.
+func (t *Terminal) render(toolName string, input map[string]any, permCtx shared.ToolPermissionContext) string {
+	var b strings.Builder
+	b.WriteString("\n")
+	if permCtx.Title != nil && *permCtx.Title != "" {
+		fmt.Fprintf(&b, "%s\n", escapeLine(*permCtx.Title))
+	} else {
+		fmt.Fprintf(&b, "Claude wants to use %s\n", escapeLine(toolName))
+	}
+	if permCtx.Description != nil && *permCtx.Description != "" {
+		fmt.Fprintf(&b, "%s\n", escapeText(*permCtx.Description))
+	}
+	if permCtx.DecisionReason != nil && *permCtx.DecisionReason != "" {
+		fmt.Fprintf(&b, "Reason: %s\n", escapeLine(*permCtx.DecisionReason))
+	}
+	b.WriteString("\n")
+
+	filePath, _ := input["file_path"].(string)
+	filePath = escapeLine(filePath)
+	switch toolName {
+	case "Bash":
+		command, _ := input["command"].(string)
+		fmt.Fprintf(&b, "  $ %s\n", strings.ReplaceAll(escapeText(command), "\n", "\n    "))
+		if description, _ := input["description"].(string); description != "" {
+			fmt.Fprintf(&b, "  # %s\n", escapeLine(description))
+		}
+
+	case "Edit":
+		oldString, _ := input["old_string"].(string)
+		newString, _ := input["new_string"].(string)
+		fmt.Fprintf(&b, "  %s\n", filePath)
+		renderDiff(&b, lineDiff(oldString, newString), t.opts.Color)
+
+	case "MultiEdit":
+		fmt.Fprintf(&b, "  %s\n", filePath)
+		edits, _ := input["edits"].([]any)
+		for _, raw := range edits {
+			edit, _ := raw.(map[string]any)
+			oldString, _ := edit["old_string"].(string)
+			newString, _ := edit["new_string"].(string)
+			renderDiff(&b, lineDiff(oldString, newString), t.opts.Color)
+		}
+
+	case "Write":
+		content, _ := input["content"].(string)
+		existing, err := os.ReadFile(filePath)
+		if err != nil {
+			fmt.Fprintf(&b, "  %s (new file)\n", filePath)
+		} else {
+			fmt.Fprintf(&b, "  %s\n", filePath)
+		}
+		renderDiff(&b, lineDiff(string(existing), content), t.opts.Color)
+
+	default:
+		data, err := json.MarshalIndent(input, "  ", "  ")
+		if err != nil {
+			fmt.Fprintf(&b, "  %s\n", escapeText(fmt.Sprint(input)))
+		} else {
+			fmt.Fprintf(&b, "  %s\n", escapeText(string(data)))
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// escapeText makes model-supplied text safe to print to a terminal: control
+// characters other than newline and tab, including ESC, and bidirectional
+// overrides are shown as Go escapes, so they cannot move the cursor,
+// recolor or reorder what the reviewer reads.
+func escapeText(s string) string {
+	return escapeTerminal(s, false)
+}
+
+// escapeLine is escapeText for single-line fields, also escaping newlines
+// so a field cannot fake further lines of the prompt.
+func escapeLine(s string) string {
+	return escapeTerminal(s, true)
+}
+
+func escapeTerminal(s string, escapeNewlines bool) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\t', r == '\n' && !escapeNewlines:
+			b.WriteRune(r)
+		case r == utf8.RuneError, unicode.IsControl(r), unicode.Is(unicode.Bidi_Control, r):
+			if r < 0x100 {
+				fmt.Fprintf(&b, "\\x%02x", r)
+			} else {
+				fmt.Fprintf(&b, "\\u%04x", r)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// alwaysUpdates turns an "always" answer into permission updates: the
+// CLI's suggestions when it sent any, otherwise an allow rule for the tool
+// (scoped to the exact command for Bash), all sent to Destination.
+func (t *Terminal) alwaysUpdates(toolName string, input map[string]any, suggestions []shared.PermissionUpdate) []shared.PermissionUpdate {
+	destination := t.opts.Destination
+	if len(suggestions) > 0 {
+		updates := make([]shared.PermissionUpdate, len(suggestions))
+		for i, suggestion := range suggestions {
+			suggestion.Destination = &destination
+			updates[i] = suggestion
+		}
+		return updates
+	}
+
+	var ruleContent *string
+	if toolName == "Bash" {
+		if command, ok := input["command"].(string); ok && command != "" {
+			ruleContent = &command
+		}
+	}
+	behavior := string(shared.PermissionBehaviorAllow)
+	return []shared.PermissionUpdate{{
+		Type:        shared.PermissionUpdateTypeAddRules,
+		Destination: &destination,
+		Rules:       []shared.PermissionRule{{ToolName: toolName, RuleContent: ruleContent}},
+		Behavior:    &behavior,
+	}}
+}
+
+// editInput lets the user replace the tool input, in Editor when set or as
+// a line of JSON otherwise.
+func (t *Terminal) editInput(ctx context.Context, input map[string]any) (map[string]any, error) {
+	current, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var edited []byte
+	if t.opts.Editor != "" {
+		edited, err = t.runEditor(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		compact, _ := json.Marshal(input)
+		t.printf("Current input: %s\nNew input (JSON) > ", compact)
+		line, err := t.readLine(ctx)
+		if err != nil {
+			return nil, err
+		}
+		edited = []byte(line)
+	}
+
+	var updated map[string]any
+	if err := json.Unmarshal(edited, &updated); err != nil {
+		return nil, fmt.Errorf("invalid JSON object: %w", err)
+	}
+	return updated, nil
+}
+
+func (t *Terminal) runEditor(ctx context.Context, current []byte) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "claude-tool-input-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "input.json")
+	if err := os.WriteFile(path, current, 0o600); err != nil {
+		return nil, err
+	}
+
+	args := strings.Fields(t.opts.Editor)
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor failed: %w", err)
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,161 @@
+package approval
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+func ask(t *testing.T, answers, toolName string, input map[string]any, permCtx shared.ToolPermissionContext) (shared.PermissionResult, string) {
+	t.Helper()
+	var out strings.Builder
+	terminal := NewTerminal(TerminalOptions{In: strings.NewReader(answers), Out: &out, Destination: shared.PermissionDestinationProjectSettings})
+	result, err := terminal.CanUseTool(toolName, input, permCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result, out.String()
+}
+
+func TestTerminalAllowOnceShowsBashCommand(t *testing.T) {
+	input := map[string]any{"command": "go test ./...", "description": "Run tests"}
+	result, out := ask(t, "bogus\ny\n", "Bash", input, shared.ToolPermissionContext{})
+	allow, ok := result.(*shared.PermissionResultAllow)
+	if !ok || allow.UpdatedPermissions != nil {
+		t.Fatalf("result = %#v", result)
+	}
+	for _, want := range []string{"Claude wants to use Bash", "$ go test ./...", "# Run tests", "Please answer"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestTerminalAlwaysPersistsRule(t *testing.T) {
+	result, _ := ask(t, "a\n", "Bash", map[string]any{"command": "make"}, shared.ToolPermissionContext{})
+	allow := result.(*shared.PermissionResultAllow)
+	if len(allow.UpdatedPermissions) != 1 {
+		t.Fatalf("updates = %+v", allow.UpdatedPermissions)
+	}
+	update := allow.UpdatedPermissions[0]
+	if update.Type != shared.PermissionUpdateTypeAddRules || *update.Destination != shared.PermissionDestinationProjectSettings ||
+		update.Rules[0].ToolName != "Bash" || *update.Rules[0].RuleContent != "make" || *update.Behavior != "allow" {
+		t.Errorf("update = %+v", update)
+	}
+
+	session := shared.PermissionDestinationSession
+	suggestion := shared.PermissionUpdate{Type: shared.PermissionUpdateTypeAddDirectories, Destination: &session, Directories: []string{"/tmp"}}
+	result, _ = ask(t, "always\n", "Read", map[string]any{}, shared.ToolPermissionContext{Suggestions: []shared.PermissionUpdate{suggestion}})
+	allow = result.(*shared.PermissionResultAllow)
+	if len(allow.UpdatedPermissions) != 1 || *allow.UpdatedPermissions[0].Destination != shared.PermissionDestinationProjectSettings ||
+		allow.UpdatedPermissions[0].Directories[0] != "/tmp" {
+		t.Errorf("suggestion updates = %+v", allow.UpdatedPermissions)
+	}
+	if *suggestion.Destination != session {
+		t.Error("caller's suggestion was modified")
+	}
+}
+
+func TestTerminalDenyWithReason(t *testing.T) {
+	result, _ := ask(t, "n\nuse the Makefile\n", "Bash", map[string]any{"command": "rm -rf build"}, shared.ToolPermissionContext{})
+	if deny, ok := result.(*shared.PermissionResultDeny); !ok || deny.Message != "use the Makefile" {
+		t.Errorf("result = %#v", result)
+	}
+	result, _ = ask(t, "n", "Bash", map[string]any{"command": "x"}, shared.ToolPermissionContext{})
+	if deny, ok := result.(*shared.PermissionResultDeny); !ok || deny.Message == "" {
+		t.Errorf("result = %#v", result)
+	}
+}
+
+func TestTerminalEditInput(t *testing.T) {
+	result, out := ask(t, "e\nnot json\ne\n{\"command\": \"ls -la\"}\n", "Bash", map[string]any{"command": "ls"}, shared.ToolPermissionContext{})
+	allow, ok := result.(*shared.PermissionResultAllow)
+	if !ok || allow.UpdatedInput.(map[string]any)["command"] != "ls -la" {
+		t.Errorf("result = %#v", result)
+	}
+	if !strings.Contains(out, "Edit failed") {
+		t.Errorf("invalid JSON not reported:\n%s", out)
+	}
+}
+
+func TestTerminalRendersDiffs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, out := ask(t, "y\n", "Write", map[string]any{"file_path": path, "content": "package main\n\nfunc main() { run() }\n"}, shared.ToolPermissionContext{})
+	if !strings.Contains(out, "- func main() {}\n+ func main() { run() }\n") || !strings.Contains(out, "  package main\n") {
+		t.Errorf("Write diff:\n%s", out)
+	}
+
+	_, out = ask(t, "y\n", "Edit", map[string]any{"file_path": "/a.txt", "old_string": "one\ntwo", "new_string": "one\n2"}, shared.ToolPermissionContext{})
+	if !strings.Contains(out, "/a.txt\n  one\n- two\n+ 2\n") {
+		t.Errorf("Edit diff:\n%s", out)
+	}
+}
+
+func TestTerminalEscapesControlSequences(t *testing.T) {
+	title := "Run tests\n\n  $ ls"
+	input := map[string]any{"command": "ls\x1b[2K\r\x1b[1Arm -rf ~", "description": "list\u202efiles"}
+	_, out := ask(t, "n\n\n", "Bash", input, shared.ToolPermissionContext{Title: &title})
+	if strings.ContainsAny(out, "\x1b\r\u202e") {
+		t.Fatalf("raw control characters reached the terminal: %q", out)
+	}
+	for _, want := range []string{`Run tests\x0a\x0a  $ ls`, `$ ls\x1b[2K\x0d\x1b[1Arm -rf ~`, `# list\u202efiles`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	_, out = ask(t, "y\n", "Edit", map[string]any{"file_path": "/a.txt", "old_string": "a", "new_string": "b\x1b]0;pwned\x07"}, shared.ToolPermissionContext{})
+	if !strings.Contains(out, `+ b\x1b]0;pwned\x07`) {
+		t.Errorf("Edit diff:\n%q", out)
+	}
+}
+
+func TestTerminalAbortDeniesAndKeepsInput(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	terminal := NewTerminal(TerminalOptions{In: reader, Out: io.Discard})
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	done := make(chan shared.PermissionResult, 1)
+	go func() {
+		result, _ := terminal.CanUseTool("Bash", map[string]any{"command": "x"}, shared.ToolPermissionContext{Context: ctx})
+		done <- result
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel(shared.ErrAbortCancelled)
+	select {
+	case result := <-done:
+		if _, ok := result.(*shared.PermissionResultDeny); !ok {
+			t.Errorf("aborted result = %#v", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("prompt not aborted")
+	}
+
+	// The abandoned read delivers the next line to the next prompt.
+	go func() { _, _ = writer.Write([]byte("y\n")) }()
+	result, _ := terminal.CanUseTool("Bash", map[string]any{"command": "y"}, shared.ToolPermissionContext{})
+	if _, ok := result.(*shared.PermissionResultAllow); !ok {
+		t.Errorf("next prompt = %#v", result)
+	}
+}
+
+func TestLineDiffCollapsesContext(t *testing.T) {
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	after := "1\n2\n3\n4\n5\n6\n7\n8\n9\nten\n"
+	var b strings.Builder
+	renderDiff(&b, lineDiff(before, after), false)
+	want := "  ...\n  7\n  8\n  9\n- 10\n+ ten\n"
+	if b.String() != want {
+		t.Errorf("diff =\n%q\nwant\n%q", b.String(), want)
+	}
+}
@@ -0,0 +1,145 @@
+// Package audit records tool usage and permission decisions in a
+// tamper-evident, hash-chained log.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Record events.
+const (
+	EventPreToolUse         = "pre_tool_use"
+	EventPostToolUse        = "post_tool_use"
+	EventPostToolUseFailure = "post_tool_use_failure"
+	EventPermissionRequest  = "permission_request"
+	EventPermissionDecision = "permission_decision"
+)
+
+// Record statuses of finished tool calls.
+const (
+	StatusSuccess     = "success"
+	StatusError       = "error"
+	StatusInterrupted = "interrupted"
+)
+
+// Record is one audit log entry. Each record carries the hash of the one
+// before it, so removing, reordering or editing records breaks the chain.
+type Record struct {
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	Event     string    `json:"event"`
+	SessionID string    `json:"session_id,omitempty"`
+	AgentID   string    `json:"agent_id,omitempty"`
+	ToolUseID string    `json:"tool_use_id,omitempty"`
+	ToolName  string    `json:"tool_name,omitempty"`
+	// InputDigest is the SHA-256 of the tool input's canonical JSON; the
+	// input itself is not logged.
+	InputDigest string `json:"input_digest,omitempty"`
+
+	// Decision is "allow", "deny" or "error" for permission decisions.
+	Decision  string `json:"decision,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Interrupt bool   `json:"interrupt,omitempty"`
+	// InputModified is set when an allow decision replaced the input.
+	InputModified bool `json:"input_modified,omitempty"`
+	// Approver names who or what made a permission decision, as reported
+	// with shared.RecordApprover: "queue:<reviewer>", "cache",
+	// "policy:<rule>", "terminal", or "callback" when the callback
+	// reported nothing.
+	Approver string `json:"approver,omitempty"`
+
+	// DurationMs is the decision time for permission decisions and the
+	// time since PreToolUse for finished tool calls.
+	DurationMs int64 `json:"duration_ms,omitempty"`
+	// Status is "success", "error" or "interrupted" for finished tool
+	// calls; Error holds the failure message.
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// ResultDigest is the SHA-256 of the tool response's canonical JSON.
+	ResultDigest string `json:"result_digest,omitempty"`
+
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// computeHash returns the hash of the record with its Hash field cleared.
+func (r Record) computeHash() (string, error) {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Sink receives chained records in order.
+type Sink interface {
+	Write(record Record) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(record Record) error
+
+// Write calls f.
+func (f SinkFunc) Write(record Record) error {
+	return f(record)
+}
+
+// WriterSink writes records to an io.Writer as JSON lines.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a JSONL sink on w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Write appends the record as one line.
+func (s *WriterSink) Write(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (s *WriterSink) Close() error {
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// digest returns "sha256:<hex>" of v's canonical JSON, or "" for nil.
+func digest(v any) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data = []byte(fmt.Sprint(v))
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// deref returns *s or "".
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
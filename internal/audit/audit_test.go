@@ -0,0 +1,257 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// fakeClock advances by step on every call.
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func runToolCall(t *testing.T, logger *Logger) {
+	t.Helper()
+	hook := logger.Hook()
+	toolUseID := "toolu_1"
+	base := map[string]any{"session_id": "sess-1", "tool_name": "Bash", "tool_input": map[string]any{"command": "ls"}, "tool_use_id": toolUseID}
+	with := func(event string, extra map[string]any) map[string]any {
+		input := map[string]any{"hook_event_name": event}
+		for k, v := range base {
+			input[k] = v
+		}
+		for k, v := range extra {
+			input[k] = v
+		}
+		return input
+	}
+
+	if _, err := hook(with("PermissionRequest", nil), nil, shared.HookContext{}); err != nil {
+		t.Fatal(err)
+	}
+	canUseTool := logger.CanUseTool(func(string, map[string]any, shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		return shared.NewPermissionAllow(map[string]any{"command": "ls -la"}, nil), nil
+	})
+	if _, err := canUseTool("Bash", map[string]any{"command": "ls"}, shared.ToolPermissionContext{ToolUseID: &toolUseID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hook(with("PreToolUse", nil), &toolUseID, shared.HookContext{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hook(with("PostToolUse", map[string]any{"tool_response": "a\nb\n"}), &toolUseID, shared.HookContext{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoggerRecordsAndVerifies(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(NewWriterSink(&buf), Options{Now: fakeClock(10 * time.Millisecond)})
+	runToolCall(t, logger)
+
+	report, err := Verify(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("verify: %v\n%s", err, buf.String())
+	}
+	if report.Records != 4 || report.First.Seq != 1 || report.Last.Seq != 4 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	var records []Record
+	sink := SinkFunc(func(r Record) error { records = append(records, r); return nil })
+	runToolCall(t, NewLogger(sink, Options{Now: fakeClock(10 * time.Millisecond)}))
+	events := []string{EventPermissionRequest, EventPermissionDecision, EventPreToolUse, EventPostToolUse}
+	for i, record := range records {
+		if record.Event != events[i] || record.SessionID != "sess-1" || record.ToolName != "Bash" {
+			t.Fatalf("record %d: unexpected %+v", i, record)
+		}
+		if !strings.HasPrefix(record.InputDigest, "sha256:") || strings.Contains(buf.String(), `"command"`) {
+			t.Fatalf("record %d: expected a digest instead of the input", i)
+		}
+	}
+	decision, post := records[1], records[3]
+	if decision.Decision != "allow" || !decision.InputModified || decision.ToolUseID != "toolu_1" || decision.DurationMs != 10 ||
+		decision.Approver != "callback" {
+		t.Fatalf("unexpected decision record %+v", decision)
+	}
+	if post.Status != StatusSuccess || post.DurationMs <= 0 || post.ResultDigest == "" {
+		t.Fatalf("unexpected post record %+v", post)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	var buf bytes.Buffer
+	runToolCall(t, NewLogger(NewWriterSink(&buf), Options{}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	tests := map[string][]string{
+		"edited":    {lines[0], strings.Replace(lines[1], `"allow"`, `"deny"`, 1), lines[2], lines[3]},
+		"removed":   {lines[0], lines[2], lines[3]},
+		"reordered": {lines[0], lines[2], lines[1], lines[3]},
+		"garbage":   {lines[0], "{not json"},
+		"extra key": {lines[0], strings.Replace(lines[1], "{", `{"approved_by":"mallory",`, 1), lines[2], lines[3]},
+		"reencoded": {lines[0], strings.Replace(lines[1], `"seq":2,`, `"seq": 2,`, 1), lines[2], lines[3]},
+	}
+	for name, tampered := range tests {
+		_, err := Verify(strings.NewReader(strings.Join(tampered, "\n")))
+		var chainErr *ChainError
+		if !errors.As(err, &chainErr) || !errors.Is(err, ErrChainBroken) || chainErr.Line != 2 {
+			t.Errorf("%s: expected a chain error at line 2, got %v", name, err)
+		}
+	}
+}
+
+func TestVerifyRequiresChainStart(t *testing.T) {
+	var buf bytes.Buffer
+	runToolCall(t, NewLogger(NewWriterSink(&buf), Options{}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	rest := strings.Join(lines[2:], "\n")
+
+	_, err := Verify(strings.NewReader(rest))
+	var chainErr *ChainError
+	if !errors.As(err, &chainErr) || chainErr.Line != 1 {
+		t.Fatalf("expected a log missing its first records to fail at line 1, got %v", err)
+	}
+
+	head, err := Verify(strings.NewReader(strings.Join(lines[:2], "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report, err := VerifyAfter(strings.NewReader(rest), head.Last); err != nil || report.First.Seq != 3 {
+		t.Fatalf("expected the rest to continue the head, got %+v, %v", report, err)
+	}
+	if _, err := VerifyAfter(strings.NewReader(strings.Join(lines[3:], "\n")), head.Last); !errors.Is(err, ErrChainBroken) {
+		t.Fatalf("expected a gap after the head to fail, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte(rest+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFile(path, Options{}); !errors.Is(err, ErrChainBroken) {
+		t.Fatalf("expected OpenFile to refuse a truncated log, got %v", err)
+	}
+	logger, err := OpenFile(path, Options{Head: &head.Last})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq, _ := logger.Head(); seq != 4 {
+		t.Fatalf("expected the logger to continue at seq 4, got %d", seq)
+	}
+}
+
+func TestLoggerRecordsApprover(t *testing.T) {
+	var records []Record
+	logger := NewLogger(SinkFunc(func(r Record) error { records = append(records, r); return nil }), Options{})
+	canUseTool := logger.CanUseTool(func(_ string, _ map[string]any, permCtx shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		shared.RecordApprover(permCtx.Context, "queue:alice")
+		return shared.NewPermissionAllow(nil, nil), nil
+	})
+	if _, err := canUseTool("Bash", nil, shared.ToolPermissionContext{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Approver != "queue:alice" {
+		t.Fatalf("expected the reported approver to be recorded, got %+v", records)
+	}
+}
+
+func TestLoggerBoundsPendingCalls(t *testing.T) {
+	logger := NewLogger(SinkFunc(func(Record) error { return nil }), Options{Now: fakeClock(time.Millisecond)})
+	hook := logger.Hook()
+	preToolUse := func(toolUseID string) {
+		input := map[string]any{"hook_event_name": "PreToolUse", "tool_name": "Bash", "tool_use_id": toolUseID}
+		if _, err := hook(input, nil, shared.HookContext{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	preToolUse("denied")
+	deny := func(string, map[string]any, shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		return shared.NewPermissionDeny("no", false), nil
+	}
+	toolUseID := "denied"
+	_, _ = logger.CanUseTool(deny)("Bash", nil, shared.ToolPermissionContext{ToolUseID: &toolUseID})
+	if _, ok := logger.started[toolUseID]; ok {
+		t.Fatal("expected a denied call to stop being tracked")
+	}
+
+	for i := range maxPending + 1 {
+		preToolUse(fmt.Sprintf("toolu_%d", i))
+	}
+	if len(logger.started) != maxPending {
+		t.Fatalf("expected %d pending calls, got %d", maxPending, len(logger.started))
+	}
+	if _, ok := logger.started["toolu_0"]; ok {
+		t.Fatal("expected the oldest pending call to be evicted")
+	}
+}
+
+func TestOpenFileContinuesChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for range 2 {
+		logger, err := OpenFile(path, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		runToolCall(t, logger)
+		if err := logger.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Verify(bytes.NewReader(data))
+	if err != nil || report.Records != 8 || report.Last.Seq != 8 {
+		t.Fatalf("expected one chain of 8 records, got %+v, %v", report, err)
+	}
+
+	if err := os.WriteFile(path, bytes.Replace(data, []byte(`"seq":3`), []byte(`"seq":30`), 1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFile(path, Options{}); !errors.Is(err, ErrChainBroken) {
+		t.Fatalf("expected a tampered log to be refused, got %v", err)
+	}
+}
+
+func TestFailClosed(t *testing.T) {
+	var reported []error
+	broken := SinkFunc(func(Record) error { return errors.New("disk full") })
+	logger := NewLogger(broken, Options{FailClosed: true, OnError: func(err error) { reported = append(reported, err) }})
+
+	output, err := logger.Hook()(map[string]any{"hook_event_name": "PreToolUse", "tool_name": "Bash", "tool_use_id": "t"}, nil, shared.HookContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	specific, _ := output["hookSpecificOutput"].(map[string]any)
+	if specific["permissionDecision"] != shared.PermissionDecisionDeny {
+		t.Fatalf("expected PreToolUse to be denied, got %v", output)
+	}
+
+	allow := func(string, map[string]any, shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		return shared.NewPermissionAllow(nil, nil), nil
+	}
+	result, _ := logger.CanUseTool(allow)("Bash", nil, shared.ToolPermissionContext{})
+	if _, ok := result.(*shared.PermissionResultDeny); !ok {
+		t.Fatalf("expected an unrecorded allow to be denied, got %#v", result)
+	}
+	if len(reported) != 2 {
+		t.Fatalf("expected both failures to be reported, got %v", reported)
+	}
+	if seq, hash := logger.Head(); seq != 0 || hash != "" {
+		t.Fatalf("expected failed records to stay out of the chain, head %d %q", seq, hash)
+	}
+}
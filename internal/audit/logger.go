@@ -0,0 +1,306 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// Options configures a Logger.
+type Options struct {
+	// Now overrides the clock; nil means time.Now.
+	Now func() time.Time
+	// OnError is called when the sink fails to write a record.
+	OnError func(err error)
+	// FailClosed denies tool calls whose PreToolUse or permission record
+	// cannot be written, so nothing runs unaudited.
+	FailClosed bool
+	// Head continues an existing chain after its last record; OpenFile
+	// then requires the file to follow it.
+	Head *Record
+}
+
+// maxPending bounds the PreToolUse times kept for calls that have not
+// finished yet; calls that never report back are evicted oldest first.
+const maxPending = 4096
+
+// Logger appends hash-chained records to a Sink. Use Hook for the
+// PreToolUse, PostToolUse, PostToolUseFailure and PermissionRequest
+// events and wrap the permission callback with CanUseTool.
+type Logger struct {
+	sink Sink
+	opts Options
+
+	mu      sync.Mutex
+	seq     uint64
+	prev    string
+	session string               // last session seen in a hook input
+	started map[string]time.Time // PreToolUse time by tool_use_id
+}
+
+// NewLogger creates a logger writing to sink.
+func NewLogger(sink Sink, opts Options) *Logger {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	l := &Logger{sink: sink, opts: opts, started: make(map[string]time.Time)}
+	if opts.Head != nil {
+		l.seq, l.prev = opts.Head.Seq, opts.Head.Hash
+	}
+	return l
+}
+
+// OpenFile verifies the log at path, if any, and returns a logger
+// appending to it that continues its chain. The log must start the chain,
+// or follow opts.Head when it is set.
+func OpenFile(path string, opts Options) (*Logger, error) {
+	existing, err := os.Open(path)
+	switch {
+	case err == nil:
+		var report Report
+		var verifyErr error
+		if opts.Head != nil {
+			report, verifyErr = VerifyAfter(existing, *opts.Head)
+		} else {
+			report, verifyErr = Verify(existing)
+		}
+		existing.Close()
+		if verifyErr != nil {
+			return nil, verifyErr
+		}
+		if report.Records > 0 {
+			head := report.Last
+			opts.Head = &head
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return NewLogger(NewWriterSink(file), opts), nil
+}
+
+// Head returns the sequence number and hash of the last record written.
+func (l *Logger) Head() (seq uint64, hash string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq, l.prev
+}
+
+// Close closes the sink if it is an io.Closer.
+func (l *Logger) Close() error {
+	if closer, ok := l.sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// append chains and writes a record. Records are written under the lock so
+// the sink sees them in chain order; a record the sink rejects is not
+// part of the chain.
+func (l *Logger) append(record Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if record.SessionID == "" {
+		record.SessionID = l.session
+	}
+	record.Seq = l.seq + 1
+	record.Time = l.opts.Now().UTC()
+	record.PrevHash = l.prev
+	hash, err := record.computeHash()
+	if err != nil {
+		return l.fail(fmt.Errorf("failed to hash audit record: %w", err))
+	}
+	record.Hash = hash
+	if err := l.sink.Write(record); err != nil {
+		return l.fail(fmt.Errorf("failed to write audit record: %w", err))
+	}
+	l.seq, l.prev = record.Seq, record.Hash
+	return nil
+}
+
+func (l *Logger) fail(err error) error {
+	if l.opts.OnError != nil {
+		l.opts.OnError(err)
+	}
+	return err
+}
+
+// Hook returns a hook callback that records PreToolUse, PostToolUse,
+// PostToolUseFailure and PermissionRequest events. It never changes the
+// outcome of a call, except that with FailClosed a PreToolUse that cannot
+// be recorded is denied.
+func (l *Logger) Hook() shared.HookCallback {
+	return func(raw shared.HookInput, _ *string, _ shared.HookContext) (shared.HookJSONOutput, error) {
+		typed, err := shared.DecodeHookInput(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		switch input := typed.(type) {
+		case *shared.PreToolUseHookInput:
+			l.start(input.ToolUseID, l.observe(input.SessionID))
+			err := l.append(Record{
+				Event:       EventPreToolUse,
+				SessionID:   input.SessionID,
+				AgentID:     deref(input.AgentID),
+				ToolUseID:   input.ToolUseID,
+				ToolName:    input.ToolName,
+				InputDigest: digest(input.ToolInput),
+			})
+			if err != nil && l.opts.FailClosed {
+				return shared.NewPreToolUseOutput(shared.PermissionDecisionDeny, "audit log unavailable", nil), nil
+			}
+
+		case *shared.PostToolUseHookInput:
+			l.observe(input.SessionID)
+			_ = l.append(Record{
+				Event:        EventPostToolUse,
+				SessionID:    input.SessionID,
+				AgentID:      deref(input.AgentID),
+				ToolUseID:    input.ToolUseID,
+				ToolName:     input.ToolName,
+				InputDigest:  digest(input.ToolInput),
+				DurationMs:   l.elapsed(input.ToolUseID),
+				Status:       StatusSuccess,
+				ResultDigest: digest(input.ToolResponse),
+			})
+
+		case *shared.PostToolUseFailureHookInput:
+			l.observe(input.SessionID)
+			status := StatusError
+			if input.IsInterrupt != nil && *input.IsInterrupt {
+				status = StatusInterrupted
+			}
+			_ = l.append(Record{
+				Event:       EventPostToolUseFailure,
+				SessionID:   input.SessionID,
+				AgentID:     deref(input.AgentID),
+				ToolUseID:   input.ToolUseID,
+				ToolName:    input.ToolName,
+				InputDigest: digest(input.ToolInput),
+				DurationMs:  l.elapsed(input.ToolUseID),
+				Status:      status,
+				Error:       input.Error,
+			})
+
+		case *shared.PermissionRequestHookInput:
+			l.observe(input.SessionID)
+			_ = l.append(Record{
+				Event:       EventPermissionRequest,
+				SessionID:   input.SessionID,
+				AgentID:     deref(input.AgentID),
+				ToolName:    input.ToolName,
+				InputDigest: digest(input.ToolInput),
+			})
+		}
+		return shared.HookJSONOutput{}, nil
+	}
+}
+
+// observe remembers the session for records that carry none (permission
+// decisions) and returns the current time.
+func (l *Logger) observe(sessionID string) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if sessionID != "" {
+		l.session = sessionID
+	}
+	return l.opts.Now()
+}
+
+// start remembers when a call passed PreToolUse, evicting the oldest
+// pending call when maxPending are already waiting.
+func (l *Logger) start(toolUseID string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.started[toolUseID]; !ok && len(l.started) >= maxPending {
+		oldestID, oldest := "", time.Time{}
+		for id, started := range l.started {
+			if oldestID == "" || started.Before(oldest) {
+				oldestID, oldest = id, started
+			}
+		}
+		delete(l.started, oldestID)
+	}
+	l.started[toolUseID] = now
+}
+
+// elapsed returns the milliseconds since the call's PreToolUse, or 0.
+func (l *Logger) elapsed(toolUseID string) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	started, ok := l.started[toolUseID]
+	if !ok {
+		return 0
+	}
+	delete(l.started, toolUseID)
+	return l.opts.Now().Sub(started).Milliseconds()
+}
+
+// CanUseTool wraps a permission callback, recording each decision with
+// its reason, timing and the approver the callback reported with
+// shared.RecordApprover. With FailClosed an allow that cannot be recorded
+// is turned into a deny.
+func (l *Logger) CanUseTool(next shared.CanUseToolCallback) shared.CanUseToolCallback {
+	return func(toolName string, input map[string]any, permCtx shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		if next == nil {
+			return shared.NewPermissionDeny("no permission callback configured", false), nil
+		}
+		ctx := permCtx.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, approver := shared.WithApproverRecorder(ctx)
+		permCtx.Context = ctx
+
+		started := l.opts.Now()
+		result, err := next(toolName, input, permCtx)
+
+		record := Record{
+			Event:       EventPermissionDecision,
+			AgentID:     deref(permCtx.AgentID),
+			ToolUseID:   deref(permCtx.ToolUseID),
+			ToolName:    toolName,
+			InputDigest: digest(input),
+			DurationMs:  l.opts.Now().Sub(started).Milliseconds(),
+			Approver:    approver(),
+		}
+		if record.Approver == "" {
+			record.Approver = "callback"
+		}
+		switch r := result.(type) {
+		case *shared.PermissionResultAllow:
+			record.Decision = shared.PermissionDecisionAllow
+			record.InputModified = r.UpdatedInput != nil && !reflect.DeepEqual(r.UpdatedInput, input)
+		case *shared.PermissionResultDeny:
+			record.Decision = shared.PermissionDecisionDeny
+			record.Reason = r.Message
+			record.Interrupt = r.Interrupt
+			// A denied call never reaches PostToolUse.
+			l.mu.Lock()
+			delete(l.started, record.ToolUseID)
+			l.mu.Unlock()
+		}
+		if err != nil {
+			record.Decision = "error"
+			record.Error = err.Error()
+		}
+
+		if appendErr := l.append(record); appendErr != nil && l.opts.FailClosed && err == nil {
+			if _, allowed := result.(*shared.PermissionResultAllow); allowed {
+				return shared.NewPermissionDeny("audit log unavailable", false), nil
+			}
+		}
+		return result, err
+	}
+}
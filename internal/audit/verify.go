@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrChainBroken is wrapped by every ChainError.
+var ErrChainBroken = errors.New("audit chain broken")
+
+// ChainError reports where an audit log fails verification.
+type ChainError struct {
+	Line   int    // 1-based line number
+	Seq    uint64 // sequence number of the offending record, if decoded
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("%s at line %d (seq %d): %s", ErrChainBroken, e.Line, e.Seq, e.Reason)
+}
+
+// Unwrap returns ErrChainBroken.
+func (e *ChainError) Unwrap() error {
+	return ErrChainBroken
+}
+
+// Report summarizes a verified log.
+type Report struct {
+	Records int
+	// First and Last are the first and last records. First is sequence 1
+	// unless the log was verified with VerifyAfter.
+	First Record
+	Last  Record
+}
+
+// maxLineSize bounds one JSONL record.
+const maxLineSize = 1 << 20
+
+// Verify reads a JSONL audit log and checks that it starts a chain (seq 1,
+// no prev_hash), that every record's hash matches its content, links to
+// the previous record's hash, and that sequence numbers increase by one. Each line must be exactly the encoding
+// the Logger writes, so unknown keys or a re-encoded record fail even when
+// the hashed fields are intact. Blank lines are ignored. It returns a
+// *ChainError for the first broken link.
+func Verify(r io.Reader) (Report, error) {
+	return verify(r, nil)
+}
+
+// VerifyAfter is Verify for a log that continues a chain after head, such
+// as the next file of a rotated log: its first record must follow head.
+func VerifyAfter(r io.Reader, head Record) (Report, error) {
+	return verify(r, &head)
+}
+
+func verify(r io.Reader, head *Record) (Report, error) {
+	var report Report
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var record Record
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			return report, &ChainError{Line: line, Reason: fmt.Sprintf("invalid record: %v", err)}
+		}
+		canonical, err := json.Marshal(record)
+		if err != nil {
+			return report, &ChainError{Line: line, Seq: record.Seq, Reason: err.Error()}
+		}
+		if !bytes.Equal(canonical, data) {
+			return report, &ChainError{Line: line, Seq: record.Seq, Reason: "record is not in canonical form"}
+		}
+
+		hash, err := record.computeHash()
+		if err != nil {
+			return report, &ChainError{Line: line, Seq: record.Seq, Reason: err.Error()}
+		}
+		if hash != record.Hash {
+			return report, &ChainError{Line: line, Seq: record.Seq, Reason: "record hash does not match its content"}
+		}
+		if report.Records > 0 {
+			if record.Seq != report.Last.Seq+1 {
+				return report, &ChainError{Line: line, Seq: record.Seq, Reason: fmt.Sprintf("expected seq %d", report.Last.Seq+1)}
+			}
+			if record.PrevHash != report.Last.Hash {
+				return report, &ChainError{Line: line, Seq: record.Seq, Reason: "prev_hash does not match the previous record"}
+			}
+		} else {
+			wantSeq, wantPrev := uint64(1), ""
+			if head != nil {
+				wantSeq, wantPrev = head.Seq+1, head.Hash
+			}
+			if record.Seq != wantSeq {
+				return report, &ChainError{Line: line, Seq: record.Seq, Reason: fmt.Sprintf("chain starts at seq %d, expected %d", record.Seq, wantSeq)}
+			}
+			if record.PrevHash != wantPrev {
+				return report, &ChainError{Line: line, Seq: record.Seq, Reason: "first record does not link to the expected head"}
+			}
+			report.First = record
+		}
+		report.Last = record
+		report.Records++
+	}
+	if err := scanner.Err(); err != nil {
+		return report, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func blockingTool(name string, timeout time.Duration, started chan<- struct{}, stopped chan<- error) *ToolDefinition {
+	return &ToolDefinition{
+		Name:    name,
+		Timeout: timeout,
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			if started != nil {
+				close(started)
+			}
+			<-ctx.Done()
+			if stopped != nil {
+				stopped <- context.Cause(ctx)
+			}
+			return nil, ctx.Err()
+		},
+	}
+}
+
+func callToolResult(t *testing.T, resp []byte) map[string]interface{} {
+	t.Helper()
+	var out struct {
+		Result map[string]interface{} `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return out.Result
+}
+
+func errorText(result map[string]interface{}) string {
+	content, _ := result["content"].([]interface{})
+	if len(content) == 0 {
+		return ""
+	}
+	text, _ := content[0].(map[string]interface{})["text"].(string)
+	return text
+}
+
+func TestCallToolTimeoutReportedAsError(t *testing.T) {
+	server := NewServer("slow", "1.0.0")
+	stopped := make(chan error, 1)
+	if err := server.RegisterTool(blockingTool("sleep", 20*time.Millisecond, nil, stopped)); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.HandleJSONRPC(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"sleep"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := callToolResult(t, resp)
+	if result["is_error"] != true || !strings.Contains(errorText(result), "timed out") {
+		t.Errorf("result = %v", result)
+	}
+	select {
+	case cause := <-stopped:
+		if cause == nil {
+			t.Error("handler ctx should carry a cause")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler ctx was not cancelled")
+	}
+}
+
+func TestCallToolHonorsRequestContext(t *testing.T) {
+	server := NewServer("slow", "1.0.0")
+	started := make(chan struct{})
+	if err := server.RegisterTool(blockingTool("wait", 0, started, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	resp, err := server.HandleJSONRPC(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"wait"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := callToolResult(t, resp)
+	if result["is_error"] != true || !strings.Contains(errorText(result), "cancelled") {
+		t.Errorf("result = %v", result)
+	}
+}
+
+func TestNotificationsCancelledAbortsToolCall(t *testing.T) {
+	server := NewServer("slow", "1.0.0")
+	started := make(chan struct{})
+	if err := server.RegisterTool(blockingTool("wait", 0, started, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	respCh := make(chan []byte, 1)
+	go func() {
+		resp, _ := server.HandleJSONRPC(context.Background(), []byte(`{"jsonrpc":"2.0","id":"call-7","method":"tools/call","params":{"name":"wait"}}`))
+		respCh <- resp
+	}()
+	<-started
+
+	ack, err := server.HandleJSONRPC(nil, []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"call-7","reason":"user interrupted"}}`))
+	if err != nil || !strings.Contains(string(ack), `"result":{}`) {
+		t.Fatalf("ack = %s, err = %v", ack, err)
+	}
+
+	select {
+	case resp := <-respCh:
+		result := callToolResult(t, resp)
+		if result["is_error"] != true || !strings.Contains(errorText(result), "user interrupted") {
+			t.Errorf("result = %v", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("tool call was not cancelled")
+	}
+
+	// Cancelling an unknown or finished request is a no-op.
+	if _, err := server.HandleJSONRPC(nil, []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"call-7"}}`)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCallToolReturnsPromptlyForUncooperativeHandler(t *testing.T) {
+	server := NewServer("stuck", "1.0.0")
+	release := make(chan struct{})
+	defer close(release)
+	err := server.RegisterTool(&ToolDefinition{
+		Name:    "stuck",
+		Timeout: 10 * time.Millisecond,
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			<-release
+			return nil, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = server.CallTool(context.Background(), "stuck", nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("CallTool took %v", elapsed)
+	}
+}
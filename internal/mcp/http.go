@@ -0,0 +1,401 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// SessionIDHeader carries the Streamable HTTP session id.
+const SessionIDHeader = "Mcp-Session-Id"
+
+// maxHTTPMessageSize bounds a POSTed JSON-RPC message.
+const maxHTTPMessageSize = 10 * 1024 * 1024
+
+// HTTPOptions configures an HTTPHandler.
+type HTTPOptions struct {
+	// AllowedOrigins lists Origin header values accepted in addition to
+	// loopback origins. Requests from other origins are rejected to
+	// prevent DNS rebinding attacks. "*" allows any origin.
+	AllowedOrigins []string
+}
+
+// HTTPHandler serves a Server over HTTP. It implements both the Streamable
+// HTTP transport (POST/GET/DELETE on a single endpoint, responses as JSON or
+// SSE) and the older HTTP+SSE transport (a GET /sse event stream that
+// announces a POST /messages endpoint).
+//
+// Routes, relative to where the handler is mounted:
+//
+//	/sse       legacy SSE stream (GET)
+//	/messages  legacy message endpoint (POST ?sessionId=...)
+//	anything else  Streamable HTTP endpoint
+//
+// The handler subscribes to the server's notifications, alongside any
+// sender already attached, and fans them out to every open GET stream.
+// Close ends the subscription.
+type HTTPHandler struct {
+	server      *Server
+	options     HTTPOptions
+	unsubscribe func()
+
+	mu       sync.Mutex
+	sessions map[string]struct{}
+	issued   bool // a session has been issued; requests must name one
+	streams  map[*sseStream]struct{}
+	legacy   map[string]*sseStream
+}
+
+// NewHTTPHandler creates an HTTP handler for s.
+func NewHTTPHandler(s *Server, options HTTPOptions) *HTTPHandler {
+	h := &HTTPHandler{
+		server:   s,
+		options:  options,
+		sessions: make(map[string]struct{}),
+		streams:  make(map[*sseStream]struct{}),
+		legacy:   make(map[string]*sseStream),
+	}
+	h.unsubscribe = s.SubscribeNotifications(h.broadcast)
+	return h
+}
+
+// Close stops delivering the server's notifications to the handler's
+// streams. Open streams end when their requests do.
+func (h *HTTPHandler) Close() error {
+	h.unsubscribe()
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.originAllowed(r.Header.Get("Origin")) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/sse"):
+		h.serveLegacyStream(w, r)
+	case strings.HasSuffix(r.URL.Path, "/messages"):
+		h.serveLegacyMessage(w, r)
+	default:
+		h.serveStreamable(w, r)
+	}
+}
+
+func (h *HTTPHandler) originAllowed(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range h.options.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (h *HTTPHandler) serveStreamable(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.handlePost(w, r)
+	case http.MethodGet:
+		if !acceptsEventStream(r) {
+			http.Error(w, "GET requires Accept: text/event-stream", http.StatusMethodNotAllowed)
+			return
+		}
+		if !h.checkSession(w, r) {
+			return
+		}
+		stream, ok := newSSEStream(w)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		h.mu.Lock()
+		h.streams[stream] = struct{}{}
+		h.mu.Unlock()
+		defer func() {
+			h.mu.Lock()
+			delete(h.streams, stream)
+			h.mu.Unlock()
+			stream.close()
+		}()
+		<-r.Context().Done()
+	case http.MethodDelete:
+		id := r.Header.Get(SessionIDHeader)
+		h.mu.Lock()
+		_, ok := h.sessions[id]
+		delete(h.sessions, id)
+		h.mu.Unlock()
+		if !ok {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HTTPHandler) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHTTPMessageSize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		writeJSONRPCError(w, http.StatusBadRequest, -32600, "batch requests are not supported")
+		return
+	}
+
+	var envelope struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, -32700, "Parse error")
+		return
+	}
+
+	session := r.Header.Get(SessionIDHeader)
+	if envelope.Method == "initialize" {
+		id, err := newSessionID()
+		if err != nil {
+			http.Error(w, "failed to create session", http.StatusInternalServerError)
+			return
+		}
+		h.mu.Lock()
+		h.sessions[id] = struct{}{}
+		h.issued = true
+		h.mu.Unlock()
+		w.Header().Set(SessionIDHeader, id)
+		session = id
+	} else if !h.checkSession(w, r) {
+		return
+	}
+	ctx := WithSession(r.Context(), session)
+
+	if !expectsResponse(body) {
+		_, _ = h.server.HandleJSONRPC(ctx, body)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if acceptsEventStream(r) {
+		stream, ok := newSSEStream(w)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		defer stream.close()
+		resp, err := h.server.HandleJSONRPC(WithNotificationSender(ctx, stream.send), body)
+		if err == nil {
+			_ = stream.send(resp)
+		}
+		return
+	}
+
+	resp, err := h.server.HandleJSONRPC(ctx, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resp)
+}
+
+// checkSession rejects requests that name a session this handler did not
+// issue (or has since deleted), and, once a session has been issued,
+// requests that name none.
+func (h *HTTPHandler) checkSession(w http.ResponseWriter, r *http.Request) bool {
+	id := r.Header.Get(SessionIDHeader)
+	h.mu.Lock()
+	_, ok := h.sessions[id]
+	issued := h.issued
+	h.mu.Unlock()
+	if id == "" {
+		if issued {
+			http.Error(w, "missing "+SessionIDHeader+" header", http.StatusBadRequest)
+			return false
+		}
+		return true
+	}
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+func (h *HTTPHandler) serveLegacyStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := newSessionID()
+	if err != nil {
+		http.Error(w, "failed to create session", http.StatusInternalServerError)
+		return
+	}
+	stream, ok := newSSEStream(w)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	stream.ctx = r.Context()
+
+	h.mu.Lock()
+	h.legacy[id] = stream
+	h.streams[stream] = struct{}{}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.legacy, id)
+		delete(h.streams, stream)
+		h.mu.Unlock()
+		stream.close()
+	}()
+
+	endpoint := strings.TrimSuffix(r.URL.Path, "/sse") + "/messages?sessionId=" + id
+	if err := stream.event("endpoint", []byte(endpoint)); err != nil {
+		return
+	}
+	<-r.Context().Done()
+}
+
+func (h *HTTPHandler) serveLegacyMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := r.URL.Query().Get("sessionId")
+	h.mu.Lock()
+	stream, ok := h.legacy[id]
+	h.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHTTPMessageSize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+
+	// Responses travel on the SSE stream, so the request is handled under
+	// the stream's lifetime rather than this POST's.
+	go func() {
+		ctx := WithNotificationSender(WithSession(stream.ctx, id), stream.send)
+		resp, err := h.server.HandleJSONRPC(ctx, body)
+		if err == nil && expectsResponse(body) {
+			_ = stream.send(resp)
+		}
+	}()
+}
+
+// broadcast delivers a server-wide notification to every open stream.
+func (h *HTTPHandler) broadcast(notification []byte) error {
+	h.mu.Lock()
+	streams := make([]*sseStream, 0, len(h.streams))
+	for stream := range h.streams {
+		streams = append(streams, stream)
+	}
+	h.mu.Unlock()
+
+	for _, stream := range streams {
+		_ = stream.send(notification)
+	}
+	return nil
+}
+
+// sseStream writes server-sent events to one HTTP response.
+type sseStream struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+	ctx     context.Context
+	closed  bool
+}
+
+func newSSEStream(w http.ResponseWriter) (*sseStream, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseStream{w: w, flusher: flusher, ctx: context.Background()}, true
+}
+
+func (s *sseStream) send(message []byte) error {
+	return s.event("message", message)
+}
+
+// close stops further writes; the ResponseWriter must not be used once
+// the handler that owns it returns.
+func (s *sseStream) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+}
+
+func (s *sseStream) event(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return fmt.Errorf("stream closed")
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func writeJSONRPCError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(JSONRPCResponse{
+		JSONRPC: "2.0",
+		Error:   &JSONRPCError{Code: code, Message: message},
+	})
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
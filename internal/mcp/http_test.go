@@ -0,0 +1,291 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func post(t *testing.T, url, body string, headers map[string]string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	return resp
+}
+
+type sseEvent struct{ name, data string }
+
+// scanEvents delivers SSE events from r to fn until the stream ends or fn
+// returns false.
+func scanEvents(r io.Reader, fn func(sseEvent) bool) {
+	var name string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if !fn(sseEvent{name, strings.TrimPrefix(line, "data: ")}) {
+				return
+			}
+		}
+	}
+}
+
+func TestStreamableHTTP(t *testing.T) {
+	server := newEchoServer(t)
+	ts := httptest.NewServer(NewHTTPHandler(server, HTTPOptions{}))
+	defer ts.Close()
+	endpoint := ts.URL + "/mcp"
+
+	resp := post(t, endpoint, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`, map[string]string{"Accept": "application/json"})
+	session := resp.Header.Get(SessionIDHeader)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || session == "" || !strings.Contains(string(body), `"serverInfo"`) {
+		t.Fatalf("initialize: status %d session %q body %s", resp.StatusCode, session, body)
+	}
+	headers := map[string]string{SessionIDHeader: session}
+
+	resp = post(t, endpoint, `{"jsonrpc":"2.0","method":"notifications/initialized"}`, headers)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("notification status = %d, want 202", resp.StatusCode)
+	}
+
+	headers["Accept"] = "application/json, text/event-stream"
+	resp = post(t, endpoint, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"},"_meta":{"progressToken":"p"}}}`, headers)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content type = %q", ct)
+	}
+	var events []sseEvent
+	scanEvents(resp.Body, func(ev sseEvent) bool {
+		events = append(events, ev)
+		return true
+	})
+	resp.Body.Close()
+	if len(events) != 2 || !strings.Contains(events[0].data, "notifications/progress") || !strings.Contains(events[1].data, `"text":"hi"`) {
+		t.Errorf("events = %v", events)
+	}
+
+	resp = post(t, endpoint, `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("missing session status = %d, want 400", resp.StatusCode)
+	}
+
+	resp = post(t, endpoint, `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`, map[string]string{SessionIDHeader: "bogus"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown session status = %d, want 404", resp.StatusCode)
+	}
+
+	resp = post(t, endpoint, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`, map[string]string{"Origin": "https://evil.example"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("foreign origin status = %d, want 403", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, endpoint, nil)
+	req.Header.Set(SessionIDHeader, session)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("delete status = %d", resp.StatusCode)
+	}
+}
+
+func TestLegacySSETransport(t *testing.T) {
+	server := newEchoServer(t)
+	ts := httptest.NewServer(NewHTTPHandler(server, HTTPOptions{}))
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/mcp/sse", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+
+	events := make(chan sseEvent, 8)
+	go scanEvents(stream.Body, func(ev sseEvent) bool {
+		events <- ev
+		return true
+	})
+
+	next := func() sseEvent {
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for SSE event")
+			return sseEvent{}
+		}
+	}
+
+	endpoint := next()
+	if endpoint.name != "endpoint" || !strings.HasPrefix(endpoint.data, "/mcp/messages?sessionId=") {
+		t.Fatalf("endpoint event = %v", endpoint)
+	}
+
+	resp := post(t, ts.URL+endpoint.data, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"yo"}}}`, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("message status = %d", resp.StatusCode)
+	}
+
+	// Without a progressToken the only event is the response.
+	msg := next()
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(msg.data), &decoded); err != nil || decoded["id"] != float64(1) {
+		t.Errorf("response event = %v (%v)", msg, err)
+	}
+
+	// Server-wide notifications reach the open stream.
+	if err := server.sendNotification("notifications/tools/list_changed", nil); err != nil {
+		t.Fatal(err)
+	}
+	if ev := next(); !strings.Contains(ev.data, "list_changed") {
+		t.Errorf("broadcast event = %v", ev)
+	}
+}
+
+func TestHTTPHandlerSharesNotificationsWithControlSender(t *testing.T) {
+	server := newEchoServer(t)
+	var (
+		mu      sync.Mutex
+		control []string
+	)
+	server.SetNotificationSender(func(notification []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		control = append(control, string(notification))
+		return nil
+	})
+	handler := NewHTTPHandler(server, HTTPOptions{})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/mcp/sse", nil)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	events := make(chan sseEvent, 8)
+	go scanEvents(stream.Body, func(ev sseEvent) bool {
+		events <- ev
+		return true
+	})
+	<-events // endpoint
+
+	if err := server.sendNotification("notifications/resources/list_changed", nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-events:
+		if !strings.Contains(ev.data, "list_changed") {
+			t.Errorf("stream event = %v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("HTTP stream did not receive the notification")
+	}
+
+	_ = handler.Close()
+	_ = server.sendNotification("notifications/tools/list_changed", nil)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(control) != 2 || !strings.Contains(control[1], "tools/list_changed") {
+		t.Errorf("control sender received %v, want both notifications", control)
+	}
+	select {
+	case ev := <-events:
+		t.Errorf("closed handler still received %v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHTTPCancelScopedToSession(t *testing.T) {
+	server := NewServer("slow", "1.0.0")
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	err := server.RegisterTool(&ToolDefinition{
+		Name: "wait",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			started <- struct{}{}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-release:
+				return nil, nil
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(NewHTTPHandler(server, HTTPOptions{}))
+	defer ts.Close()
+	defer close(release)
+	endpoint := ts.URL + "/mcp"
+
+	// Both sessions use request id 1.
+	var results [2]chan string
+	var headers [2]map[string]string
+	for i := range results {
+		resp := post(t, endpoint, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`, nil)
+		resp.Body.Close()
+		headers[i] = map[string]string{SessionIDHeader: resp.Header.Get(SessionIDHeader), "Accept": "application/json"}
+		results[i] = make(chan string, 1)
+		go func(i int) {
+			resp := post(t, endpoint, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"wait"}}`, headers[i])
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			results[i] <- string(body)
+		}(i)
+	}
+	<-started
+	<-started
+
+	cancel := `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1,"reason":"stop"}}`
+	post(t, endpoint, cancel, headers[0]).Body.Close()
+	select {
+	case body := <-results[0]:
+		if !strings.Contains(body, "stop") {
+			t.Errorf("cancelled call = %s", body)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the cancelled session's call kept running")
+	}
+	select {
+	case body := <-results[1]:
+		t.Fatalf("another session's call was cancelled: %s", body)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	post(t, endpoint, cancel, headers[1]).Body.Close()
+	select {
+	case <-results[1]:
+	case <-time.After(time.Second):
+		t.Fatal("the second session's call was not cancelled")
+	}
+}
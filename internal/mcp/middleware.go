@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrToolPanicked is returned by RecoverMiddleware when a tool handler
+// panics.
+var ErrToolPanicked = errors.New("tool panicked")
+
+// ToolCall describes one tool invocation as seen by middleware.
+type ToolCall struct {
+	Name        string
+	Arguments   map[string]interface{}
+	Annotations map[string]interface{}
+}
+
+// ToolInvoker runs a tool call and returns its result.
+type ToolInvoker func(ctx context.Context, call *ToolCall) ([]Content, error)
+
+// ToolMiddleware wraps a ToolInvoker. Middleware may inspect or rewrite the
+// call before invoking next, inspect or replace the result afterwards, or
+// return without calling next at all.
+type ToolMiddleware func(next ToolInvoker) ToolInvoker
+
+// Use appends middleware to the chain around every tool handler, including
+// tools registered later. The first middleware is the outermost.
+func (s *Server) Use(middleware ...ToolMiddleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, mw := range middleware {
+		if mw != nil {
+			s.middleware = append(s.middleware, mw)
+		}
+	}
+}
+
+// invoker builds the middleware chain around a tool's handler.
+func (s *Server) invoker(tool *ToolDefinition) ToolInvoker {
+	s.mu.RLock()
+	chain := append([]ToolMiddleware(nil), s.middleware...)
+	s.mu.RUnlock()
+
+	invoke := func(ctx context.Context, call *ToolCall) ([]Content, error) {
+		return tool.Handler(ctx, call.Arguments)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		invoke = chain[i](invoke)
+	}
+	return invoke
+}
+
+// RecoverMiddleware turns a panic in a tool handler, or in middleware
+// further down the chain, into an error wrapping ErrToolPanicked, so the
+// call is reported as an is_error result instead of crashing the process.
+func RecoverMiddleware() ToolMiddleware {
+	return func(next ToolInvoker) ToolInvoker {
+		return func(ctx context.Context, call *ToolCall) (content []Content, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					content = nil
+					err = panicError(call, r)
+				}
+			}()
+			return next(ctx, call)
+		}
+	}
+}
+
+func panicError(call *ToolCall, r interface{}) error {
+	return fmt.Errorf("%w: tool '%s': %v", ErrToolPanicked, call.Name, r)
+}
+
+// TimeoutMiddleware bounds every tool call by d. A ToolDefinition.Timeout
+// still applies; the shorter limit wins. When d elapses the handler's ctx
+// is cancelled and the call fails with ErrToolTimeout.
+//
+// The rest of the chain runs on its own goroutine, where a panic cannot
+// reach RecoverMiddleware placed before this one, so panics there are
+// recovered here and reported as ErrToolPanicked the same way.
+func TimeoutMiddleware(d time.Duration) ToolMiddleware {
+	return func(next ToolInvoker) ToolInvoker {
+		return func(ctx context.Context, call *ToolCall) ([]Content, error) {
+			if d <= 0 {
+				return next(ctx, call)
+			}
+			ctx, cancel := context.WithTimeoutCause(ctx, d,
+				fmt.Errorf("%w: tool '%s' exceeded %s", ErrToolTimeout, call.Name, d))
+			defer cancel()
+
+			type callResult struct {
+				content []Content
+				err     error
+			}
+			done := make(chan callResult, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- callResult{err: panicError(call, r)}
+					}
+				}()
+				content, err := next(ctx, call)
+				done <- callResult{content: content, err: err}
+			}()
+
+			select {
+			case result := <-done:
+				return result.content, result.err
+			case <-ctx.Done():
+				return nil, toolContextError(ctx)
+			}
+		}
+	}
+}
+
+// LoggingMiddleware reports each tool call's outcome and duration to log.
+// Arguments are not logged; put a redacting middleware in front if they
+// should be.
+func LoggingMiddleware(log func(message string)) ToolMiddleware {
+	return func(next ToolInvoker) ToolInvoker {
+		return func(ctx context.Context, call *ToolCall) ([]Content, error) {
+			start := time.Now()
+			content, err := next(ctx, call)
+			elapsed := time.Since(start).Round(time.Millisecond)
+			if log != nil {
+				if err != nil {
+					log(fmt.Sprintf("mcp tool '%s' failed after %s: %v", call.Name, elapsed, err))
+				} else {
+					log(fmt.Sprintf("mcp tool '%s' completed in %s", call.Name, elapsed))
+				}
+			}
+			return content, err
+		}
+	}
+}
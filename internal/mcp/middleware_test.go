@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareOrderAndAccess(t *testing.T) {
+	server := NewServer("mw", "1.0.0")
+	var order []string
+	trace := func(label string) ToolMiddleware {
+		return func(next ToolInvoker) ToolInvoker {
+			return func(ctx context.Context, call *ToolCall) ([]Content, error) {
+				order = append(order, label+":"+call.Name)
+				return next(ctx, call)
+			}
+		}
+	}
+	redact := func(next ToolInvoker) ToolInvoker {
+		return func(ctx context.Context, call *ToolCall) ([]Content, error) {
+			if call.Annotations["sensitive"] == true {
+				call.Arguments["token"] = "[redacted]"
+			}
+			return next(ctx, call)
+		}
+	}
+	server.Use(trace("outer"), redact, trace("inner"))
+
+	_ = server.RegisterTool(&ToolDefinition{
+		Name:        "login",
+		Annotations: map[string]interface{}{"sensitive": true},
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			order = append(order, "handler")
+			return []Content{&TextContent{Type: ContentTypeText, Text: args["token"].(string)}}, nil
+		},
+	})
+
+	content, err := server.CallTool(context.Background(), "login", map[string]interface{}{"token": "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := content[0].(*TextContent).Text; got != "[redacted]" {
+		t.Errorf("handler saw token %q", got)
+	}
+	if want := "outer:login,inner:login,handler"; strings.Join(order, ",") != want {
+		t.Errorf("order = %v, want %s", order, want)
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	server := NewServer("mw", "1.0.0")
+	server.Use(RecoverMiddleware())
+	_ = server.RegisterTool(&ToolDefinition{
+		Name: "boom",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			panic("kaboom")
+		},
+	})
+
+	_, err := server.CallTool(context.Background(), "boom", nil)
+	if !errors.Is(err, ErrToolPanicked) || !strings.Contains(err.Error(), "kaboom") {
+		t.Fatalf("err = %v", err)
+	}
+
+	resp := rpc(t, server, "tools/call", map[string]interface{}{"name": "boom"})
+	result := resp["result"].(map[string]interface{})
+	if result["is_error"] != true {
+		t.Errorf("tools/call result = %v, want is_error", result)
+	}
+}
+
+func TestRecoverBeforeTimeoutMiddleware(t *testing.T) {
+	server := NewServer("mw", "1.0.0")
+	server.Use(RecoverMiddleware(), TimeoutMiddleware(time.Second))
+	_ = server.RegisterTool(&ToolDefinition{
+		Name: "boom",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			panic("kaboom")
+		},
+	})
+
+	_, err := server.CallTool(context.Background(), "boom", nil)
+	if !errors.Is(err, ErrToolPanicked) || !strings.Contains(err.Error(), "kaboom") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	server := NewServer("mw", "1.0.0")
+	server.Use(TimeoutMiddleware(20 * time.Millisecond))
+	released := make(chan struct{})
+	_ = server.RegisterTool(&ToolDefinition{
+		Name: "slow",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			<-ctx.Done()
+			close(released)
+			return nil, ctx.Err()
+		},
+	})
+
+	_, err := server.CallTool(context.Background(), "slow", nil)
+	if !errors.Is(err, ErrToolTimeout) {
+		t.Fatalf("err = %v, want ErrToolTimeout", err)
+	}
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Error("handler context was not cancelled")
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	server := NewServer("mw", "1.0.0")
+	var lines []string
+	server.Use(LoggingMiddleware(func(msg string) { lines = append(lines, msg) }))
+	_ = server.RegisterTool(&ToolDefinition{
+		Name: "ok",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			return nil, nil
+		},
+	})
+	_ = server.RegisterTool(&ToolDefinition{
+		Name: "bad",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			return nil, errors.New("disk full")
+		},
+	})
+
+	_, _ = server.CallTool(context.Background(), "ok", nil)
+	_, _ = server.CallTool(context.Background(), "bad", nil)
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "mcp tool 'ok' completed in") ||
+		!strings.Contains(lines[1], "mcp tool 'bad' failed after") || !strings.HasSuffix(lines[1], "disk full") {
+		t.Errorf("log = %q", lines)
+	}
+}
@@ -0,0 +1,236 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// LoggingLevel is an MCP log severity (RFC 5424 names).
+type LoggingLevel string
+
+const (
+	LoggingLevelDebug     LoggingLevel = "debug"
+	LoggingLevelInfo      LoggingLevel = "info"
+	LoggingLevelNotice    LoggingLevel = "notice"
+	LoggingLevelWarning   LoggingLevel = "warning"
+	LoggingLevelError     LoggingLevel = "error"
+	LoggingLevelCritical  LoggingLevel = "critical"
+	LoggingLevelAlert     LoggingLevel = "alert"
+	LoggingLevelEmergency LoggingLevel = "emergency"
+)
+
+var loggingLevelRank = map[LoggingLevel]int{
+	LoggingLevelDebug:     0,
+	LoggingLevelInfo:      1,
+	LoggingLevelNotice:    2,
+	LoggingLevelWarning:   3,
+	LoggingLevelError:     4,
+	LoggingLevelCritical:  5,
+	LoggingLevelAlert:     6,
+	LoggingLevelEmergency: 7,
+}
+
+// RequestNotifier emits notifications tied to one in-flight request. A nil
+// *RequestNotifier is valid and drops everything.
+type RequestNotifier struct {
+	server        *Server
+	send          func(notification []byte) error
+	progressToken interface{}
+	logger        string
+}
+
+type notifierContextKey struct{}
+
+type notificationSenderContextKey struct{}
+
+// WithNotificationSender returns a context whose requests deliver their
+// notifications (progress, log records) through send instead of the
+// server-wide sender. Transports that can answer on a per-request stream,
+// such as Streamable HTTP, use it.
+func WithNotificationSender(ctx context.Context, send func(notification []byte) error) context.Context {
+	return context.WithValue(ctx, notificationSenderContextKey{}, send)
+}
+
+type sessionContextKey struct{}
+
+// WithSession returns a context whose requests belong to the client session
+// id. JSON-RPC request ids are only unique within a session, so
+// notifications/cancelled reaches only the tools/call requests of its own
+// session. Transports serving several clients at once, such as the HTTP
+// handler, set it.
+func WithSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, id)
+}
+
+// sessionFromContext returns the session set by WithSession, or "".
+func sessionFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionContextKey{}).(string)
+	return id
+}
+
+// NotifierFromContext returns the notifier attached to a tool handler's
+// context, or nil when the handler was not invoked through tools/call.
+func NotifierFromContext(ctx context.Context) *RequestNotifier {
+	n, _ := ctx.Value(notifierContextKey{}).(*RequestNotifier)
+	return n
+}
+
+func withNotifier(ctx context.Context, n *RequestNotifier) context.Context {
+	return context.WithValue(ctx, notifierContextKey{}, n)
+}
+
+// Progress sends notifications/progress for the request's progressToken.
+// total and message are omitted when zero. It is a no-op when the client did
+// not ask for progress (no _meta.progressToken on the request).
+func (n *RequestNotifier) Progress(progress, total float64, message string) error {
+	if n == nil || n.progressToken == nil {
+		return nil
+	}
+	params := map[string]interface{}{
+		"progressToken": n.progressToken,
+		"progress":      progress,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	return n.emit("notifications/progress", params)
+}
+
+// Log sends a notifications/message log record. Records below the level set
+// by logging/setLevel are dropped.
+func (n *RequestNotifier) Log(level LoggingLevel, data interface{}) error {
+	if n == nil {
+		return nil
+	}
+	rank, ok := loggingLevelRank[level]
+	if !ok {
+		return fmt.Errorf("invalid logging level %q", level)
+	}
+	if rank < n.server.minLogRank() {
+		return nil
+	}
+	params := map[string]interface{}{
+		"level": level,
+		"data":  data,
+	}
+	if n.logger != "" {
+		params["logger"] = n.logger
+	}
+	return n.emit("notifications/message", params)
+}
+
+func (n *RequestNotifier) emit(method string, params map[string]interface{}) error {
+	if n.send == nil {
+		return n.server.sendNotification(method, params)
+	}
+	data, err := marshalNotification(method, params)
+	if err != nil {
+		return err
+	}
+	return n.send(data)
+}
+
+func (s *Server) minLogRank() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return loggingLevelRank[s.logLevel]
+}
+
+func (s *Server) handleSetLogLevel(request JSONRPCRequest) ([]byte, error) {
+	level, _ := request.Params["level"].(string)
+	if _, ok := loggingLevelRank[LoggingLevel(level)]; !ok {
+		return s.errorResponse(request.ID, -32602, fmt.Sprintf("Invalid params: unknown level '%s'", level), nil)
+	}
+
+	s.mu.Lock()
+	s.logLevel = LoggingLevel(level)
+	s.mu.Unlock()
+
+	return s.successResponse(request.ID, map[string]interface{}{})
+}
+
+// requestNotifier builds the notifier for a tools/call request.
+func (s *Server) requestNotifier(ctx context.Context, request JSONRPCRequest, toolName string) *RequestNotifier {
+	n := &RequestNotifier{server: s, logger: toolName}
+	n.send, _ = ctx.Value(notificationSenderContextKey{}).(func(notification []byte) error)
+	if meta, ok := request.Params["_meta"].(map[string]interface{}); ok {
+		n.progressToken = meta["progressToken"]
+	}
+	return n
+}
+
+// SetNotificationSender attaches the function used to deliver server-initiated
+// JSON-RPC notifications (for example notifications/resources/updated) to the
+// client. The SDK control protocol installs one that forwards them to the CLI.
+func (s *Server) SetNotificationSender(send func(notification []byte) error) {
+	s.mu.Lock()
+	s.notify = send
+	s.mu.Unlock()
+}
+
+// SubscribeNotifications delivers server-initiated notifications to send
+// in addition to the sender set with SetNotificationSender, so a server can
+// serve the CLI and other transports at once. Call the returned function to
+// unsubscribe.
+func (s *Server) SubscribeNotifications(send func(notification []byte) error) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[uint64]func(notification []byte) error)
+	}
+	s.nextSubscriber++
+	id := s.nextSubscriber
+	s.subscribers[id] = send
+	return func() {
+		s.mu.Lock()
+		delete(s.subscribers, id)
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) sendNotification(method string, params map[string]interface{}) error {
+	s.mu.RLock()
+	senders := make([]func(notification []byte) error, 0, len(s.subscribers)+1)
+	if s.notify != nil {
+		senders = append(senders, s.notify)
+	}
+	for _, send := range s.subscribers {
+		senders = append(senders, send)
+	}
+	s.mu.RUnlock()
+	if len(senders) == 0 {
+		return nil
+	}
+
+	data, err := marshalNotification(method, params)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, send := range senders {
+		if err := send(data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func marshalNotification(method string, params map[string]interface{}) ([]byte, error) {
+	notification := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+	}
+	if params != nil {
+		notification["params"] = params
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestToolNotifierProgressAndLog(t *testing.T) {
+	server := NewServer("notify", "1.0.0")
+	err := server.RegisterTool(&ToolDefinition{
+		Name: "index",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			n := NotifierFromContext(ctx)
+			if err := n.Progress(1, 2, "half way"); err != nil {
+				return nil, err
+			}
+			if err := n.Log(LoggingLevelDebug, "verbose"); err != nil {
+				return nil, err
+			}
+			if err := n.Log(LoggingLevelWarning, map[string]interface{}{"file": "a.go"}); err != nil {
+				return nil, err
+			}
+			return []Content{&TextContent{Type: ContentTypeText, Text: "ok"}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []map[string]interface{}
+	server.SetNotificationSender(func(notification []byte) error {
+		var msg map[string]interface{}
+		if err := json.Unmarshal(notification, &msg); err != nil {
+			return err
+		}
+		sent = append(sent, msg)
+		return nil
+	})
+
+	rpc(t, server, "logging/setLevel", map[string]interface{}{"level": "info"})
+	rpc(t, server, "tools/call", map[string]interface{}{
+		"name":  "index",
+		"_meta": map[string]interface{}{"progressToken": "tok-1"},
+	})
+
+	if len(sent) != 2 {
+		t.Fatalf("sent = %v, want progress and warning", sent)
+	}
+	progress := sent[0]
+	params := progress["params"].(map[string]interface{})
+	if progress["method"] != "notifications/progress" || params["progressToken"] != "tok-1" ||
+		params["progress"] != float64(1) || params["total"] != float64(2) || params["message"] != "half way" {
+		t.Errorf("progress = %v", progress)
+	}
+	logMsg := sent[1]
+	params = logMsg["params"].(map[string]interface{})
+	if logMsg["method"] != "notifications/message" || params["level"] != "warning" || params["logger"] != "index" {
+		t.Errorf("log = %v", logMsg)
+	}
+}
+
+func TestToolNotifierWithoutProgressToken(t *testing.T) {
+	server := NewServer("notify", "1.0.0")
+	_ = server.RegisterTool(&ToolDefinition{
+		Name: "work",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			return nil, NotifierFromContext(ctx).Progress(1, 0, "")
+		},
+	})
+	var sent int
+	server.SetNotificationSender(func([]byte) error { sent++; return nil })
+
+	rpc(t, server, "tools/call", map[string]interface{}{"name": "work"})
+	if sent != 0 {
+		t.Errorf("progress sent without token")
+	}
+
+	var nilNotifier *RequestNotifier
+	if err := nilNotifier.Log(LoggingLevelInfo, "x"); err != nil {
+		t.Errorf("nil notifier Log: %v", err)
+	}
+	if resp := rpc(t, server, "logging/setLevel", map[string]interface{}{"level": "loud"}); resp["error"] == nil {
+		t.Errorf("expected error for unknown level, got %v", resp)
+	}
+}
@@ -0,0 +1,191 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrPromptNotFound is returned by GetPrompt for unknown prompt names.
+var ErrPromptNotFound = errors.New("prompt not found")
+
+// PromptHandler renders a prompt from its arguments.
+type PromptHandler func(ctx context.Context, args map[string]string) (*GetPromptResult, error)
+
+// PromptArgument describes an argument accepted by a prompt.
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptDefinition defines a prompt with its handler.
+type PromptDefinition struct {
+	Name        string
+	Title       string
+	Description string
+	Arguments   []PromptArgument
+	Handler     PromptHandler
+}
+
+// Prompt represents an MCP prompt in prompts/list.
+type Prompt struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptMessage is one message of a rendered prompt.
+type PromptMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// GetPromptResult represents the result of prompts/get.
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// ListPromptsResult represents the result of listing prompts.
+type ListPromptsResult struct {
+	Prompts []Prompt `json:"prompts"`
+}
+
+// RegisterPrompt registers a prompt with the server.
+func (s *Server) RegisterPrompt(prompt *PromptDefinition) error {
+	if prompt == nil {
+		return fmt.Errorf("prompt definition cannot be nil")
+	}
+	if prompt.Name == "" {
+		return fmt.Errorf("prompt name cannot be empty")
+	}
+	if prompt.Handler == nil {
+		return fmt.Errorf("prompt handler cannot be nil")
+	}
+	seen := make(map[string]bool, len(prompt.Arguments))
+	for _, arg := range prompt.Arguments {
+		if arg.Name == "" {
+			return fmt.Errorf("prompt %s: argument name cannot be empty", prompt.Name)
+		}
+		if seen[arg.Name] {
+			return fmt.Errorf("prompt %s: duplicate argument %s", prompt.Name, arg.Name)
+		}
+		seen[arg.Name] = true
+	}
+
+	s.mu.Lock()
+	s.prompts[prompt.Name] = prompt
+	s.mu.Unlock()
+
+	_ = s.sendNotification("notifications/prompts/list_changed", nil)
+	return nil
+}
+
+// ListPrompts returns all registered prompts sorted by name.
+func (s *Server) ListPrompts() []Prompt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Prompt, 0, len(s.prompts))
+	for _, def := range s.prompts {
+		result = append(result, Prompt{
+			Name:        def.Name,
+			Title:       def.Title,
+			Description: def.Description,
+			Arguments:   def.Arguments,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// GetPrompt renders the named prompt. Missing required arguments are
+// reported before the handler runs.
+func (s *Server) GetPrompt(ctx context.Context, name string, args map[string]string) (*GetPromptResult, error) {
+	s.mu.RLock()
+	prompt, ok := s.prompts[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPromptNotFound, name)
+	}
+
+	var missing []string
+	for _, arg := range prompt.Arguments {
+		if _, ok := args[arg.Name]; arg.Required && !ok {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &invalidParamsError{message: fmt.Sprintf("missing required arguments: %s", strings.Join(missing, ", "))}
+	}
+
+	result, err := prompt.Handler(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = &GetPromptResult{}
+	}
+	if result.Description == "" {
+		result.Description = prompt.Description
+	}
+	if result.Messages == nil {
+		result.Messages = []PromptMessage{}
+	}
+	return result, nil
+}
+
+func (s *Server) hasPrompts() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.prompts) > 0
+}
+
+// invalidParamsError maps to JSON-RPC -32602.
+type invalidParamsError struct {
+	message string
+}
+
+func (e *invalidParamsError) Error() string {
+	return e.message
+}
+
+func (s *Server) handleListPrompts(request JSONRPCRequest) ([]byte, error) {
+	return s.successResponse(request.ID, ListPromptsResult{Prompts: s.ListPrompts()})
+}
+
+func (s *Server) handleGetPrompt(ctx context.Context, request JSONRPCRequest) ([]byte, error) {
+	name, ok := request.Params["name"].(string)
+	if !ok || name == "" {
+		return s.errorResponse(request.ID, -32602, "Invalid params: missing 'name'", nil)
+	}
+
+	args := make(map[string]string)
+	if raw, ok := request.Params["arguments"].(map[string]interface{}); ok {
+		for key, value := range raw {
+			if str, ok := value.(string); ok {
+				args[key] = str
+			} else {
+				args[key] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	result, err := s.GetPrompt(ctx, name, args)
+	if err != nil {
+		var invalid *invalidParamsError
+		switch {
+		case errors.Is(err, ErrPromptNotFound):
+			return s.errorResponse(request.ID, -32602, fmt.Sprintf("Prompt '%s' not found", name), nil)
+		case errors.As(err, &invalid):
+			return s.errorResponse(request.ID, -32602, "Invalid params: "+invalid.message, nil)
+		default:
+			return s.errorResponse(request.ID, -32603, err.Error(), nil)
+		}
+	}
+	return s.successResponse(request.ID, result)
+}
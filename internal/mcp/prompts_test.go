@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func newPromptServer(t *testing.T) *Server {
+	t.Helper()
+	server := NewServer("prompts", "1.0.0")
+	err := server.RegisterPrompt(&PromptDefinition{
+		Name:        "review",
+		Description: "Review a file",
+		Arguments: []PromptArgument{
+			{Name: "path", Required: true},
+			{Name: "focus"},
+		},
+		Handler: func(ctx context.Context, args map[string]string) (*GetPromptResult, error) {
+			if args["path"] == "fail" {
+				return nil, errors.New("cannot render")
+			}
+			return &GetPromptResult{Messages: []PromptMessage{
+				{Role: "user", Content: &TextContent{Type: ContentTypeText, Text: "Review " + args["path"]}},
+				{Role: "user", Content: &ResourceContent{Type: ContentTypeResource, Resource: EmbeddedResource{URI: "file:///" + args["path"], Text: "code"}}},
+			}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func TestPromptsCapabilityAndList(t *testing.T) {
+	server := newPromptServer(t)
+	caps := rpc(t, server, "initialize", nil)["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	if _, ok := caps["prompts"].(map[string]interface{}); !ok {
+		t.Errorf("prompts capability missing: %v", caps)
+	}
+
+	prompts := rpc(t, server, "prompts/list", nil)["result"].(map[string]interface{})["prompts"].([]interface{})
+	if len(prompts) != 1 {
+		t.Fatalf("prompts = %v", prompts)
+	}
+	prompt := prompts[0].(map[string]interface{})
+	args := prompt["arguments"].([]interface{})
+	if prompt["name"] != "review" || len(args) != 2 || args[0].(map[string]interface{})["required"] != true {
+		t.Errorf("prompt = %v", prompt)
+	}
+}
+
+func TestPromptsGet(t *testing.T) {
+	server := newPromptServer(t)
+
+	result := rpc(t, server, "prompts/get", map[string]interface{}{
+		"name":      "review",
+		"arguments": map[string]interface{}{"path": "main.go"},
+	})["result"].(map[string]interface{})
+	if result["description"] != "Review a file" {
+		t.Errorf("description = %v", result["description"])
+	}
+	messages := result["messages"].([]interface{})
+	if len(messages) != 2 {
+		t.Fatalf("messages = %v", messages)
+	}
+	first := messages[0].(map[string]interface{})
+	if first["role"] != "user" || first["content"].(map[string]interface{})["text"] != "Review main.go" {
+		t.Errorf("first message = %v", first)
+	}
+	second := messages[1].(map[string]interface{})["content"].(map[string]interface{})
+	if second["type"] != "resource" || second["resource"].(map[string]interface{})["uri"] != "file:///main.go" {
+		t.Errorf("second message content = %v", second)
+	}
+
+	errorTests := []struct {
+		name   string
+		params map[string]interface{}
+		code   float64
+	}{
+		{"missing name", map[string]interface{}{}, -32602},
+		{"unknown prompt", map[string]interface{}{"name": "nope"}, -32602},
+		{"missing required argument", map[string]interface{}{"name": "review"}, -32602},
+		{"handler error", map[string]interface{}{"name": "review", "arguments": map[string]interface{}{"path": "fail"}}, -32603},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := rpc(t, server, "prompts/get", tt.params)
+			rpcErr, ok := resp["error"].(map[string]interface{})
+			if !ok || rpcErr["code"] != tt.code {
+				t.Errorf("response = %v, want code %v", resp, tt.code)
+			}
+		})
+	}
+}
+
+func TestRegisterPromptValidation(t *testing.T) {
+	server := NewServer("prompts", "1.0.0")
+	handler := func(context.Context, map[string]string) (*GetPromptResult, error) { return nil, nil }
+	invalid := []*PromptDefinition{
+		nil,
+		{Handler: handler},
+		{Name: "x"},
+		{Name: "x", Handler: handler, Arguments: []PromptArgument{{Name: "a"}, {Name: "a"}}},
+	}
+	for i, def := range invalid {
+		if err := server.RegisterPrompt(def); err == nil {
+			t.Errorf("definition %d: expected error", i)
+		}
+	}
+}
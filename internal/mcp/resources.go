@@ -0,0 +1,354 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrResourceNotFound is returned by ReadResource when no static resource or
+// template matches the requested URI.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// ResourceHandler returns the contents of a static resource.
+type ResourceHandler func(ctx context.Context, uri string) ([]EmbeddedResource, error)
+
+// ResourceTemplateHandler returns the contents of a resource matched by a URI
+// template. params holds the percent-decoded values of the template
+// variables.
+type ResourceTemplateHandler func(ctx context.Context, uri string, params map[string]string) ([]EmbeddedResource, error)
+
+// ResourceDefinition defines a static resource with its handler.
+type ResourceDefinition struct {
+	URI         string
+	Name        string
+	Title       string
+	Description string
+	MimeType    string
+	Handler     ResourceHandler
+}
+
+// ResourceTemplateDefinition defines a family of resources addressed by an
+// RFC 6570 URI template. Simple ({var}) and reserved ({+var}) expansions
+// are supported.
+type ResourceTemplateDefinition struct {
+	URITemplate string
+	Name        string
+	Title       string
+	Description string
+	MimeType    string
+	Handler     ResourceTemplateHandler
+
+	pattern *regexp.Regexp
+	vars    []string
+}
+
+var uriTemplateExpr = regexp.MustCompile(`\{([^}]*)\}`)
+
+// compile builds the regular expression used to match URIs against the
+// template.
+func (t *ResourceTemplateDefinition) compile() error {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	t.vars = nil
+	last := 0
+	for _, loc := range uriTemplateExpr.FindAllStringSubmatchIndex(t.URITemplate, -1) {
+		pattern.WriteString(regexp.QuoteMeta(t.URITemplate[last:loc[0]]))
+		expr := t.URITemplate[loc[2]:loc[3]]
+		valuePattern := `([^/?#]+)`
+		if strings.HasPrefix(expr, "+") {
+			expr = expr[1:]
+			valuePattern = `(.+)`
+		}
+		if expr == "" || strings.ContainsAny(expr, "#./;?&=,!@|*:") {
+			return fmt.Errorf("unsupported URI template expression %q", t.URITemplate[loc[0]:loc[1]])
+		}
+		t.vars = append(t.vars, expr)
+		pattern.WriteString(valuePattern)
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(t.URITemplate[last:]))
+	pattern.WriteString("$")
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return fmt.Errorf("invalid URI template %q: %w", t.URITemplate, err)
+	}
+	t.pattern = re
+	return nil
+}
+
+// match reports whether uri matches the template and returns the
+// variables, percent-decoded. A variable that is not valid percent-encoding
+// does not match.
+func (t *ResourceTemplateDefinition) match(uri string) (map[string]string, bool) {
+	m := t.pattern.FindStringSubmatch(uri)
+	if m == nil {
+		return nil, false
+	}
+	params := make(map[string]string, len(t.vars))
+	for i, name := range t.vars {
+		value, err := url.PathUnescape(m[i+1])
+		if err != nil {
+			return nil, false
+		}
+		params[name] = value
+	}
+	return params, true
+}
+
+// Resource represents an MCP resource in resources/list.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate represents an MCP resource template in
+// resources/templates/list.
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ListResourcesResult represents the result of listing resources.
+type ListResourcesResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+// ListResourceTemplatesResult represents the result of listing resource
+// templates.
+type ListResourceTemplatesResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+}
+
+// ReadResourceResult represents the result of reading a resource.
+type ReadResourceResult struct {
+	Contents []EmbeddedResource `json:"contents"`
+}
+
+// RegisterResource registers a static resource with the server.
+func (s *Server) RegisterResource(resource *ResourceDefinition) error {
+	if resource == nil {
+		return fmt.Errorf("resource definition cannot be nil")
+	}
+	if resource.URI == "" {
+		return fmt.Errorf("resource URI cannot be empty")
+	}
+	if resource.Handler == nil {
+		return fmt.Errorf("resource handler cannot be nil")
+	}
+
+	s.mu.Lock()
+	s.resources[resource.URI] = resource
+	s.mu.Unlock()
+
+	s.notifyResourceListChanged()
+	return nil
+}
+
+// RegisterResourceTemplate registers a URI-template resource handler with
+// the server. Templates are matched in registration order after static
+// resources.
+func (s *Server) RegisterResourceTemplate(template *ResourceTemplateDefinition) error {
+	if template == nil {
+		return fmt.Errorf("resource template definition cannot be nil")
+	}
+	if template.URITemplate == "" {
+		return fmt.Errorf("resource URI template cannot be empty")
+	}
+	if template.Handler == nil {
+		return fmt.Errorf("resource template handler cannot be nil")
+	}
+	if err := template.compile(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	replaced := false
+	for i, existing := range s.resourceTemplates {
+		if existing.URITemplate == template.URITemplate {
+			s.resourceTemplates[i] = template
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		s.resourceTemplates = append(s.resourceTemplates, template)
+	}
+	s.mu.Unlock()
+
+	s.notifyResourceListChanged()
+	return nil
+}
+
+// ListResources returns all registered static resources sorted by URI.
+func (s *Server) ListResources() []Resource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Resource, 0, len(s.resources))
+	for _, def := range s.resources {
+		name := def.Name
+		if name == "" {
+			name = def.URI
+		}
+		result = append(result, Resource{
+			URI:         def.URI,
+			Name:        name,
+			Title:       def.Title,
+			Description: def.Description,
+			MimeType:    def.MimeType,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].URI < result[j].URI })
+	return result
+}
+
+// ListResourceTemplates returns all registered resource templates in
+// registration order.
+func (s *Server) ListResourceTemplates() []ResourceTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]ResourceTemplate, 0, len(s.resourceTemplates))
+	for _, def := range s.resourceTemplates {
+		name := def.Name
+		if name == "" {
+			name = def.URITemplate
+		}
+		result = append(result, ResourceTemplate{
+			URITemplate: def.URITemplate,
+			Name:        name,
+			Title:       def.Title,
+			Description: def.Description,
+			MimeType:    def.MimeType,
+		})
+	}
+	return result
+}
+
+// ReadResource reads the resource identified by uri. Static resources take
+// precedence over templates. Contents without a URI or MIME type inherit
+// them from the request and the definition.
+func (s *Server) ReadResource(ctx context.Context, uri string) ([]EmbeddedResource, error) {
+	s.mu.RLock()
+	static, ok := s.resources[uri]
+	templates := append([]*ResourceTemplateDefinition(nil), s.resourceTemplates...)
+	s.mu.RUnlock()
+
+	var (
+		contents []EmbeddedResource
+		mimeType string
+		err      error
+	)
+	switch {
+	case ok:
+		mimeType = static.MimeType
+		contents, err = static.Handler(ctx, uri)
+	default:
+		matched := false
+		for _, tmpl := range templates {
+			params, ok := tmpl.match(uri)
+			if !ok {
+				continue
+			}
+			matched = true
+			mimeType = tmpl.MimeType
+			contents, err = tmpl.Handler(ctx, uri, params)
+			break
+		}
+		if !matched {
+			return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range contents {
+		if contents[i].URI == "" {
+			contents[i].URI = uri
+		}
+		if contents[i].MimeType == "" {
+			contents[i].MimeType = mimeType
+		}
+	}
+	return contents, nil
+}
+
+// NotifyResourceUpdated sends notifications/resources/updated for uri if the
+// client subscribed to it. It is a no-op when no notification sender is
+// attached.
+func (s *Server) NotifyResourceUpdated(uri string) error {
+	s.mu.RLock()
+	_, subscribed := s.subscriptions[uri]
+	s.mu.RUnlock()
+	if !subscribed {
+		return nil
+	}
+	return s.sendNotification("notifications/resources/updated", map[string]interface{}{"uri": uri})
+}
+
+func (s *Server) notifyResourceListChanged() {
+	_ = s.sendNotification("notifications/resources/list_changed", nil)
+}
+
+func (s *Server) hasResources() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.resources) > 0 || len(s.resourceTemplates) > 0
+}
+
+func (s *Server) handleListResources(request JSONRPCRequest) ([]byte, error) {
+	return s.successResponse(request.ID, ListResourcesResult{Resources: s.ListResources()})
+}
+
+func (s *Server) handleListResourceTemplates(request JSONRPCRequest) ([]byte, error) {
+	return s.successResponse(request.ID, ListResourceTemplatesResult{ResourceTemplates: s.ListResourceTemplates()})
+}
+
+func (s *Server) handleReadResource(ctx context.Context, request JSONRPCRequest) ([]byte, error) {
+	uri, ok := request.Params["uri"].(string)
+	if !ok || uri == "" {
+		return s.errorResponse(request.ID, -32602, "Invalid params: missing 'uri'", nil)
+	}
+
+	contents, err := s.ReadResource(ctx, uri)
+	if err != nil {
+		if errors.Is(err, ErrResourceNotFound) {
+			return s.errorResponse(request.ID, -32002, "Resource not found", map[string]interface{}{"uri": uri})
+		}
+		return s.errorResponse(request.ID, -32603, err.Error(), nil)
+	}
+	if contents == nil {
+		contents = []EmbeddedResource{}
+	}
+	return s.successResponse(request.ID, ReadResourceResult{Contents: contents})
+}
+
+func (s *Server) handleSubscribeResource(request JSONRPCRequest, subscribe bool) ([]byte, error) {
+	uri, ok := request.Params["uri"].(string)
+	if !ok || uri == "" {
+		return s.errorResponse(request.ID, -32602, "Invalid params: missing 'uri'", nil)
+	}
+
+	s.mu.Lock()
+	if subscribe {
+		s.subscriptions[uri] = struct{}{}
+	} else {
+		delete(s.subscriptions, uri)
+	}
+	s.mu.Unlock()
+
+	return s.successResponse(request.ID, map[string]interface{}{})
+}
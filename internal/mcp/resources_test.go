@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func rpc(t *testing.T, server *Server, method string, params map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	req, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	resp, err := server.HandleJSONRPC(nil, req)
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		t.Fatalf("%s: unmarshal: %v", method, err)
+	}
+	return out
+}
+
+func newResourceServer(t *testing.T) *Server {
+	t.Helper()
+	server := NewServer("docs", "1.0.0")
+	err := server.RegisterResource(&ResourceDefinition{
+		URI:      "docs://readme",
+		Name:     "README",
+		MimeType: "text/markdown",
+		Handler: func(ctx context.Context, uri string) ([]EmbeddedResource, error) {
+			return []EmbeddedResource{{Text: "# hello"}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterResourceTemplate(&ResourceTemplateDefinition{
+		URITemplate: "repo://{owner}/{+path}",
+		Name:        "repo file",
+		Handler: func(ctx context.Context, uri string, params map[string]string) ([]EmbeddedResource, error) {
+			if params["owner"] == "broken" {
+				return nil, errors.New("backend down")
+			}
+			return []EmbeddedResource{{Text: params["owner"] + ":" + params["path"], MimeType: "text/plain"}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func TestResourcesCapabilityAdvertised(t *testing.T) {
+	caps := rpc(t, NewServer("tools-only", "1.0.0"), "initialize", nil)["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	if _, ok := caps["resources"]; ok {
+		t.Errorf("tools-only server should not advertise resources: %v", caps)
+	}
+
+	caps = rpc(t, newResourceServer(t), "initialize", nil)["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	resources, ok := caps["resources"].(map[string]interface{})
+	if !ok || resources["subscribe"] != true || resources["listChanged"] != true {
+		t.Errorf("resources capability = %v", caps["resources"])
+	}
+}
+
+func TestResourcesListAndTemplatesList(t *testing.T) {
+	server := newResourceServer(t)
+
+	list := rpc(t, server, "resources/list", nil)["result"].(map[string]interface{})["resources"].([]interface{})
+	if len(list) != 1 {
+		t.Fatalf("resources = %v", list)
+	}
+	res := list[0].(map[string]interface{})
+	if res["uri"] != "docs://readme" || res["name"] != "README" || res["mimeType"] != "text/markdown" {
+		t.Errorf("resource = %v", res)
+	}
+
+	templates := rpc(t, server, "resources/templates/list", nil)["result"].(map[string]interface{})["resourceTemplates"].([]interface{})
+	if len(templates) != 1 || templates[0].(map[string]interface{})["uriTemplate"] != "repo://{owner}/{+path}" {
+		t.Errorf("templates = %v", templates)
+	}
+}
+
+func TestResourcesRead(t *testing.T) {
+	server := newResourceServer(t)
+
+	tests := []struct {
+		name     string
+		uri      string
+		wantText string
+		wantMime string
+		wantCode float64
+	}{
+		{"static", "docs://readme", "# hello", "text/markdown", 0},
+		{"template", "repo://acme/src/main.go", "acme:src/main.go", "text/plain", 0},
+		{"escaped", "repo://acme%20corp/docs/read%20me.md", "acme corp:docs/read me.md", "text/plain", 0},
+		{"invalid escape", "repo://acme%zz/x", "", "", -32002},
+		{"not found", "repo://acme", "", "", -32002},
+		{"handler error", "repo://broken/x", "", "", -32603},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := rpc(t, server, "resources/read", map[string]interface{}{"uri": tt.uri})
+			if tt.wantCode != 0 {
+				rpcErr, ok := resp["error"].(map[string]interface{})
+				if !ok || rpcErr["code"] != tt.wantCode {
+					t.Fatalf("response = %v, want error %v", resp, tt.wantCode)
+				}
+				return
+			}
+			contents := resp["result"].(map[string]interface{})["contents"].([]interface{})
+			if len(contents) != 1 {
+				t.Fatalf("contents = %v", contents)
+			}
+			c := contents[0].(map[string]interface{})
+			if c["uri"] != tt.uri || c["text"] != tt.wantText || c["mimeType"] != tt.wantMime {
+				t.Errorf("content = %v", c)
+			}
+		})
+	}
+
+	resp := rpc(t, server, "resources/read", nil)
+	if resp["error"].(map[string]interface{})["code"] != float64(-32602) {
+		t.Errorf("missing uri response = %v", resp)
+	}
+}
+
+func TestResourceSubscriptionNotifications(t *testing.T) {
+	server := newResourceServer(t)
+	var sent []string
+	server.SetNotificationSender(func(notification []byte) error {
+		sent = append(sent, string(notification))
+		return nil
+	})
+
+	if err := server.NotifyResourceUpdated("docs://readme"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("notification sent without subscription: %v", sent)
+	}
+
+	rpc(t, server, "resources/subscribe", map[string]interface{}{"uri": "docs://readme"})
+	if err := server.NotifyResourceUpdated("docs://readme"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || !strings.Contains(sent[0], `"method":"notifications/resources/updated"`) || !strings.Contains(sent[0], `"uri":"docs://readme"`) {
+		t.Fatalf("sent = %v", sent)
+	}
+
+	rpc(t, server, "resources/unsubscribe", map[string]interface{}{"uri": "docs://readme"})
+	_ = server.NotifyResourceUpdated("docs://readme")
+	if len(sent) != 1 {
+		t.Errorf("notification sent after unsubscribe: %v", sent)
+	}
+
+	_ = server.RegisterResource(&ResourceDefinition{
+		URI:     "docs://new",
+		Handler: func(context.Context, string) ([]EmbeddedResource, error) { return nil, nil },
+	})
+	if len(sent) != 2 || !strings.Contains(sent[1], "notifications/resources/list_changed") {
+		t.Errorf("sent = %v, want list_changed", sent)
+	}
+}
+
+func TestRegisterResourceTemplateValidation(t *testing.T) {
+	server := NewServer("docs", "1.0.0")
+	handler := func(context.Context, string, map[string]string) ([]EmbeddedResource, error) { return nil, nil }
+	for _, tmpl := range []string{"", "file:///{?query}", "x://{}"} {
+		if err := server.RegisterResourceTemplate(&ResourceTemplateDefinition{URITemplate: tmpl, Handler: handler}); err == nil {
+			t.Errorf("template %q: expected error", tmpl)
+		}
+	}
+	if err := server.RegisterResource(&ResourceDefinition{URI: "x://y"}); err == nil {
+		t.Error("expected error for resource without handler")
+	}
+}
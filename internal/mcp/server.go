@@ -2,12 +2,15 @@ package mcp
 
 import (
 	"context"
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // ToolHandler is a function that handles tool execution.
@@ -48,6 +51,15 @@ type ToolDefinition struct {
 	InputSchema interface{} // Can be map[string]Type or map[string]interface{} (JSON Schema)
 	Annotations map[string]interface{}
 	Handler     ToolHandler
+
+	// OutputSchema, when set, is advertised as the tool's outputSchema. Same
+	// accepted forms as InputSchema.
+	OutputSchema interface{}
+
+	// Timeout bounds a single call. Zero means no limit beyond the
+	// caller's context. The call is answered when the timeout elapses, but
+	// the handler runs until it notices ctx is done.
+	Timeout time.Duration
 }
 
 // Server represents an in-process MCP server.
@@ -55,7 +67,33 @@ type Server struct {
 	name    string
 	version string
 	tools   map[string]*ToolDefinition
-	mu      sync.RWMutex
+
+	resources         map[string]*ResourceDefinition
+	resourceTemplates []*ResourceTemplateDefinition
+	subscriptions     map[string]struct{}
+
+	prompts map[string]*PromptDefinition
+
+	// inflight holds cancel functions for running tools/call requests,
+	// keyed by session and JSON-RPC request id (see inflightKey), for
+	// notifications/cancelled.
+	inflight map[string]context.CancelCauseFunc
+
+	// middleware wraps every tool handler invocation, outermost first.
+	middleware []ToolMiddleware
+
+	// validateArgs enables InputSchema validation of tools/call arguments.
+	validateArgs bool
+
+	// logLevel is the minimum level for notifications/message, set by the
+	// client through logging/setLevel.
+	logLevel LoggingLevel
+
+	notify func(notification []byte) error
+	// subscribers receive server-wide notifications alongside notify.
+	subscribers    map[uint64]func(notification []byte) error
+	nextSubscriber uint64
+	mu             sync.RWMutex
 }
 
 // NewServer creates a new MCP server instance.
@@ -64,14 +102,18 @@ func NewServer(name, version string) *Server {
 		name:    name,
 		version: version,
 		tools:   make(map[string]*ToolDefinition),
+
+		resources:     make(map[string]*ResourceDefinition),
+		subscriptions: make(map[string]struct{}),
+		prompts:       make(map[string]*PromptDefinition),
+		inflight:      make(map[string]context.CancelCauseFunc),
+		logLevel:      LoggingLevelDebug,
 	}
 }
 
-// RegisterTool registers a tool with the server.
+// RegisterTool registers a tool with the server, replacing any tool of the
+// same name. Connected clients are sent notifications/tools/list_changed.
 func (s *Server) RegisterTool(tool *ToolDefinition) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if tool == nil {
 		return fmt.Errorf("tool definition cannot be nil")
 	}
@@ -82,10 +124,33 @@ func (s *Server) RegisterTool(tool *ToolDefinition) error {
 		return fmt.Errorf("tool handler cannot be nil")
 	}
 
+	s.mu.Lock()
 	s.tools[tool.Name] = tool
+	s.mu.Unlock()
+
+	s.notifyToolListChanged()
 	return nil
 }
 
+// UnregisterTool removes a tool and reports whether it was registered.
+// Calls already running are not affected. Connected clients are sent
+// notifications/tools/list_changed.
+func (s *Server) UnregisterTool(name string) bool {
+	s.mu.Lock()
+	_, ok := s.tools[name]
+	delete(s.tools, name)
+	s.mu.Unlock()
+
+	if ok {
+		s.notifyToolListChanged()
+	}
+	return ok
+}
+
+func (s *Server) notifyToolListChanged() {
+	_ = s.sendNotification("notifications/tools/list_changed", nil)
+}
+
 // ListTools returns all registered tools.
 func (s *Server) ListTools() []Tool {
 	s.mu.RLock()
@@ -94,18 +159,38 @@ func (s *Server) ListTools() []Tool {
 	result := make([]Tool, 0, len(s.tools))
 	for _, toolDef := range s.tools {
 		schema := s.buildJSONSchema(toolDef.InputSchema)
-		result = append(result, Tool{
+		tool := Tool{
 			Name:        toolDef.Name,
 			Description: toolDef.Description,
 			InputSchema: schema,
 			Annotations: toolDef.Annotations,
 			Meta:        buildToolMeta(toolDef.Annotations),
-		})
+		}
+		if toolDef.OutputSchema != nil {
+			tool.OutputSchema = s.buildJSONSchema(toolDef.OutputSchema)
+		}
+		result = append(result, tool)
 	}
 	return result
 }
 
+// ErrToolCancelled is returned by CallTool when the call's context is
+// cancelled before the handler returns.
+var ErrToolCancelled = errors.New("tool call cancelled")
+
+// ErrToolTimeout is returned by CallTool when the tool's Timeout elapses
+// before the handler returns.
+var ErrToolTimeout = errors.New("tool call timed out")
+
 // CallTool executes a tool by name with the given arguments.
+//
+// The handler runs under ctx, bounded by the tool's Timeout. If ctx ends or
+// the timeout elapses first, CallTool returns immediately and the handler's
+// eventual result is discarded. The handler is not waited for: it keeps
+// running until it returns, so handlers must stop promptly once ctx is
+// done. The error wraps ErrToolTimeout for the tool
+// timeout, ErrToolCancelled for notifications/cancelled, and is ctx.Err()
+// otherwise.
 func (s *Server) CallTool(ctx context.Context, name string, args map[string]interface{}) ([]Content, error) {
 	s.mu.RLock()
 	tool, ok := s.tools[name]
@@ -115,35 +200,100 @@ func (s *Server) CallTool(ctx context.Context, name string, args map[string]inte
 		return nil, fmt.Errorf("tool '%s' not found", name)
 	}
 
-	// Execute the tool handler
-	return tool.Handler(ctx, args)
+	if tool.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, tool.Timeout,
+			fmt.Errorf("%w: tool '%s' exceeded %s", ErrToolTimeout, name, tool.Timeout))
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, toolContextError(ctx)
+	}
+
+	type callResult struct {
+		content []Content
+		err     error
+	}
+	invoke := s.invoker(tool)
+	call := &ToolCall{Name: name, Arguments: args, Annotations: tool.Annotations}
+	done := make(chan callResult, 1)
+	go func() {
+		content, err := invoke(ctx, call)
+		done <- callResult{content: content, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.content, result.err
+	case <-ctx.Done():
+		return nil, toolContextError(ctx)
+	}
+}
+
+// toolContextError describes why ctx ended. Causes set by the server (tool
+// timeout, notifications/cancelled) are returned as is; otherwise the
+// caller's ctx.Err() is returned unchanged.
+func toolContextError(ctx context.Context) error {
+	if cause := context.Cause(ctx); errors.Is(cause, ErrToolTimeout) || errors.Is(cause, ErrToolCancelled) {
+		return cause
+	}
+	return ctx.Err()
 }
 
 // HandleJSONRPC handles a JSON-RPC request and returns a response.
-// The requestID parameter can be any JSON-RPC request ID type (typically a number or string).
-func (s *Server) HandleJSONRPC(requestID interface{}, requestData []byte) ([]byte, error) {
+//
+// ctx may be a context.Context, in which case it bounds the request: tool
+// handlers see its cancellation. Any other value (for example a request ID,
+// kept for interface compatibility) is ignored and context.Background is
+// used.
+func (s *Server) HandleJSONRPC(ctx interface{}, requestData []byte) ([]byte, error) {
 	var request JSONRPCRequest
 	if err := json.Unmarshal(requestData, &request); err != nil {
 		return s.errorResponse(nil, -32700, "Parse error", err)
 	}
 
-	// Use the parsed request ID, not the parameter
-	// (The parameter is for interface compatibility but not used in SDK servers)
+	reqCtx, ok := ctx.(context.Context)
+	if !ok || reqCtx == nil {
+		reqCtx = context.Background()
+	}
+
+	return s.dispatch(reqCtx, request)
+}
 
-	// Create context for the request if not provided
-	ctx := context.Background()
+func (s *Server) dispatch(ctx context.Context, request JSONRPCRequest) ([]byte, error) {
 
 	switch request.Method {
 	case "initialize":
 		return s.handleInitialize(request)
+	case "ping":
+		return s.successResponse(request.ID, map[string]interface{}{})
 	case "tools/list":
 		return s.handleListTools(request)
 	case "tools/call":
 		return s.handleCallTool(ctx, request)
+	case "resources/list":
+		return s.handleListResources(request)
+	case "resources/templates/list":
+		return s.handleListResourceTemplates(request)
+	case "resources/read":
+		return s.handleReadResource(ctx, request)
+	case "resources/subscribe":
+		return s.handleSubscribeResource(request, true)
+	case "resources/unsubscribe":
+		return s.handleSubscribeResource(request, false)
+	case "logging/setLevel":
+		return s.handleSetLogLevel(request)
+	case "prompts/list":
+		return s.handleListPrompts(request)
+	case "prompts/get":
+		return s.handleGetPrompt(ctx, request)
 	case "notifications/initialized":
 		// Match Python SDK bridge behavior: notifications/initialized
 		// returns a JSON-RPC success payload without an id field.
 		return s.notificationSuccessResponse(map[string]interface{}{})
+	case "notifications/cancelled":
+		s.handleCancelled(ctx, request)
+		return s.notificationSuccessResponse(map[string]interface{}{})
 	default:
 		return s.errorResponse(request.ID, -32601, fmt.Sprintf("Method '%s' not found", request.Method), nil)
 	}
@@ -155,11 +305,25 @@ func (s *Server) handleInitialize(request JSONRPCRequest) ([]byte, error) {
 		version = "1.0.0"
 	}
 
+	capabilities := map[string]interface{}{
+		"tools":   map[string]interface{}{"listChanged": true},
+		"logging": map[string]interface{}{},
+	}
+	if s.hasResources() {
+		capabilities["resources"] = map[string]interface{}{
+			"subscribe":   true,
+			"listChanged": true,
+		}
+	}
+	if s.hasPrompts() {
+		capabilities["prompts"] = map[string]interface{}{
+			"listChanged": true,
+		}
+	}
+
 	result := map[string]interface{}{
 		"protocolVersion": "2024-11-05",
-		"capabilities": map[string]interface{}{
-			"tools": map[string]interface{}{},
-		},
+		"capabilities":    capabilities,
 		"serverInfo": map[string]interface{}{
 			"name":    s.name,
 			"version": version,
@@ -187,8 +351,43 @@ func (s *Server) handleCallTool(ctx context.Context, request JSONRPCRequest) ([]
 		args = make(map[string]interface{})
 	}
 
+	if err := s.validateToolArguments(name, args); err != nil {
+		result := map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": err.Error(),
+				},
+			},
+			"is_error": true,
+		}
+		return s.successResponse(request.ID, result)
+	}
+
+	// Let the handler report progress and log records for this request.
+	ctx = withNotifier(ctx, s.requestNotifier(ctx, request, name))
+
+	// Track the call so notifications/cancelled can abort it.
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if request.ID != nil {
+		key := inflightKey(ctx, request.ID)
+		s.mu.Lock()
+		s.inflight[key] = cancel
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			delete(s.inflight, key)
+			s.mu.Unlock()
+		}()
+	}
+
 	// Call the tool
 	content, err := s.CallTool(ctx, name, args)
+	if err != nil && ctx.Err() != nil &&
+		(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		err = fmt.Errorf("%w: tool '%s': %v", ErrToolCancelled, name, err)
+	}
 	if err != nil {
 		// ToolErrorContent signals a tool-level error: emit a successful
 		// response carrying the user's content with is_error=true.
@@ -223,10 +422,70 @@ func (s *Server) handleCallTool(ctx context.Context, request JSONRPCRequest) ([]
 	result := map[string]interface{}{
 		"content": normalizeToolResultContent(content),
 	}
+	if structured := structuredContentOf(content); structured != nil {
+		result["structuredContent"] = structured
+	}
 
 	return s.successResponse(request.ID, result)
 }
 
+// SetArgumentValidation enables or disables validating tools/call arguments
+// against each tool's InputSchema before the handler runs. Invalid calls are
+// answered with an is_error result describing every problem. Disabled by
+// default.
+func (s *Server) SetArgumentValidation(enabled bool) {
+	s.mu.Lock()
+	s.validateArgs = enabled
+	s.mu.Unlock()
+}
+
+// validateToolArguments checks args against the named tool's InputSchema
+// when validation is enabled. Unknown tools are left to CallTool.
+func (s *Server) validateToolArguments(name string, args map[string]interface{}) error {
+	s.mu.RLock()
+	enabled := s.validateArgs
+	tool, ok := s.tools[name]
+	s.mu.RUnlock()
+	if !enabled || !ok {
+		return nil
+	}
+	if err := ValidateSchema(s.buildJSONSchema(tool.InputSchema), args); err != nil {
+		return fmt.Errorf("tool '%s': %w", name, err)
+	}
+	return nil
+}
+
+// inflightKey identifies a running request by the session ctx belongs to
+// and its JSON-RPC id, so clients reusing ids do not collide.
+func inflightKey(ctx context.Context, requestID interface{}) string {
+	return sessionFromContext(ctx) + "\x00" + fmt.Sprint(requestID)
+}
+
+// handleCancelled aborts the in-flight tools/call named by params.requestId
+// in ctx's session. Unknown or finished requests are ignored, as the MCP
+// spec requires.
+func (s *Server) handleCancelled(ctx context.Context, request JSONRPCRequest) {
+	requestID, ok := request.Params["requestId"]
+	if !ok || requestID == nil {
+		return
+	}
+	key := inflightKey(ctx, requestID)
+
+	s.mu.Lock()
+	cancel, ok := s.inflight[key]
+	delete(s.inflight, key)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	reason, _ := request.Params["reason"].(string)
+	if reason == "" {
+		reason = "cancelled by client"
+	}
+	cancel(fmt.Errorf("%w: %s", ErrToolCancelled, reason))
+}
+
 func buildToolMeta(annotations map[string]interface{}) map[string]interface{} {
 	if annotations == nil {
 		return nil
@@ -269,6 +528,9 @@ func normalizeToolResultContent(content []Content) []Content {
 				text = strings.Join(parts, "\n")
 			}
 			result = append(result, &TextContent{Type: ContentTypeText, Text: text})
+		case *StructuredContent:
+			// Carried in structuredContent, not in the content array.
+			continue
 		case *ResourceContent:
 			if value.Resource.Text == "" {
 				continue
@@ -281,6 +543,17 @@ func normalizeToolResultContent(content []Content) []Content {
 	return result
 }
 
+// structuredContentOf returns the value of the last StructuredContent item.
+func structuredContentOf(content []Content) interface{} {
+	var structured interface{}
+	for _, item := range content {
+		if value, ok := item.(*StructuredContent); ok {
+			structured = value.Value
+		}
+	}
+	return structured
+}
+
 func (s *Server) successResponse(id interface{}, result interface{}) ([]byte, error) {
 	response := JSONRPCResponse{
 		JSONRPC: "2.0",
@@ -423,11 +696,42 @@ func (s *Server) convertSimpleSchemaToJSON(schema map[string]interface{}) map[st
 	}
 }
 
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// implements reports whether t or *t implements iface.
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
 func schemaForType(t reflect.Type) map[string]interface{} {
+	return typeSchema(t, make(map[reflect.Type]bool))
+}
+
+// typeSchema derives the schema of t. visiting holds the struct types being
+// expanded; a type that refers back to one of them is described as a plain
+// object instead of being expanded forever.
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
 
+	// Types with custom JSON encodings follow encoding/json's precedence:
+	// a json.Marshaler may produce any value, a TextMarshaler a string.
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case implements(t, jsonMarshalerType):
+		return map[string]interface{}{}
+	case implements(t, textMarshalerType):
+		return map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	}
+
 	switch t.Kind() {
 	case reflect.String:
 		return map[string]interface{}{"type": "string"}
@@ -441,28 +745,51 @@ func schemaForType(t reflect.Type) map[string]interface{} {
 	case reflect.Slice, reflect.Array:
 		return map[string]interface{}{
 			"type":  "array",
-			"items": schemaForType(t.Elem()),
+			"items": typeSchema(t.Elem(), visiting),
 		}
-	case reflect.Map, reflect.Interface:
+	case reflect.Map:
 		return map[string]interface{}{"type": "object"}
+	case reflect.Interface:
+		return map[string]interface{}{}
 	case reflect.Struct:
+		if visiting[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
 		properties := make(map[string]interface{})
 		required := make([]string, 0, t.NumField())
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
-			if field.PkgPath != "" {
-				continue
-			}
-			name, optional, ok := schemaFieldName(field)
-			if !ok {
-				continue
-			}
-			fieldSchema := schemaForType(field.Type)
+		for _, field := range schemaFields(t) {
+			name, optional := field.name, field.optional
+			fieldSchema := typeSchema(field.Type, visiting)
 			if desc := field.Tag.Get("description"); desc != "" {
 				fieldSchema["description"] = desc
 			} else if desc := field.Tag.Get("jsonschema_description"); desc != "" {
 				fieldSchema["description"] = desc
 			}
+			if enum := field.Tag.Get("enum"); enum != "" {
+				fieldSchema["enum"] = enumValues(field.Type, enum)
+			}
+			for _, keyword := range []string{"minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems"} {
+				if raw := field.Tag.Get(keyword); raw != "" {
+					if n, err := strconv.ParseFloat(raw, 64); err == nil {
+						fieldSchema[keyword] = n
+					}
+				}
+			}
+			if pattern := field.Tag.Get("pattern"); pattern != "" {
+				fieldSchema["pattern"] = pattern
+			}
+			switch field.Tag.Get("required") {
+			case "true":
+				optional = false
+			case "false":
+				optional = true
+			}
+			if field.Type.Kind() == reflect.Pointer {
+				allowNull(fieldSchema)
+			}
 			properties[name] = fieldSchema
 			if !optional {
 				required = append(required, name)
@@ -482,6 +809,162 @@ func schemaForType(t reflect.Type) map[string]interface{} {
 	}
 }
 
+// allowNull widens schema to also accept JSON null, as a pointer field
+// decodes it to nil. Unconstrained schemas already accept it.
+func allowNull(schema map[string]interface{}) {
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []interface{}{typ, "null"}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		schema["enum"] = append(enum, nil)
+	}
+}
+
+// schemaField is a struct field as encoding/json sees it, possibly promoted
+// from an embedded struct.
+type schemaField struct {
+	reflect.StructField
+	name     string
+	optional bool
+	depth    int
+	tagged   bool
+}
+
+// schemaFields lists the JSON fields of struct type t the way encoding/json
+// does: embedded structs without a JSON name are flattened, and of several
+// fields with one name the shallowest wins, then a tagged one; any other
+// conflict hides the name.
+func schemaFields(t reflect.Type) []schemaField {
+	var all []schemaField
+	collectSchemaFields(t, 0, false, map[reflect.Type]bool{}, &all)
+
+	var names []string
+	byName := make(map[string][]schemaField)
+	for _, field := range all {
+		if _, ok := byName[field.name]; !ok {
+			names = append(names, field.name)
+		}
+		byName[field.name] = append(byName[field.name], field)
+	}
+
+	fields := make([]schemaField, 0, len(names))
+	for _, name := range names {
+		if field, ok := dominantField(byName[name]); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// collectSchemaFields appends the fields of t, descending into embedded
+// structs. Fields promoted through an embedded pointer are optional, since
+// the pointer may be nil.
+func collectSchemaFields(t reflect.Type, depth int, viaPointer bool, seen map[reflect.Type]bool, fields *[]schemaField) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, optional, ok := schemaFieldName(field)
+		if !ok {
+			continue
+		}
+		tagged := strings.Split(field.Tag.Get("json"), ",")[0] != ""
+		if field.Anonymous {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct && !tagged {
+				collectSchemaFields(embedded, depth+1, viaPointer || field.Type.Kind() == reflect.Pointer, seen, fields)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+		} else if !field.IsExported() {
+			continue
+		}
+		*fields = append(*fields, schemaField{
+			StructField: field,
+			name:        name,
+			optional:    optional || viaPointer,
+			depth:       depth,
+			tagged:      tagged,
+		})
+	}
+}
+
+// dominantField picks the field encoding/json uses among fields sharing a
+// name, if any.
+func dominantField(fields []schemaField) (schemaField, bool) {
+	depth := fields[0].depth
+	for _, field := range fields[1:] {
+		depth = min(depth, field.depth)
+	}
+	var shallowest, tagged []schemaField
+	for _, field := range fields {
+		if field.depth != depth {
+			continue
+		}
+		shallowest = append(shallowest, field)
+		if field.tagged {
+			tagged = append(tagged, field)
+		}
+	}
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	default:
+		return schemaField{}, false
+	}
+}
+
+// enumValues parses a comma-separated enum tag into values of the field's
+// JSON type. Values that do not parse are kept as strings.
+func enumValues(t reflect.Type, tag string) []interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	parts := strings.Split(tag, ",")
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		var value interface{} = part
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if n, err := strconv.ParseFloat(part, 64); err == nil {
+				value = n
+			}
+		case reflect.Bool:
+			if b, err := strconv.ParseBool(part); err == nil {
+				value = b
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// SchemaForType returns the JSON Schema derived from a Go type, as used for
+// struct-typed InputSchema values. Struct fields honor the json, description,
+// enum (comma-separated), required ("true"/"false"), minimum, maximum,
+// minLength, maxLength, minItems, maxItems and pattern tags. time.Time is a
+// date-time string, []byte a base64 string, an encoding.TextMarshaler a
+// string, and interfaces and json.Marshaler types are unconstrained.
+// Embedded structs are flattened as encoding/json does, pointer fields also
+// accept null, and a struct type nested within itself is a plain object.
+func SchemaForType(t reflect.Type) map[string]interface{} {
+	return schemaForType(t)
+}
+
 func schemaFieldName(field reflect.StructField) (string, bool, bool) {
 	tag := field.Tag.Get("json")
 	if tag == "-" {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -379,6 +380,110 @@ func TestCallTool_ContextCancellation(t *testing.T) {
 }
 
 // TestBuildJSONSchema tests JSON schema generation
+// level is a TextMarshaler encoded as its name.
+type level int
+
+func (l level) MarshalText() ([]byte, error) { return []byte(fmt.Sprintf("level-%d", l)), nil }
+
+func TestSchemaForSpecialTypes(t *testing.T) {
+	type input struct {
+		When  time.Time       `json:"when"`
+		Until *time.Time      `json:"until,omitempty"`
+		Data  []byte          `json:"data"`
+		Any   interface{}     `json:"any"`
+		Raw   json.RawMessage `json:"raw"`
+		Level level           `json:"level"`
+	}
+	schema := SchemaForType(reflect.TypeOf(input{}))
+	properties := schema["properties"].(map[string]interface{})
+	want := map[string]map[string]interface{}{
+		"when":  {"type": "string", "format": "date-time"},
+		"until": {"type": []interface{}{"string", "null"}, "format": "date-time"},
+		"data":  {"type": "string", "contentEncoding": "base64"},
+		"any":   {},
+		"raw":   {},
+		"level": {"type": "string"},
+	}
+	for name, expected := range want {
+		if !reflect.DeepEqual(properties[name], expected) {
+			t.Errorf("%s: schema = %v, want %v", name, properties[name], expected)
+		}
+	}
+
+	valid := input{When: time.Now(), Data: []byte{0xff, 0}, Any: "scalar", Raw: json.RawMessage(`[1,{"a":2}]`), Level: 3}
+	for _, anyValue := range []interface{}{"scalar", 1.5, true, nil, []int{1}} {
+		valid.Any = anyValue
+		data, _ := json.Marshal(valid)
+		var args map[string]interface{}
+		_ = json.Unmarshal(data, &args)
+		if err := ValidateSchema(schema, args); err != nil {
+			t.Errorf("%s: %v", data, err)
+		}
+	}
+}
+
+type schemaBase struct {
+	ID    string `json:"id"`
+	Shade string `json:"shade"`
+}
+
+type schemaExtra struct {
+	Note  string `json:"note"`
+	Shade string `json:"shade"`
+}
+
+type schemaNode struct {
+	Name     string        `json:"name"`
+	Children []*schemaNode `json:"children,omitempty"`
+	Parent   *schemaNode   `json:"parent"`
+}
+
+func TestSchemaForEmbeddedRecursiveAndPointerFields(t *testing.T) {
+	type input struct {
+		schemaBase
+		*schemaExtra
+		Named schemaBase `json:"named,omitempty"`
+		Label *string    `json:"label" enum:"a,b"`
+	}
+	schema := SchemaForType(reflect.TypeOf(input{}))
+	properties := schema["properties"].(map[string]interface{})
+	for _, name := range []string{"id", "note", "named", "label"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("missing property %q in %v", name, properties)
+		}
+	}
+	for _, name := range []string{"schemaBase", "schemaExtra", "shade"} {
+		if _, ok := properties[name]; ok {
+			t.Errorf("unexpected property %q in %v", name, properties)
+		}
+	}
+	if required := schema["required"]; !reflect.DeepEqual(required, []string{"id"}) {
+		t.Errorf("required = %v", required)
+	}
+
+	data, _ := json.Marshal(input{schemaBase: schemaBase{ID: "x"}})
+	var args map[string]interface{}
+	_ = json.Unmarshal(data, &args)
+	if err := ValidateSchema(schema, args); err != nil {
+		t.Errorf("%s: %v", data, err)
+	}
+	if err := ValidateSchema(schema, map[string]interface{}{"id": "x", "label": "c"}); err == nil {
+		t.Error("expected a label outside the enum to fail")
+	}
+
+	tree := SchemaForType(reflect.TypeOf(schemaNode{}))
+	data, _ = json.Marshal(schemaNode{Name: "root", Children: []*schemaNode{{Name: "leaf"}}})
+	args = nil
+	_ = json.Unmarshal(data, &args)
+	if err := ValidateSchema(tree, args); err != nil {
+		t.Errorf("%s: %v", data, err)
+	}
+	parent := tree["properties"].(map[string]interface{})["parent"]
+	if !reflect.DeepEqual(parent, map[string]interface{}{"type": []interface{}{"object", "null"}}) {
+		t.Errorf("parent = %v", parent)
+	}
+}
+
 func TestBuildJSONSchema(t *testing.T) {
 	server := NewServer("test", "1.0.0")
 
@@ -1065,3 +1170,45 @@ func TestConcurrentListAndCall(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestRegisterAndUnregisterToolNotifyListChanged(t *testing.T) {
+	server := NewServer("dynamic", "1.0.0")
+	var methods []string
+	server.SetNotificationSender(func(notification []byte) error {
+		var msg map[string]interface{}
+		if err := json.Unmarshal(notification, &msg); err != nil {
+			return err
+		}
+		methods = append(methods, msg["method"].(string))
+		return nil
+	})
+
+	handler := func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+		return []Content{&TextContent{Type: ContentTypeText, Text: "ok"}}, nil
+	}
+	if err := server.RegisterTool(&ToolDefinition{Name: "flagged", Handler: handler}); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.ListTools()) != 1 {
+		t.Fatalf("tools = %v", server.ListTools())
+	}
+	if !server.UnregisterTool("flagged") {
+		t.Error("UnregisterTool(flagged) = false")
+	}
+	if server.UnregisterTool("flagged") {
+		t.Error("second UnregisterTool(flagged) = true")
+	}
+	if len(server.ListTools()) != 0 {
+		t.Errorf("tools after unregister = %v", server.ListTools())
+	}
+
+	want := []string{"notifications/tools/list_changed", "notifications/tools/list_changed"}
+	if len(methods) != len(want) || methods[0] != want[0] || methods[1] != want[1] {
+		t.Errorf("notifications = %v, want %v", methods, want)
+	}
+
+	caps := rpc(t, server, "initialize", nil)["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	if tools := caps["tools"].(map[string]interface{}); tools["listChanged"] != true {
+		t.Errorf("tools capability = %v, want listChanged", tools)
+	}
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// maxStdioMessageSize bounds a single newline-delimited JSON-RPC message.
+const maxStdioMessageSize = 10 * 1024 * 1024
+
+// ServeStdio serves the server over newline-delimited JSON-RPC, reading
+// requests from r and writing responses and notifications to w, as the MCP
+// stdio transport specifies. Requests are handled concurrently so that
+// notifications/cancelled can reach a running tool.
+//
+// ServeStdio installs its own notification sender for the duration of the
+// call, so a server should be hosted by one transport at a time. It returns
+// nil when r reaches EOF, or ctx's error when ctx ends first.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var writeMu sync.Mutex
+	write := func(message []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if _, err := w.Write(append(message, '\n')); err != nil {
+			return fmt.Errorf("failed to write message: %w", err)
+		}
+		return nil
+	}
+
+	s.mu.Lock()
+	previous := s.notify
+	s.notify = write
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.notify = previous
+		s.mu.Unlock()
+	}()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxStdioMessageSize)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			select {
+			case lines <- append([]byte(nil), line...):
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			wg.Wait()
+			return err
+		case line := <-lines:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := s.HandleJSONRPC(ctx, line)
+				if err != nil || !expectsResponse(line) {
+					return
+				}
+				_ = write(resp)
+			}()
+		}
+	}
+}
+
+// expectsResponse reports whether a raw JSON-RPC message is a request that
+// must be answered. Notifications and responses are not; unparseable input
+// is, so the parse error reaches the client.
+func expectsResponse(message []byte) bool {
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return true
+	}
+	if envelope.Method == "" {
+		return false
+	}
+	return len(envelope.ID) > 0 && string(envelope.ID) != "null"
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newEchoServer(t *testing.T) *Server {
+	t.Helper()
+	server := NewServer("echo", "1.0.0")
+	err := server.RegisterTool(&ToolDefinition{
+		Name: "echo",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			_ = NotifierFromContext(ctx).Progress(1, 1, "echoing")
+			text, _ := args["text"].(string)
+			return []Content{&TextContent{Type: ContentTypeText, Text: text}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func TestServeStdio(t *testing.T) {
+	server := newEchoServer(t)
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"},"_meta":{"progressToken":7}}}`,
+		`not json`,
+	}, "\n") + "\n"
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ServeStdio(context.Background(), strings.NewReader(input), pw)
+		pw.Close()
+	}()
+
+	var messages []map[string]interface{}
+	scanner := bufio.NewScanner(pr)
+	for scanner.Scan() {
+		var msg map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("invalid output line %q: %v", scanner.Text(), err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("ServeStdio: %v", err)
+	}
+
+	// initialize response, progress notification, tools/call response and
+	// parse error; nothing for notifications/initialized.
+	if len(messages) != 4 {
+		t.Fatalf("got %d messages: %v", len(messages), messages)
+	}
+	byKind := map[string]map[string]interface{}{}
+	for _, msg := range messages {
+		switch {
+		case msg["method"] == "notifications/progress":
+			byKind["progress"] = msg
+		case msg["id"] == float64(1):
+			byKind["initialize"] = msg
+		case msg["id"] == float64(2):
+			byKind["call"] = msg
+		case msg["error"] != nil:
+			byKind["parse"] = msg
+		}
+	}
+	if len(byKind) != 4 {
+		t.Fatalf("unexpected messages: %v", messages)
+	}
+	if byKind["progress"]["params"].(map[string]interface{})["progressToken"] != float64(7) {
+		t.Errorf("progress = %v", byKind["progress"])
+	}
+	content := byKind["call"]["result"].(map[string]interface{})["content"].([]interface{})
+	if content[0].(map[string]interface{})["text"] != "hi" {
+		t.Errorf("call = %v", byKind["call"])
+	}
+}
+
+func TestServeStdioStopsOnContextCancel(t *testing.T) {
+	server := newEchoServer(t)
+	pr, _ := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.ServeStdio(ctx, pr, io.Discard) }()
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ServeStdio did not return after cancel")
+	}
+}
@@ -15,6 +15,10 @@ const (
 	ContentTypeAudio        ContentType = "audio"
 	ContentTypeResourceLink ContentType = "resource_link"
 	ContentTypeResource     ContentType = "resource"
+
+	// ContentTypeStructured marks StructuredContent; it never appears on the
+	// wire as a content block type.
+	ContentTypeStructured ContentType = "structured"
 )
 
 // Content represents content in MCP protocol.
@@ -89,13 +93,26 @@ func (r *ResourceContent) GetType() ContentType {
 	return ContentTypeResource
 }
 
+// StructuredContent carries a tool's structured result. It is emitted as
+// the result's structuredContent field rather than as a content block.
+type StructuredContent struct {
+	Value interface{}
+}
+
+// GetType returns the content type for StructuredContent.
+func (s *StructuredContent) GetType() ContentType {
+	return ContentTypeStructured
+}
+
 // Tool represents an MCP tool definition.
 type Tool struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	InputSchema map[string]interface{} `json:"inputSchema"`
-	Annotations map[string]interface{} `json:"annotations,omitempty"`
-	Meta        map[string]interface{} `json:"_meta,omitempty"`
+	// OutputSchema describes structuredContent, when the tool returns it.
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+	Annotations  map[string]interface{} `json:"annotations,omitempty"`
+	Meta         map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // CallToolResult represents the result of a tool call.
@@ -0,0 +1,418 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationError lists every way a value failed a JSON Schema.
+type ValidationError struct {
+	Problems []string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return "invalid arguments: " + strings.Join(e.Problems, "; ")
+}
+
+// ValidateSchema checks value against a JSON Schema. It supports the subset
+// SDK tools use: type (single or list), enum, const, required, properties,
+// additionalProperties, items, minItems/maxItems, uniqueItems,
+// minLength/maxLength, pattern, minimum/maximum,
+// exclusiveMinimum/exclusiveMaximum, multipleOf, anyOf, oneOf and allOf.
+// Unknown keywords are ignored; a combinator that is not a list of schemas
+// is reported. It returns a *ValidationError or nil.
+func ValidateSchema(schema map[string]interface{}, value interface{}) error {
+	v := &schemaValidator{}
+	v.validate(schema, value, "")
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+type schemaValidator struct {
+	problems []string
+}
+
+func (v *schemaValidator) addf(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "(root)"
+	}
+	v.problems = append(v.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *schemaValidator) validate(schema map[string]interface{}, value interface{}, path string) {
+	if schema == nil {
+		return
+	}
+
+	if !v.checkType(schema["type"], value, path) {
+		// Further keywords would only repeat the type mismatch.
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsJSONValue(enum, value) {
+		v.addf(path, "must be one of %s, got %s", formatJSONValues(enum), formatJSONValue(value))
+	}
+	if enum, ok := schema["enum"].([]string); ok {
+		values := make([]interface{}, len(enum))
+		for i, e := range enum {
+			values[i] = e
+		}
+		if !containsJSONValue(values, value) {
+			v.addf(path, "must be one of %s, got %s", formatJSONValues(values), formatJSONValue(value))
+		}
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, value) {
+		v.addf(path, "must be %s, got %s", formatJSONValue(c), formatJSONValue(value))
+	}
+
+	switch val := value.(type) {
+	case string:
+		v.validateString(schema, val, path)
+	case map[string]interface{}:
+		v.validateObject(schema, val, path)
+	case []interface{}:
+		v.validateArray(schema, val, path)
+	default:
+		if n, ok := toFloat(value); ok {
+			v.validateNumber(schema, n, path)
+		}
+	}
+
+	v.validateCombinators(schema, value, path)
+}
+
+func (v *schemaValidator) checkType(typeSpec interface{}, value interface{}, path string) bool {
+	var types []string
+	switch t := typeSpec.(type) {
+	case nil:
+		return true
+	case string:
+		types = []string{t}
+	case []string:
+		types = t
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+	default:
+		return true
+	}
+	for _, t := range types {
+		if matchesJSONType(t, value) {
+			return true
+		}
+	}
+	v.addf(path, "expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value))
+	return false
+}
+
+func (v *schemaValidator) validateString(schema map[string]interface{}, s, path string) {
+	length := utf8.RuneCountInString(s)
+	if min, ok := toFloat(schema["minLength"]); ok && float64(length) < min {
+		v.addf(path, "must be at least %v characters, got %d", min, length)
+	}
+	if max, ok := toFloat(schema["maxLength"]); ok && float64(length) > max {
+		v.addf(path, "must be at most %v characters, got %d", max, length)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			v.addf(path, "schema pattern %q is invalid: %v", pattern, err)
+		} else if !re.MatchString(s) {
+			v.addf(path, "must match pattern %q", pattern)
+		}
+	}
+}
+
+func (v *schemaValidator) validateNumber(schema map[string]interface{}, n float64, path string) {
+	if min, ok := toFloat(schema["minimum"]); ok && n < min {
+		v.addf(path, "must be >= %v, got %v", min, n)
+	}
+	if max, ok := toFloat(schema["maximum"]); ok && n > max {
+		v.addf(path, "must be <= %v, got %v", max, n)
+	}
+	if min, ok := toFloat(schema["exclusiveMinimum"]); ok && n <= min {
+		v.addf(path, "must be > %v, got %v", min, n)
+	}
+	if max, ok := toFloat(schema["exclusiveMaximum"]); ok && n >= max {
+		v.addf(path, "must be < %v, got %v", max, n)
+	}
+	if m, ok := toFloat(schema["multipleOf"]); ok && m > 0 {
+		if q := n / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.addf(path, "must be a multiple of %v, got %v", m, n)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema map[string]interface{}, obj map[string]interface{}, path string) {
+	for _, name := range requiredNames(schema["required"]) {
+		if _, ok := obj[name]; !ok {
+			v.addf(path, "missing required property %q", name)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		childPath := joinPath(path, name)
+		if propSchema, ok := properties[name].(map[string]interface{}); ok {
+			v.validate(propSchema, obj[name], childPath)
+			continue
+		}
+		if _, declared := properties[name]; declared {
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.addf(path, "unexpected property %q", name)
+			}
+		case map[string]interface{}:
+			v.validate(extra, obj[name], childPath)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(schema map[string]interface{}, arr []interface{}, path string) {
+	if min, ok := toFloat(schema["minItems"]); ok && float64(len(arr)) < min {
+		v.addf(path, "must have at least %v items, got %d", min, len(arr))
+	}
+	if max, ok := toFloat(schema["maxItems"]); ok && float64(len(arr)) > max {
+		v.addf(path, "must have at most %v items, got %d", max, len(arr))
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+	outer:
+		for i := range arr {
+			for j := 0; j < i; j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					v.addf(path, "items %d and %d are equal", j, i)
+					break outer
+				}
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range arr {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+func (v *schemaValidator) validateCombinators(schema map[string]interface{}, value interface{}, path string) {
+	if all, ok := v.subschemas(schema, "allOf", path); ok {
+		for _, s := range all {
+			v.validate(s, value, path)
+		}
+	}
+	if anyOf, ok := v.subschemas(schema, "anyOf", path); ok && countMatching(anyOf, value) == 0 {
+		v.addf(path, "does not match any allowed schema")
+	}
+	if oneOf, ok := v.subschemas(schema, "oneOf", path); ok {
+		if n := countMatching(oneOf, value); n != 1 {
+			v.addf(path, "must match exactly one schema, matched %d", n)
+		}
+	}
+}
+
+// subschemas returns the schemas listed under keyword, accepting both
+// decoded JSON ([]interface{}) and schemas built in Go
+// ([]map[string]interface{}). A keyword of any other shape is reported,
+// since ignoring it would accept values the schema means to reject.
+func (v *schemaValidator) subschemas(schema map[string]interface{}, keyword, path string) ([]map[string]interface{}, bool) {
+	switch list := schema[keyword].(type) {
+	case nil:
+		return nil, false
+	case []map[string]interface{}:
+		return list, true
+	case []interface{}:
+		schemas := make([]map[string]interface{}, 0, len(list))
+		for i, item := range list {
+			switch s := item.(type) {
+			case map[string]interface{}:
+				schemas = append(schemas, s)
+			case bool:
+				// Boolean schemas: true accepts every value, false none.
+				if s {
+					schemas = append(schemas, map[string]interface{}{})
+				} else {
+					schemas = append(schemas, map[string]interface{}{"enum": []interface{}{}})
+				}
+			default:
+				v.addf(path, "schema %s[%d] must be an object, got %T", keyword, i, item)
+				return nil, false
+			}
+		}
+		return schemas, true
+	default:
+		v.addf(path, "schema %s must be a list of schemas, got %T", keyword, list)
+		return nil, false
+	}
+}
+
+func countMatching(schemas []map[string]interface{}, value interface{}) int {
+	n := 0
+	for _, s := range schemas {
+		if ValidateSchema(s, value) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func requiredNames(required interface{}) []string {
+	switch r := required.(type) {
+	case []string:
+		return r
+	case []interface{}:
+		names := make([]string, 0, len(r))
+		for _, item := range r {
+			if s, ok := item.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func matchesJSONType(t string, value interface{}) bool {
+	switch t {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == math.Trunc(n) && !math.IsInf(n, 0)
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	default:
+		return true
+	}
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	if n, ok := toFloat(value); ok {
+		if n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func jsonEqual(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func containsJSONValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if jsonEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatJSONValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(value)
+}
+
+func formatJSONValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = formatJSONValue(value)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+var patternCache sync.Map // string -> *regexp.Regexp
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
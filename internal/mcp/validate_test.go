@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"name", "count"},
+		"properties": map[string]interface{}{
+			"name":  map[string]interface{}{"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+			"count": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 10},
+			"mode":  map[string]interface{}{"enum": []interface{}{"fast", "slow"}},
+			"tags": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"maxItems":    2,
+				"uniqueItems": true,
+			},
+			"owner": map[string]interface{}{
+				"type":                 "object",
+				"required":             []string{"id"},
+				"properties":           map[string]interface{}{"id": map[string]interface{}{"type": "number"}},
+				"additionalProperties": false,
+			},
+			"nullable": map[string]interface{}{"type": []interface{}{"string", "null"}},
+		},
+	}
+
+	valid := map[string]interface{}{
+		"name":     "abc",
+		"count":    float64(3),
+		"mode":     "fast",
+		"tags":     []interface{}{"a", "b"},
+		"owner":    map[string]interface{}{"id": 1.5},
+		"nullable": nil,
+	}
+	if err := ValidateSchema(schema, valid); err != nil {
+		t.Fatalf("valid value rejected: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value map[string]interface{}
+		want  []string
+	}{
+		{"missing required", map[string]interface{}{"name": "abc"}, []string{`(root): missing required property "count"`}},
+		{"wrong type", map[string]interface{}{"name": 5, "count": 1.5}, []string{"name: expected string, got integer", "count: expected integer, got number"}},
+		{"string constraints", map[string]interface{}{"name": "A", "count": 1}, []string{"name: must be at least 2 characters", `name: must match pattern "^[a-z]+$"`}},
+		{"range", map[string]interface{}{"name": "ab", "count": 11}, []string{"count: must be <= 10, got 11"}},
+		{"enum", map[string]interface{}{"name": "ab", "count": 1, "mode": "medium"}, []string{`mode: must be one of ["fast", "slow"], got "medium"`}},
+		{"array", map[string]interface{}{"name": "ab", "count": 1, "tags": []interface{}{"a", "a", 3}}, []string{"tags: must have at most 2 items", "tags: items 0 and 1 are equal", "tags[2]: expected string, got integer"}},
+		{"nested object", map[string]interface{}{"name": "ab", "count": 1, "owner": map[string]interface{}{"extra": true}}, []string{`owner: missing required property "id"`, `owner: unexpected property "extra"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSchema(schema, tt.value)
+			if err == nil {
+				t.Fatal("expected validation error")
+			}
+			verr, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("error type = %T", err)
+			}
+			if len(verr.Problems) != len(tt.want) {
+				t.Errorf("problems = %q, want %d", verr.Problems, len(tt.want))
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q missing %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSchemaCombinators(t *testing.T) {
+	schema := map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "number", "multipleOf": 5},
+		},
+	}
+	for _, ok := range []interface{}{"x", float64(10)} {
+		if err := ValidateSchema(schema, ok); err != nil {
+			t.Errorf("%v rejected: %v", ok, err)
+		}
+	}
+	if err := ValidateSchema(schema, float64(7)); err == nil {
+		t.Error("7 should match no schema")
+	}
+}
+
+func TestValidateSchemaCombinatorShapes(t *testing.T) {
+	built := map[string]interface{}{
+		"anyOf": []map[string]interface{}{
+			{"type": "string"},
+			{"type": "integer"},
+		},
+		"allOf": []map[string]interface{}{
+			{"not_a_keyword": true},
+			{"minimum": 0},
+		},
+		"oneOf": []interface{}{true, false},
+	}
+	for _, ok := range []interface{}{"x", float64(3)} {
+		if err := ValidateSchema(built, ok); err != nil {
+			t.Errorf("%v rejected: %v", ok, err)
+		}
+	}
+	for _, bad := range []interface{}{true, float64(-1), 1.5} {
+		if err := ValidateSchema(built, bad); err == nil {
+			t.Errorf("%v should be rejected", bad)
+		}
+	}
+
+	for name, schema := range map[string]map[string]interface{}{
+		"map":  {"anyOf": map[string]interface{}{"type": "string"}},
+		"item": {"oneOf": []interface{}{"string"}},
+	} {
+		err := ValidateSchema(schema, "x")
+		if err == nil || !strings.Contains(err.Error(), "schema") {
+			t.Errorf("%s: expected the unsupported shape to be reported, got %v", name, err)
+		}
+	}
+}
+
+func TestServerArgumentValidation(t *testing.T) {
+	server := NewServer("validated", "1.0.0")
+	called := 0
+	err := server.RegisterTool(&ToolDefinition{
+		Name: "greet",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+			"required":   []interface{}{"name"},
+		},
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]Content, error) {
+			called++
+			return []Content{&TextContent{Type: ContentTypeText, Text: "hi"}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Disabled by default: the handler sees the bad arguments.
+	rpc(t, server, "tools/call", map[string]interface{}{"name": "greet", "arguments": map[string]interface{}{"name": 3}})
+	if called != 1 {
+		t.Fatalf("called = %d, want 1", called)
+	}
+
+	server.SetArgumentValidation(true)
+	result := rpc(t, server, "tools/call", map[string]interface{}{"name": "greet", "arguments": map[string]interface{}{"name": 3}})["result"].(map[string]interface{})
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	if result["is_error"] != true || !strings.Contains(text, "tool 'greet'") || !strings.Contains(text, "name: expected string, got integer") {
+		t.Errorf("result = %v", result)
+	}
+	if called != 1 {
+		t.Errorf("handler ran for invalid arguments")
+	}
+
+	rpc(t, server, "tools/call", map[string]interface{}{"name": "greet", "arguments": map[string]interface{}{"name": "Ada"}})
+	if called != 2 {
+		t.Errorf("handler did not run for valid arguments")
+	}
+}
@@ -0,0 +1,522 @@
+// Package mcpclient provides an MCP client that connects to stdio, SSE,
+// Streamable HTTP and in-process MCP servers.
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+)
+
+// ProtocolVersion is the MCP protocol version requested during initialize.
+const ProtocolVersion = "2025-06-18"
+
+// ErrClosed is returned by requests made on, or pending when, the client or
+// its transport closes.
+var ErrClosed = errors.New("mcp client closed")
+
+// Transport carries JSON-RPC messages between a Client and one server.
+type Transport interface {
+	// Start connects to the server. receive is called with every message
+	// the server sends, and closed once when the connection ends.
+	Start(ctx context.Context, receive func(message []byte), closed func(err error)) error
+	// Send delivers one JSON-RPC message to the server.
+	Send(ctx context.Context, message []byte) error
+	// Close disconnects from the server.
+	Close() error
+}
+
+// RPCError is a JSON-RPC error returned by the server.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message)
+}
+
+// Implementation identifies a client or server.
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// InitializeResult is the server's answer to initialize.
+type InitializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      Implementation         `json:"serverInfo"`
+	Instructions    string                 `json:"instructions,omitempty"`
+}
+
+// CallToolResult is the decoded result of tools/call.
+type CallToolResult struct {
+	Content           []mcp.Content
+	StructuredContent interface{}
+	IsError           bool
+}
+
+// Progress is a notifications/progress update for an in-flight request.
+type Progress struct {
+	Progress float64
+	Total    float64
+	Message  string
+}
+
+// NotificationHandler receives server notifications other than progress
+// updates for requests made with WithProgress.
+type NotificationHandler func(method string, params json.RawMessage)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithClientInfo sets the clientInfo sent during initialize.
+func WithClientInfo(name, version string) Option {
+	return func(c *Client) {
+		c.clientInfo = Implementation{Name: name, Version: version}
+	}
+}
+
+// WithNotificationHandler sets the handler for server notifications such as
+// notifications/message and notifications/tools/list_changed.
+func WithNotificationHandler(handler NotificationHandler) Option {
+	return func(c *Client) {
+		c.onNotification = handler
+	}
+}
+
+type progressContextKey struct{}
+
+// WithProgress returns a context whose requests ask the server for progress
+// updates and deliver them to fn.
+func WithProgress(ctx context.Context, fn func(Progress)) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, fn)
+}
+
+// Client is a connected MCP client. Its methods are safe for concurrent use.
+type Client struct {
+	transport      Transport
+	clientInfo     Implementation
+	onNotification NotificationHandler
+	server         InitializeResult
+
+	nextID atomic.Int64
+
+	mu       sync.Mutex
+	pending  map[int64]chan *response
+	progress map[string]func(Progress)
+	closed   bool
+	closeErr error
+	done     chan struct{}
+}
+
+type response struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// Connect starts transport and performs the initialize handshake.
+func Connect(ctx context.Context, transport Transport, opts ...Option) (*Client, error) {
+	c := &Client{
+		transport:  transport,
+		clientInfo: Implementation{Name: "claude-agent-sdk-go", Version: "1.0.0"},
+		pending:    make(map[int64]chan *response),
+		progress:   make(map[string]func(Progress)),
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := transport.Start(ctx, c.receive, c.shutdown); err != nil {
+		return nil, fmt.Errorf("failed to start MCP transport: %w", err)
+	}
+
+	params := map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      c.clientInfo,
+	}
+	if err := c.call(ctx, "initialize", params, &c.server); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("MCP initialize failed: %w", err)
+	}
+	if err := c.notify(ctx, "notifications/initialized", nil); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("MCP initialize failed: %w", err)
+	}
+	return c, nil
+}
+
+// ServerInfo returns the server's initialize result.
+func (c *Client) ServerInfo() InitializeResult {
+	return c.server
+}
+
+// Close disconnects from the server. Pending requests fail with ErrClosed.
+func (c *Client) Close() error {
+	c.shutdown(nil)
+	return c.transport.Close()
+}
+
+// Done is closed when the connection ends.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Ping checks that the server is responsive.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.call(ctx, "ping", nil, nil)
+}
+
+// ListTools returns every tool the server offers, following pagination.
+func (c *Client) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	var tools []mcp.Tool
+	err := c.paginate(ctx, "tools/list", func(raw json.RawMessage) error {
+		var page struct {
+			Tools []mcp.Tool `json:"tools"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		tools = append(tools, page.Tools...)
+		return nil
+	})
+	return tools, err
+}
+
+// CallTool invokes a tool. A tool-level failure is reported through
+// CallToolResult.IsError, not as an error.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (*CallToolResult, error) {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	var raw struct {
+		Content           json.RawMessage `json:"content"`
+		StructuredContent interface{}     `json:"structuredContent"`
+		IsError           bool            `json:"isError"`
+		// SDK servers answer with the CLI bridge's spelling.
+		IsErrorSnake bool `json:"is_error"`
+	}
+	if err := c.call(ctx, "tools/call", map[string]interface{}{"name": name, "arguments": args}, &raw); err != nil {
+		return nil, err
+	}
+
+	result := &CallToolResult{StructuredContent: raw.StructuredContent, IsError: raw.IsError || raw.IsErrorSnake}
+	if len(raw.Content) > 0 && string(raw.Content) != "null" {
+		content, err := mcp.UnmarshalContent(raw.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tool content: %w", err)
+		}
+		result.Content = content
+	}
+	return result, nil
+}
+
+// ListResources returns every static resource the server offers.
+func (c *Client) ListResources(ctx context.Context) ([]mcp.Resource, error) {
+	var resources []mcp.Resource
+	err := c.paginate(ctx, "resources/list", func(raw json.RawMessage) error {
+		var page mcp.ListResourcesResult
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		resources = append(resources, page.Resources...)
+		return nil
+	})
+	return resources, err
+}
+
+// ListResourceTemplates returns every resource template the server offers.
+func (c *Client) ListResourceTemplates(ctx context.Context) ([]mcp.ResourceTemplate, error) {
+	var templates []mcp.ResourceTemplate
+	err := c.paginate(ctx, "resources/templates/list", func(raw json.RawMessage) error {
+		var page mcp.ListResourceTemplatesResult
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		templates = append(templates, page.ResourceTemplates...)
+		return nil
+	})
+	return templates, err
+}
+
+// ReadResource reads the resource identified by uri.
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]mcp.EmbeddedResource, error) {
+	var result mcp.ReadResourceResult
+	if err := c.call(ctx, "resources/read", map[string]interface{}{"uri": uri}, &result); err != nil {
+		return nil, err
+	}
+	return result.Contents, nil
+}
+
+// SubscribeResource asks the server to send notifications/resources/updated
+// for uri.
+func (c *Client) SubscribeResource(ctx context.Context, uri string) error {
+	return c.call(ctx, "resources/subscribe", map[string]interface{}{"uri": uri}, nil)
+}
+
+// UnsubscribeResource cancels a SubscribeResource.
+func (c *Client) UnsubscribeResource(ctx context.Context, uri string) error {
+	return c.call(ctx, "resources/unsubscribe", map[string]interface{}{"uri": uri}, nil)
+}
+
+// ListPrompts returns every prompt the server offers.
+func (c *Client) ListPrompts(ctx context.Context) ([]mcp.Prompt, error) {
+	var prompts []mcp.Prompt
+	err := c.paginate(ctx, "prompts/list", func(raw json.RawMessage) error {
+		var page mcp.ListPromptsResult
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		prompts = append(prompts, page.Prompts...)
+		return nil
+	})
+	return prompts, err
+}
+
+// GetPrompt renders a prompt with the given arguments.
+func (c *Client) GetPrompt(ctx context.Context, name string, args map[string]string) (*mcp.GetPromptResult, error) {
+	params := map[string]interface{}{"name": name}
+	if len(args) > 0 {
+		params["arguments"] = args
+	}
+	var raw struct {
+		Description string `json:"description"`
+		Messages    []struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	if err := c.call(ctx, "prompts/get", params, &raw); err != nil {
+		return nil, err
+	}
+
+	result := &mcp.GetPromptResult{Description: raw.Description, Messages: make([]mcp.PromptMessage, 0, len(raw.Messages))}
+	for _, msg := range raw.Messages {
+		content, err := mcp.UnmarshalContent(append(append([]byte("["), msg.Content...), ']'))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode prompt message: %w", err)
+		}
+		var item mcp.Content
+		if len(content) > 0 {
+			item = content[0]
+		}
+		result.Messages = append(result.Messages, mcp.PromptMessage{Role: msg.Role, Content: item})
+	}
+	return result, nil
+}
+
+// SetLogLevel sets the minimum level of notifications/message records the
+// server sends.
+func (c *Client) SetLogLevel(ctx context.Context, level mcp.LoggingLevel) error {
+	return c.call(ctx, "logging/setLevel", map[string]interface{}{"level": level}, nil)
+}
+
+func (c *Client) paginate(ctx context.Context, method string, page func(json.RawMessage) error) error {
+	var cursor string
+	for {
+		var params map[string]interface{}
+		if cursor != "" {
+			params = map[string]interface{}{"cursor": cursor}
+		}
+		var raw json.RawMessage
+		if err := c.call(ctx, method, params, &raw); err != nil {
+			return err
+		}
+		if err := page(raw); err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+		var next struct {
+			NextCursor string `json:"nextCursor"`
+		}
+		_ = json.Unmarshal(raw, &next)
+		if next.NextCursor == "" {
+			return nil
+		}
+		cursor = next.NextCursor
+	}
+}
+
+// call sends a request and decodes its result into out (if non-nil). When
+// ctx ends first the server is sent notifications/cancelled.
+func (c *Client) call(ctx context.Context, method string, params map[string]interface{}, out interface{}) error {
+	id := c.nextID.Add(1)
+	ch := make(chan *response, 1)
+
+	c.mu.Lock()
+	if c.closed {
+		err := c.closeErr
+		c.mu.Unlock()
+		return err
+	}
+	c.pending[id] = ch
+	progressToken := ""
+	if fn, ok := ctx.Value(progressContextKey{}).(func(Progress)); ok && fn != nil {
+		progressToken = "p" + strconv.FormatInt(id, 10)
+		c.progress[progressToken] = fn
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		if progressToken != "" {
+			delete(c.progress, progressToken)
+		}
+		c.mu.Unlock()
+	}()
+
+	if progressToken != "" {
+		withMeta := make(map[string]interface{}, len(params)+1)
+		for k, v := range params {
+			withMeta[k] = v
+		}
+		withMeta["_meta"] = map[string]interface{}{"progressToken": progressToken}
+		params = withMeta
+	}
+
+	request := map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method}
+	if params != nil {
+		request["params"] = params
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+	if err := c.transport.Send(ctx, data); err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if out == nil {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, out); err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		reason := context.Cause(ctx).Error()
+		_ = c.notify(context.Background(), "notifications/cancelled", map[string]interface{}{"requestId": id, "reason": reason})
+		return ctx.Err()
+	case <-c.done:
+		c.mu.Lock()
+		err := c.closeErr
+		c.mu.Unlock()
+		return err
+	}
+}
+
+func (c *Client) notify(ctx context.Context, method string, params map[string]interface{}) error {
+	notification := map[string]interface{}{"jsonrpc": "2.0", "method": method}
+	if params != nil {
+		notification["params"] = params
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s notification: %w", method, err)
+	}
+	return c.transport.Send(ctx, data)
+}
+
+// receive routes one message from the server.
+func (c *Client) receive(message []byte) {
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+		response
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return
+	}
+	hasID := len(envelope.ID) > 0 && string(envelope.ID) != "null"
+
+	switch {
+	case envelope.Method != "" && hasID:
+		go c.answerServerRequest(envelope.ID, envelope.Method)
+	case envelope.Method == "notifications/progress":
+		c.handleProgress(envelope.Params)
+	case envelope.Method != "":
+		if c.onNotification != nil {
+			c.onNotification(envelope.Method, envelope.Params)
+		}
+	case hasID:
+		id, err := strconv.ParseInt(string(envelope.ID), 10, 64)
+		if err != nil {
+			return
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[id]
+		c.mu.Unlock()
+		if ok {
+			resp := envelope.response
+			ch <- &resp
+		}
+	}
+}
+
+func (c *Client) handleProgress(raw json.RawMessage) {
+	var params struct {
+		ProgressToken interface{} `json:"progressToken"`
+		Progress      float64     `json:"progress"`
+		Total         float64     `json:"total"`
+		Message       string      `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return
+	}
+	c.mu.Lock()
+	fn := c.progress[fmt.Sprint(params.ProgressToken)]
+	c.mu.Unlock()
+	if fn != nil {
+		fn(Progress{Progress: params.Progress, Total: params.Total, Message: params.Message})
+	} else if c.onNotification != nil {
+		c.onNotification("notifications/progress", raw)
+	}
+}
+
+// answerServerRequest replies to requests the server sends to the client.
+// Only ping is supported; the client declares no other capabilities.
+func (c *Client) answerServerRequest(id json.RawMessage, method string) {
+	reply := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if method == "ping" {
+		reply["result"] = map[string]interface{}{}
+	} else {
+		reply["error"] = map[string]interface{}{"code": -32601, "message": fmt.Sprintf("Method '%s' not found", method)}
+	}
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return
+	}
+	_ = c.transport.Send(context.Background(), data)
+}
+
+// shutdown marks the client closed and releases pending requests.
+func (c *Client) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	if err != nil {
+		c.closeErr = fmt.Errorf("%w: %v", ErrClosed, err)
+	} else {
+		c.closeErr = ErrClosed
+	}
+	close(c.done)
+}
@@ -0,0 +1,249 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// TestMain doubles as a stdio MCP server when re-executed by
+// TestStdioTransport.
+func TestMain(m *testing.M) {
+	if os.Getenv("MCPCLIENT_TEST_SERVER") == "1" {
+		if err := newTestServer().ServeStdio(context.Background(), os.Stdin, os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func newTestServer() *mcp.Server {
+	server := mcp.NewServer("test", "2.0.0")
+	_ = server.RegisterTool(&mcp.ToolDefinition{
+		Name:        "echo",
+		Description: "Echo text",
+		InputSchema: map[string]interface{}{"text": "string"},
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]mcp.Content, error) {
+			_ = mcp.NotifierFromContext(ctx).Progress(1, 2, "halfway")
+			text, _ := args["text"].(string)
+			return []mcp.Content{&mcp.TextContent{Type: mcp.ContentTypeText, Text: text}}, nil
+		},
+	})
+	_ = server.RegisterTool(&mcp.ToolDefinition{
+		Name: "block",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]mcp.Content, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	_ = server.RegisterResource(&mcp.ResourceDefinition{
+		URI:      "file:///readme",
+		Name:     "readme",
+		MimeType: "text/plain",
+		Handler: func(ctx context.Context, uri string) ([]mcp.EmbeddedResource, error) {
+			return []mcp.EmbeddedResource{{Text: "hello"}}, nil
+		},
+	})
+	_ = server.RegisterPrompt(&mcp.PromptDefinition{
+		Name:      "greet",
+		Arguments: []mcp.PromptArgument{{Name: "name", Required: true}},
+		Handler: func(ctx context.Context, args map[string]string) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{Messages: []mcp.PromptMessage{{
+				Role:    "user",
+				Content: &mcp.TextContent{Type: mcp.ContentTypeText, Text: "Hi " + args["name"]},
+			}}}, nil
+		},
+	})
+	return server
+}
+
+// exercise runs the same checks against any transport.
+func exercise(t *testing.T, transport Transport) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := Connect(ctx, transport, WithClientInfo("tester", "0.1"))
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if info := client.ServerInfo(); info.ServerInfo.Name != "test" || info.Capabilities["prompts"] == nil {
+		t.Errorf("ServerInfo = %+v", info)
+	}
+	if err := client.Ping(ctx); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil || len(tools) != 2 {
+		t.Fatalf("ListTools = %v, %v", tools, err)
+	}
+
+	var mu sync.Mutex
+	var updates []Progress
+	progressCtx := WithProgress(ctx, func(p Progress) {
+		mu.Lock()
+		updates = append(updates, p)
+		mu.Unlock()
+	})
+	result, err := client.CallTool(progressCtx, "echo", map[string]interface{}{"text": "ping"})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if text, ok := result.Content[0].(*mcp.TextContent); !ok || text.Text != "ping" || result.IsError {
+		t.Errorf("CallTool = %+v", result)
+	}
+	mu.Lock()
+	if len(updates) != 1 || updates[0].Message != "halfway" || updates[0].Total != 2 {
+		t.Errorf("progress = %+v", updates)
+	}
+	mu.Unlock()
+
+	result, err = client.CallTool(ctx, "missing", nil)
+	if err != nil || !result.IsError {
+		t.Errorf("CallTool(missing) = %+v, %v", result, err)
+	}
+
+	resources, err := client.ListResources(ctx)
+	if err != nil || len(resources) != 1 || resources[0].URI != "file:///readme" {
+		t.Errorf("ListResources = %v, %v", resources, err)
+	}
+	contents, err := client.ReadResource(ctx, "file:///readme")
+	if err != nil || len(contents) != 1 || contents[0].Text != "hello" || contents[0].MimeType != "text/plain" {
+		t.Errorf("ReadResource = %v, %v", contents, err)
+	}
+	_, err = client.ReadResource(ctx, "file:///nope")
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32002 {
+		t.Errorf("ReadResource(missing) err = %v", err)
+	}
+
+	prompts, err := client.ListPrompts(ctx)
+	if err != nil || len(prompts) != 1 || prompts[0].Name != "greet" {
+		t.Errorf("ListPrompts = %v, %v", prompts, err)
+	}
+	prompt, err := client.GetPrompt(ctx, "greet", map[string]string{"name": "Ada"})
+	if err != nil {
+		t.Fatalf("GetPrompt: %v", err)
+	}
+	if text, ok := prompt.Messages[0].Content.(*mcp.TextContent); !ok || text.Text != "Hi Ada" {
+		t.Errorf("GetPrompt = %+v", prompt.Messages)
+	}
+}
+
+func TestInProcessTransport(t *testing.T) {
+	exercise(t, NewInProcessTransport(newTestServer()))
+}
+
+func TestStreamableHTTPTransport(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewHTTPHandler(newTestServer(), mcp.HTTPOptions{}))
+	defer ts.Close()
+	exercise(t, NewStreamableHTTPTransport(ts.URL+"/mcp", nil))
+}
+
+func TestSSETransport(t *testing.T) {
+	ts := httptest.NewServer(mcp.NewHTTPHandler(newTestServer(), mcp.HTTPOptions{}))
+	defer ts.Close()
+	exercise(t, NewSSETransport(ts.URL+"/mcp/sse", nil))
+}
+
+func TestStdioTransport(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip("no test executable")
+	}
+	transport := NewStdioTransport(exe, []string{"-test.run=^$"}, map[string]string{"MCPCLIENT_TEST_SERVER": "1"})
+	exercise(t, transport)
+}
+
+func TestCancelledCallNotifiesServer(t *testing.T) {
+	client, err := Connect(context.Background(), NewInProcessTransport(newTestServer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.CallTool(ctx, "block", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	// The blocked handler was released by notifications/cancelled; Close
+	// would hang waiting for it otherwise.
+}
+
+func TestNotificationHandlerAndClose(t *testing.T) {
+	server := newTestServer()
+	got := make(chan string, 4)
+	client, err := Connect(context.Background(), NewInProcessTransport(server),
+		WithNotificationHandler(func(method string, params json.RawMessage) {
+			got <- method + " " + string(params)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := client.SubscribeResource(ctx, "file:///readme"); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.NotifyResourceUpdated("file:///readme"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-got:
+		if !strings.HasPrefix(msg, "notifications/resources/updated") || !strings.Contains(msg, "file:///readme") {
+			t.Errorf("notification = %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no notification")
+	}
+
+	client.Close()
+	select {
+	case <-client.Done():
+	default:
+		t.Error("Done not closed after Close")
+	}
+	if _, err := client.ListTools(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("after Close err = %v, want ErrClosed", err)
+	}
+}
+
+func TestTransportForConfig(t *testing.T) {
+	cases := []struct {
+		config shared.McpServerConfig
+		want   string
+	}{
+		{&shared.McpStdioServerConfig{Command: "node"}, "*mcpclient.StdioTransport"},
+		{&shared.McpSSEServerConfig{URL: "http://x/sse"}, "*mcpclient.SSETransport"},
+		{&shared.McpHTTPServerConfig{URL: "http://x/mcp"}, "*mcpclient.StreamableHTTPTransport"},
+		{&shared.McpSdkServerConfig{Name: "s", Instance: mcp.NewServer("s", "1")}, "*mcpclient.InProcessTransport"},
+	}
+	for _, tc := range cases {
+		transport, err := TransportForConfig(tc.config)
+		if err != nil {
+			t.Errorf("%T: %v", tc.config, err)
+			continue
+		}
+		if got := fmt.Sprintf("%T", transport); got != tc.want {
+			t.Errorf("%T -> %s, want %s", tc.config, got, tc.want)
+		}
+	}
+	if _, err := TransportForConfig(&shared.McpSdkServerConfig{Name: "bare"}); err == nil {
+		t.Error("expected error for sdk config without instance")
+	}
+}
@@ -0,0 +1,41 @@
+package mcpclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// TransportForConfig returns a transport for an MCP server configured for
+// the CLI in Options.McpServers.
+func TransportForConfig(config shared.McpServerConfig) (Transport, error) {
+	switch c := config.(type) {
+	case *shared.McpStdioServerConfig:
+		return NewStdioTransport(c.Command, c.Args, c.Env), nil
+	case *shared.McpSSEServerConfig:
+		return NewSSETransport(c.URL, c.Headers), nil
+	case *shared.McpHTTPServerConfig:
+		return NewStreamableHTTPTransport(c.URL, c.Headers), nil
+	case *shared.McpSdkServerConfig:
+		server, ok := c.Instance.(*mcp.Server)
+		if !ok {
+			return nil, fmt.Errorf("sdk mcp server %q has no in-process instance", c.Name)
+		}
+		return NewInProcessTransport(server), nil
+	case nil:
+		return nil, fmt.Errorf("mcp server config cannot be nil")
+	default:
+		return nil, fmt.Errorf("unsupported mcp server config type %T", config)
+	}
+}
+
+// ConnectConfig connects to an MCP server configured for the CLI.
+func ConnectConfig(ctx context.Context, config shared.McpServerConfig, opts ...Option) (*Client, error) {
+	transport, err := TransportForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return Connect(ctx, transport, opts...)
+}
@@ -0,0 +1,348 @@
+package mcpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+)
+
+// StreamableHTTPTransport talks to a server over MCP Streamable HTTP: each
+// message is POSTed to URL and answered with JSON or an SSE stream. Once a
+// session is established, a GET stream receives server-initiated
+// notifications when the server offers one.
+type StreamableHTTPTransport struct {
+	URL     string
+	Headers map[string]string
+	// HTTPClient is used for requests; nil means http.DefaultClient.
+	HTTPClient *http.Client
+
+	mu        sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	receive   func([]byte)
+	closed    func(error)
+	sessionID string
+	wg        sync.WaitGroup
+}
+
+// NewStreamableHTTPTransport creates a Streamable HTTP transport.
+func NewStreamableHTTPTransport(url string, headers map[string]string) *StreamableHTTPTransport {
+	return &StreamableHTTPTransport{URL: url, Headers: headers}
+}
+
+// Start prepares the transport; no request is made until the first Send.
+func (t *StreamableHTTPTransport) Start(_ context.Context, receive func([]byte), closed func(error)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+	t.receive = receive
+	t.closed = closed
+	return nil
+}
+
+// Send POSTs one message. Responses delivered as an SSE stream are read in
+// the background until the stream ends or ctx is done.
+func (t *StreamableHTTPTransport) Send(ctx context.Context, message []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeaders(req)
+
+	resp, err := httpClient(t.HTTPClient).Do(req)
+	if err != nil {
+		return err
+	}
+
+	if id := resp.Header.Get(mcp.SessionIDHeader); id != "" {
+		t.mu.Lock()
+		first := t.sessionID == ""
+		t.sessionID = id
+		t.mu.Unlock()
+		if first {
+			t.wg.Add(1)
+			go t.listen()
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "text/event-stream"):
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			defer resp.Body.Close()
+			readEvents(resp.Body, func(_ string, data []byte) bool {
+				t.receive(data)
+				return true
+			})
+		}()
+	case strings.HasPrefix(contentType, "application/json"):
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxMessageSize))
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		if len(bytes.TrimSpace(body)) > 0 {
+			t.receive(body)
+		}
+	default:
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// listen holds open the GET stream for server-initiated messages. Servers
+// without one answer 405, which is not an error.
+func (t *StreamableHTTPTransport) listen() {
+	defer t.wg.Done()
+	req, err := http.NewRequestWithContext(t.ctx, http.MethodGet, t.URL, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	t.setHeaders(req)
+	resp, err := httpClient(t.HTTPClient).Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+	readEvents(resp.Body, func(_ string, data []byte) bool {
+		t.receive(data)
+		return true
+	})
+}
+
+// Close ends the session.
+func (t *StreamableHTTPTransport) Close() error {
+	t.mu.Lock()
+	cancel, closed, sessionID := t.cancel, t.closed, t.sessionID
+	t.cancel = nil
+	t.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	if sessionID != "" {
+		req, err := http.NewRequest(http.MethodDelete, t.URL, nil)
+		if err == nil {
+			t.setHeaders(req)
+			if resp, err := httpClient(t.HTTPClient).Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+	cancel()
+	t.wg.Wait()
+	closed(nil)
+	return nil
+}
+
+func (t *StreamableHTTPTransport) setHeaders(req *http.Request) {
+	for k, v := range t.Headers {
+		req.Header.Set(k, v)
+	}
+	t.mu.Lock()
+	if t.sessionID != "" {
+		req.Header.Set(mcp.SessionIDHeader, t.sessionID)
+	}
+	t.mu.Unlock()
+}
+
+// SSETransport talks to a server over the HTTP+SSE transport: messages
+// arrive on a GET event stream, which first announces the endpoint that
+// messages are POSTed to.
+type SSETransport struct {
+	URL     string
+	Headers map[string]string
+	// HTTPClient is used for requests; nil means http.DefaultClient.
+	HTTPClient *http.Client
+
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	endpoint string
+	done     chan struct{}
+}
+
+// NewSSETransport creates an HTTP+SSE transport.
+func NewSSETransport(url string, headers map[string]string) *SSETransport {
+	return &SSETransport{URL: url, Headers: headers}
+}
+
+// Start opens the event stream and waits for the endpoint event.
+func (t *SSETransport) Start(ctx context.Context, receive func([]byte), closed func(error)) error {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, t.URL, nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	for k, v := range t.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient(t.HTTPClient).Do(req)
+	if err != nil {
+		cancel()
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return fmt.Errorf("HTTP %d opening event stream", resp.StatusCode)
+	}
+
+	endpoint := make(chan string, 1)
+	t.done = make(chan struct{})
+	go func() {
+		defer close(t.done)
+		defer resp.Body.Close()
+		announced := false
+		readEvents(resp.Body, func(event string, data []byte) bool {
+			if event == "endpoint" && !announced {
+				announced = true
+				endpoint <- string(data)
+				return true
+			}
+			receive(data)
+			return true
+		})
+		if !announced {
+			close(endpoint)
+		}
+		closed(streamCtx.Err())
+	}()
+
+	select {
+	case raw, ok := <-endpoint:
+		if !ok {
+			cancel()
+			return fmt.Errorf("event stream ended before the endpoint event")
+		}
+		base, err := url.Parse(t.URL)
+		if err == nil {
+			var ref *url.URL
+			if ref, err = url.Parse(raw); err == nil {
+				raw = base.ResolveReference(ref).String()
+			}
+		}
+		if err != nil {
+			cancel()
+			return fmt.Errorf("invalid endpoint %q: %w", raw, err)
+		}
+		t.mu.Lock()
+		t.endpoint = raw
+		t.cancel = cancel
+		t.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
+
+// Send POSTs one message to the announced endpoint; any response arrives
+// on the event stream.
+func (t *SSETransport) Send(ctx context.Context, message []byte) error {
+	t.mu.Lock()
+	endpoint := t.endpoint
+	t.mu.Unlock()
+	if endpoint == "" {
+		return ErrClosed
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient(t.HTTPClient).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Close closes the event stream.
+func (t *SSETransport) Close() error {
+	t.mu.Lock()
+	cancel := t.cancel
+	t.cancel = nil
+	t.endpoint = ""
+	t.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-t.done
+	return nil
+}
+
+// readEvents calls fn for each server-sent event in r until r ends or fn
+// returns false. Multi-line data fields are joined with newlines.
+func readEvents(r io.Reader, fn func(event string, data []byte) bool) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	var (
+		event string
+		data  []byte
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data != nil {
+				if event == "" {
+					event = "message"
+				}
+				if !fn(event, data) {
+					return
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			value := strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+			if data != nil {
+				data = append(data, '\n')
+			}
+			data = append(data, value...)
+		}
+	}
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,95 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+)
+
+// InProcessTransport connects to an mcp.Server in the same process, which
+// is mainly useful for tests.
+//
+// While started, the transport is the server's notification sender, so a
+// server should be connected to one transport at a time.
+type InProcessTransport struct {
+	server *mcp.Server
+
+	mu       sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	receive  func([]byte)
+	closed   func(error)
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewInProcessTransport creates a transport for server.
+func NewInProcessTransport(server *mcp.Server) *InProcessTransport {
+	return &InProcessTransport{server: server}
+}
+
+// Start attaches to the server.
+func (t *InProcessTransport) Start(_ context.Context, receive func([]byte), closed func(error)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+	t.receive = receive
+	t.closed = closed
+	t.server.SetNotificationSender(func(notification []byte) error {
+		receive(notification)
+		return nil
+	})
+	return nil
+}
+
+// Send hands the message to the server. Requests are handled concurrently,
+// so notifications/cancelled can reach a running tool.
+func (t *InProcessTransport) Send(_ context.Context, message []byte) error {
+	t.mu.Lock()
+	ctx, receive := t.ctx, t.receive
+	if ctx == nil || ctx.Err() != nil {
+		t.mu.Unlock()
+		return ErrClosed
+	}
+	t.wg.Add(1)
+	t.mu.Unlock()
+
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	_ = json.Unmarshal(message, &envelope)
+	isRequest := envelope.Method != "" && len(envelope.ID) > 0 && string(envelope.ID) != "null"
+
+	go func() {
+		defer t.wg.Done()
+		reqCtx := mcp.WithNotificationSender(ctx, func(notification []byte) error {
+			receive(notification)
+			return nil
+		})
+		resp, err := t.server.HandleJSONRPC(reqCtx, message)
+		if err == nil && isRequest {
+			receive(resp)
+		}
+	}()
+	return nil
+}
+
+// Close detaches from the server and cancels running requests.
+func (t *InProcessTransport) Close() error {
+	t.stopOnce.Do(func() {
+		t.mu.Lock()
+		cancel, closed := t.cancel, t.closed
+		t.mu.Unlock()
+		if cancel == nil {
+			return
+		}
+		cancel()
+		t.wg.Wait()
+		t.server.SetNotificationSender(nil)
+		closed(nil)
+	})
+	return nil
+}
@@ -0,0 +1,127 @@
+package mcpclient
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const (
+	// maxMessageSize bounds a single message read from a server.
+	maxMessageSize = 10 * 1024 * 1024
+	// stdioCloseTimeout is how long Close waits for the server to exit
+	// after its stdin is closed before killing it.
+	stdioCloseTimeout = 5 * time.Second
+)
+
+// StdioTransport runs an MCP server as a subprocess and talks
+// newline-delimited JSON-RPC over its stdin and stdout.
+type StdioTransport struct {
+	Command string
+	Args    []string
+	// Env is added to the current process environment.
+	Env map[string]string
+	// Dir is the working directory; empty means the current one.
+	Dir string
+	// Stderr receives the server's stderr; nil discards it.
+	Stderr io.Writer
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	mu     sync.Mutex
+	exited chan struct{}
+}
+
+// NewStdioTransport creates a transport for the given command.
+func NewStdioTransport(command string, args []string, env map[string]string) *StdioTransport {
+	return &StdioTransport{Command: command, Args: args, Env: env}
+}
+
+// Start launches the server process.
+func (t *StdioTransport) Start(_ context.Context, receive func([]byte), closed func(error)) error {
+	if t.Command == "" {
+		return fmt.Errorf("stdio transport requires a command")
+	}
+	cmd := exec.Command(t.Command, t.Args...)
+	cmd.Dir = t.Dir
+	cmd.Env = os.Environ()
+	for k, v := range t.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stderr = t.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", t.Command, err)
+	}
+	t.cmd = cmd
+	t.stdin = stdin
+	t.exited = make(chan struct{})
+
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			if line := scanner.Bytes(); len(line) > 0 {
+				receive(append([]byte(nil), line...))
+			}
+		}
+		scanErr := scanner.Err()
+		waitErr := cmd.Wait()
+		close(t.exited)
+		if scanErr != nil {
+			closed(scanErr)
+		} else if waitErr != nil {
+			closed(fmt.Errorf("server exited: %w", waitErr))
+		} else {
+			closed(io.EOF)
+		}
+	}()
+	return nil
+}
+
+// Send writes one message followed by a newline.
+func (t *StdioTransport) Send(_ context.Context, message []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stdin == nil {
+		return ErrClosed
+	}
+	if _, err := t.stdin.Write(append(message, '\n')); err != nil {
+		return fmt.Errorf("failed to write to server: %w", err)
+	}
+	return nil
+}
+
+// Close closes the server's stdin and waits for it to exit, killing it
+// if it does not exit promptly.
+func (t *StdioTransport) Close() error {
+	t.mu.Lock()
+	stdin := t.stdin
+	t.stdin = nil
+	t.mu.Unlock()
+	if stdin == nil {
+		return nil
+	}
+	_ = stdin.Close()
+
+	select {
+	case <-t.exited:
+	case <-time.After(stdioCloseTimeout):
+		_ = t.cmd.Process.Kill()
+		<-t.exited
+	}
+	return nil
+}
@@ -137,3 +137,45 @@ func TestHookEventMessageIsSystemMessage(t *testing.T) {
 		t.Errorf("embedded SystemMessage.Subtype = %q, want hook_started", hem.SystemMessage.Subtype)
 	}
 }
+
+// TestParseHookEventProgressAndTypedFields covers hook_progress and the
+// typed hook_id / hook_name / outcome / exit_code fields. A hook_name of
+// the form "Event:matcher" yields just the event.
+func TestParseHookEventProgressAndTypedFields(t *testing.T) {
+	parser := New()
+	msg, err := parser.ParseMessage(map[string]any{
+		"type":      "system",
+		"subtype":   "hook_progress",
+		"hook_id":   "hook-1",
+		"hook_name": "SessionStart:startup",
+		"stdout":    "loading",
+	})
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	hem, ok := msg.(*shared.HookEventMessage)
+	if !ok {
+		t.Fatalf("expected *HookEventMessage, got %T", msg)
+	}
+	if hem.Event() != shared.HookEventSessionStart {
+		t.Errorf("Event() = %q, want SessionStart", hem.Event())
+	}
+	if hem.HookID == nil || *hem.HookID != "hook-1" || hem.HookName == nil || *hem.HookName != "SessionStart:startup" {
+		t.Errorf("HookID/HookName = %v/%v", hem.HookID, hem.HookName)
+	}
+
+	msg, err = parser.ParseMessage(map[string]any{
+		"type":       "system",
+		"subtype":    "hook_response",
+		"hook_event": "SessionEnd",
+		"exit_code":  float64(2),
+		"outcome":    "error",
+	})
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	hem = msg.(*shared.HookEventMessage)
+	if hem.ExitCode == nil || *hem.ExitCode != 2 || hem.Outcome == nil || *hem.Outcome != "error" {
+		t.Errorf("ExitCode/Outcome = %v/%v", hem.ExitCode, hem.Outcome)
+	}
+}
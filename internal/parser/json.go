@@ -341,29 +341,10 @@ func (p *Parser) parseSystemMessage(data map[string]any) (shared.Message, error)
 	}
 
 	// Hook events (emitted when IncludeHookEvents is enabled) arrive as
-	// system messages with subtype hook_started or hook_response. Route
-	// them to HookEventMessage before the switch below.
-	if subtype == "hook_started" || subtype == "hook_response" {
-		base := shared.SystemMessage{Subtype: subtype, Data: data}
-		hookEventName := ""
-		if v, ok := data["hook_event"].(string); ok {
-			hookEventName = v
-		} else if v, ok := data["hook_name"].(string); ok {
-			hookEventName = v
-		} else if v, ok := data["hook_event_name"].(string); ok {
-			hookEventName = v
-		}
-		msg := &shared.HookEventMessage{
-			SystemMessage: base,
-			HookEventName: hookEventName,
-		}
-		if v, ok := data["session_id"].(string); ok {
-			msg.SessionID = &v
-		}
-		if v, ok := data["uuid"].(string); ok {
-			msg.UUID = &v
-		}
-		return msg, nil
+	// system messages with subtype hook_started, hook_progress or
+	// hook_response. Route them to HookEventMessage before the switch below.
+	if shared.IsHookEventSubtype(subtype) {
+		return shared.NewHookEventMessage(subtype, data), nil
 	}
 
 	base := shared.SystemMessage{
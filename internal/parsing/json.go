@@ -334,28 +334,11 @@ func (p *Parser) parseSystemMessage(data map[string]any) (shared.Message, error)
 		return nil, shared.NewMessageParseError("system message missing subtype field", data)
 	}
 
-	// Hook events (emitted when IncludeHookEvents is enabled) arrive as system
-	// messages with subtype hook_started or hook_response. Route them to
-	// HookEventMessage before the switch below.
-	if subtype == "hook_started" || subtype == "hook_response" {
-		msg := &shared.HookEventMessage{
-			SystemMessage: shared.SystemMessage{Subtype: subtype, Data: data},
-		}
-		// The CLI has spelled this key three ways across versions; accept all.
-		if v, ok := data["hook_event"].(string); ok {
-			msg.HookEventName = v
-		} else if v, ok := data["hook_name"].(string); ok {
-			msg.HookEventName = v
-		} else if v, ok := data["hook_event_name"].(string); ok {
-			msg.HookEventName = v
-		}
-		if v, ok := data["session_id"].(string); ok {
-			msg.SessionID = &v
-		}
-		if v, ok := data["uuid"].(string); ok {
-			msg.UUID = &v
-		}
-		return msg, nil
+	// Hook events (emitted when IncludeHookEvents is enabled) arrive as
+	// system messages with subtype hook_started, hook_progress or
+	// hook_response. Route them to HookEventMessage before the switch below.
+	if shared.IsHookEventSubtype(subtype) {
+		return shared.NewHookEventMessage(subtype, data), nil
 	}
 
 	base := shared.SystemMessage{
@@ -0,0 +1,432 @@
+package policy
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// Decision is the outcome of evaluating a tool call, with a trace of how
+// it was reached.
+type Decision struct {
+	// Decision is "allow", "deny" or "ask".
+	Decision string
+	// Rule is the name (or "#n" position) of the deciding rule, or empty
+	// when the policy default applied.
+	Rule         string
+	Message      string
+	Interrupt    bool
+	UpdatedInput map[string]any
+	Trace        []TraceStep
+}
+
+// TraceStep records why one rule did or did not match.
+type TraceStep struct {
+	Rule    string
+	Matched bool
+	Reason  string
+}
+
+// Explain renders the trace as one line per rule followed by the outcome.
+func (d *Decision) Explain() string {
+	var b strings.Builder
+	for _, step := range d.Trace {
+		mark := "skip"
+		if step.Matched {
+			mark = "match"
+		}
+		fmt.Fprintf(&b, "rule %s: %s (%s)\n", step.Rule, mark, step.Reason)
+	}
+	if d.Rule == "" {
+		fmt.Fprintf(&b, "=> %s (default)", d.Decision)
+	} else {
+		fmt.Fprintf(&b, "=> %s by rule %s", d.Decision, d.Rule)
+	}
+	if d.Message != "" {
+		fmt.Fprintf(&b, ": %s", d.Message)
+	}
+	return b.String()
+}
+
+// Engine evaluates tool calls against a compiled Policy. It is safe for
+// concurrent use.
+type Engine struct {
+	policy Policy
+	rules  []*compiledRule
+
+	mu    sync.Mutex
+	calls map[int]int // rule index -> calls decided, for MaxCalls
+
+	now        func() time.Time
+	onDecision func(toolName string, decision *Decision)
+}
+
+// Option configures an Engine.
+type Option func(*Engine)
+
+// WithClock overrides the time source used by time windows.
+func WithClock(now func() time.Time) Option {
+	return func(e *Engine) {
+		if now != nil {
+			e.now = now
+		}
+	}
+}
+
+// WithDecisionHandler reports every decision, e.g. to log Explain output.
+func WithDecisionHandler(fn func(toolName string, decision *Decision)) Option {
+	return func(e *Engine) {
+		e.onDecision = fn
+	}
+}
+
+// New validates and compiles policy.
+func New(policy *Policy, opts ...Option) (*Engine, error) {
+	if policy == nil {
+		return nil, fmt.Errorf("policy cannot be nil")
+	}
+	rules, err := policy.compile()
+	if err != nil {
+		return nil, err
+	}
+	e := &Engine{
+		policy: *policy,
+		rules:  rules,
+		calls:  make(map[int]int),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// Evaluate decides a tool call. A rule with MaxCalls counts the calls it
+// decides, so Evaluate is not free of side effects.
+func (e *Engine) Evaluate(toolName string, input map[string]any) *Decision {
+	decision := &Decision{}
+	now := e.now()
+
+	e.mu.Lock()
+	for _, rule := range e.rules {
+		label := ruleLabel(rule.index, rule.Name)
+		matched, reason := e.match(rule, toolName, input, now)
+		decision.Trace = append(decision.Trace, TraceStep{Rule: label, Matched: matched, Reason: reason})
+		if !matched {
+			continue
+		}
+		e.calls[rule.index]++
+		decision.Decision = rule.Decision
+		decision.Rule = label
+		decision.Message = rule.Message
+		decision.Interrupt = rule.Interrupt
+		if rule.Decision == DecisionAllow && len(rule.UpdatedInput) > 0 {
+			decision.UpdatedInput = maps.Clone(input)
+			if decision.UpdatedInput == nil {
+				decision.UpdatedInput = make(map[string]any, len(rule.UpdatedInput))
+			}
+			maps.Copy(decision.UpdatedInput, rule.UpdatedInput)
+		}
+		break
+	}
+	e.mu.Unlock()
+
+	if decision.Decision == "" {
+		decision.Decision = e.policy.Default
+		if decision.Decision == "" {
+			decision.Decision = DecisionDeny
+		}
+		decision.Message = e.policy.DefaultMessage
+		if decision.Message == "" && decision.Decision == DecisionDeny {
+			decision.Message = fmt.Sprintf("%s is not permitted by policy", toolName)
+		}
+	}
+	if decision.Decision == DecisionDeny && decision.Message == "" {
+		decision.Message = fmt.Sprintf("%s denied by policy rule %s", toolName, decision.Rule)
+	}
+	if e.onDecision != nil {
+		e.onDecision(toolName, decision)
+	}
+	return decision
+}
+
+// match reports whether rule matches the call and why. Callers hold e.mu.
+func (e *Engine) match(rule *compiledRule, toolName string, input map[string]any, now time.Time) (bool, string) {
+	if len(rule.tools) > 0 && !anyMatch(rule.tools, toolName) {
+		return false, fmt.Sprintf("tool %s not in %v", toolName, rule.Tools)
+	}
+
+	if len(rule.mcpServers) > 0 || len(rule.mcpTools) > 0 {
+		server, tool, ok := splitMcpToolName(toolName)
+		if !ok {
+			return false, fmt.Sprintf("%s is not an MCP tool", toolName)
+		}
+		if len(rule.mcpServers) > 0 && !anyMatch(rule.mcpServers, server) {
+			return false, fmt.Sprintf("MCP server %s not in %v", server, rule.McpServers)
+		}
+		if len(rule.mcpTools) > 0 && !anyMatch(rule.mcpTools, tool) {
+			return false, fmt.Sprintf("MCP tool %s not in %v", tool, rule.McpTools)
+		}
+	}
+
+	if len(rule.Commands) > 0 || len(rule.commandRegex) > 0 {
+		command, ok := input["command"].(string)
+		if !ok {
+			return false, "no command in input"
+		}
+		if len(rule.Commands) > 0 {
+			if ok, reason := matchCommands(command, rule.Commands, rule.Decision == DecisionAllow); !ok {
+				return false, reason
+			}
+		}
+		if len(rule.commandRegex) > 0 && !anyMatch(rule.commandRegex, command) {
+			return false, fmt.Sprintf("command does not match %v", rule.CommandRegex)
+		}
+	}
+
+	if len(rule.paths) > 0 {
+		path, ok := inputPath(input)
+		if !ok {
+			return false, "no file path in input"
+		}
+		if !anyMatch(rule.paths, path) {
+			return false, fmt.Sprintf("path %s not in %v", path, rule.Paths)
+		}
+	}
+
+	if rule.window != nil && !rule.window.contains(now) {
+		return false, "outside time window"
+	}
+
+	if rule.MaxCalls > 0 && e.calls[rule.index] >= rule.MaxCalls {
+		return false, fmt.Sprintf("max_calls %d exhausted", rule.MaxCalls)
+	}
+
+	return true, "all conditions hold"
+}
+
+// pathInputKeys are the input fields that carry a file path, in the order
+// they are checked.
+var pathInputKeys = []string{"file_path", "notebook_path", "path"}
+
+func inputPath(input map[string]any) (string, bool) {
+	for _, key := range pathInputKeys {
+		if path, ok := input[key].(string); ok && path != "" {
+			return filepath.ToSlash(filepath.Clean(path)), true
+		}
+	}
+	return "", false
+}
+
+// splitMcpToolName splits mcp__<server>__<tool>.
+func splitMcpToolName(name string) (server, tool string, ok bool) {
+	rest, found := strings.CutPrefix(name, "mcp__")
+	if !found {
+		return "", "", false
+	}
+	return strings.Cut(rest, "__")
+}
+
+func anyMatch(patterns []*regexp.Regexp, value string) bool {
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandSeparators split a Bash command line into its simple commands:
+// lists, pipelines, subshells and command substitutions.
+var commandSeparators = regexp.MustCompile("&&|\\|\\||[;&|\n()`]")
+
+// commandSubstitution finds constructs that run a nested command.
+var commandSubstitution = regexp.MustCompile("`|\\$\\(|[<>]\\(")
+
+// commandRedirection finds redirections, which let a command read or write
+// any file.
+var commandRedirection = regexp.MustCompile("[<>]")
+
+// commandWrapper describes a command that runs the command following it.
+type commandWrapper struct {
+	valueOptions []string // options that take a separate value
+	operands     int      // arguments before the wrapped command
+}
+
+// commandWrappers are the wrappers deny rules see through.
+var commandWrappers = map[string]commandWrapper{
+	"sudo":    {valueOptions: []string{"-u", "-g", "-C", "-D", "-h", "-p", "-r", "-t", "-T", "-U"}},
+	"doas":    {valueOptions: []string{"-u", "-C"}},
+	"env":     {valueOptions: []string{"-u", "-C", "-S"}},
+	"command": {},
+	"builtin": {},
+	"exec":    {valueOptions: []string{"-a"}},
+	"nohup":   {},
+	"time":    {},
+	"nice":    {valueOptions: []string{"-n"}},
+	"timeout": {valueOptions: []string{"-s", "-k"}, operands: 1},
+}
+
+// commandAssignment matches a leading VAR=value word.
+var commandAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// commandQuoting is removed from command names, which the shell unquotes.
+var commandQuoting = strings.NewReplacer(`"`, "", `'`, "", `\`, "")
+
+// MatchCommand reports whether a Bash command matches the prefixes the way
+// a rule's Commands do. allow selects the stricter allow-rule matching.
+func MatchCommand(command string, prefixes []string, allow bool) bool {
+	ok, _ := matchCommands(command, prefixes, allow)
+	return ok
+}
+
+// matchCommands reports whether command matches the prefixes. An allow
+// rule must match every simple command in it and never matches command
+// substitution or redirection. Other rules match if any simple command
+// matches, either as written or after normalizeCommand, so chaining,
+// VAR=value prefixes, the wrappers in commandWrappers and a path on the
+// command name do not dodge a deny rule. Other indirection, such as eval,
+// sh -c or a script, is not detected.
+func matchCommands(command string, prefixes []string, allow bool) (bool, string) {
+	var parts []string
+	for _, part := range commandSeparators.Split(command, -1) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if !allow {
+		for _, part := range parts {
+			if hasCommandPrefix(part, prefixes) || hasCommandPrefix(normalizeCommand(part), prefixes) {
+				return true, ""
+			}
+		}
+		return false, fmt.Sprintf("no command starts with %v", prefixes)
+	}
+	if commandSubstitution.MatchString(command) {
+		return false, "command substitution is never allowed by commands"
+	}
+	if commandRedirection.MatchString(command) {
+		return false, "redirection is never allowed by commands"
+	}
+	if len(parts) == 0 {
+		return false, "empty command"
+	}
+	for _, part := range parts {
+		if !hasCommandPrefix(part, prefixes) {
+			return false, fmt.Sprintf("%q does not start with %v", part, prefixes)
+		}
+	}
+	return true, ""
+}
+
+// normalizeCommand reduces a simple command to the command that actually
+// runs: leading VAR=value words and wrappers are dropped, the command name
+// is unquoted and reduced to its base name, and words are joined by single
+// spaces. "FOO=1 sudo -u root /bin/rm  -rf x" becomes "rm -rf x".
+func normalizeCommand(command string) string {
+	words := strings.Fields(command)
+	for len(words) > 0 {
+		name := path.Base(commandQuoting.Replace(words[0]))
+		if commandAssignment.MatchString(words[0]) {
+			words = words[1:]
+			continue
+		}
+		wrapper, ok := commandWrappers[name]
+		if !ok {
+			words[0] = name
+			return strings.Join(words, " ")
+		}
+		words = words[1:]
+		for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+			option := words[0]
+			words = words[1:]
+			if option == "--" {
+				break
+			}
+			if slices.Contains(wrapper.valueOptions, option) && len(words) > 0 {
+				words = words[1:]
+			}
+		}
+		words = words[min(wrapper.operands, len(words)):]
+	}
+	return ""
+}
+
+// hasCommandPrefix reports whether command starts with any prefix as whole
+// words: "git" matches "git log" but not "gitk".
+func hasCommandPrefix(command string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSpace(prefix)
+		rest, ok := strings.CutPrefix(command, prefix)
+		if ok && prefix != "" && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return true
+		}
+	}
+	return false
+}
+
+// CanUseTool returns a permission callback enforcing the policy. An "ask"
+// decision is delegated to fallback (e.g. an interactive approver), or
+// denied when fallback is nil.
+func (e *Engine) CanUseTool(fallback shared.CanUseToolCallback) shared.CanUseToolCallback {
+	return func(toolName string, input map[string]any, ctx shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		decision := e.Evaluate(toolName, input)
+		if decision.Decision != DecisionAsk || fallback == nil {
+			approver := "policy:default"
+			if decision.Rule != "" {
+				approver = "policy:" + decision.Rule
+			}
+			shared.RecordApprover(ctx.Context, approver)
+		}
+		switch decision.Decision {
+		case DecisionAllow:
+			var updated any
+			if decision.UpdatedInput != nil {
+				updated = decision.UpdatedInput
+			}
+			return shared.NewPermissionAllow(updated, nil), nil
+		case DecisionAsk:
+			if fallback != nil {
+				return fallback(toolName, input, ctx)
+			}
+			message := decision.Message
+			if message == "" {
+				message = fmt.Sprintf("%s requires approval", toolName)
+			}
+			return shared.NewPermissionDeny(message, false), nil
+		default:
+			return shared.NewPermissionDeny(decision.Message, decision.Interrupt), nil
+		}
+	}
+}
+
+// PreToolUseHook returns a PreToolUse hook callback enforcing the policy
+// through permissionDecision, so it applies even to calls the CLI would
+// otherwise allow without asking.
+func (e *Engine) PreToolUseHook() shared.HookCallback {
+	return func(raw shared.HookInput, _ *string, _ shared.HookContext) (shared.HookJSONOutput, error) {
+		typed, err := shared.DecodeHookInput(raw)
+		if err != nil {
+			return nil, err
+		}
+		input, ok := typed.(*shared.PreToolUseHookInput)
+		if !ok {
+			return nil, fmt.Errorf("policy hook received %T, want PreToolUse input", typed)
+		}
+		decision := e.Evaluate(input.ToolName, input.ToolInput)
+		output := shared.NewPreToolUseOutput(decision.Decision, decision.Message, decision.UpdatedInput)
+		if decision.Decision == DecisionDeny && decision.Interrupt {
+			output["continue"] = false
+			output["stopReason"] = decision.Message
+		}
+		return output, nil
+	}
+}
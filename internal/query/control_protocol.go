@@ -14,8 +14,14 @@ import (
 
 // ControlProtocol manages bidirectional control protocol communication.
 type ControlProtocol struct {
-	// Hook processor
+	// Hook processor, created on first runtime hook change if not supplied
 	hookProcessor *HookProcessor
+	hookMu        sync.RWMutex
+
+	// Error of the first rejected set_hooks request; later runtime hook
+	// changes for events not dispatched by the SDK fail with it at once.
+	setHooksErr error
+	setHooksMu  sync.Mutex
 
 	// SDK MCP servers for handling mcp_message requests
 	sdkMCPServers map[string]shared.McpSDKServer
@@ -26,7 +32,7 @@ type ControlProtocol struct {
 
 	// In-flight inbound control requests, keyed by request_id. Cancellable
 	// via control_cancel_request from the CLI (Python SDK fix #751).
-	inflightRequests map[string]context.CancelFunc
+	inflightRequests map[string]context.CancelCauseFunc
 	inflightMu       sync.Mutex
 
 	// Request counter
@@ -35,7 +41,7 @@ type ControlProtocol struct {
 
 	// Context
 	ctx    context.Context
-	cancel context.CancelFunc
+	cancel context.CancelCauseFunc
 
 	// Write function for sending messages
 	writeFn func([]byte) error
@@ -58,17 +64,36 @@ func NewControlProtocol(
 	writeFn func([]byte) error,
 	sdkMCPServers map[string]shared.McpSDKServer,
 ) *ControlProtocol {
-	cpCtx, cancel := context.WithCancel(ctx)
+	cpCtx, cancel := context.WithCancelCause(ctx)
 
-	return &ControlProtocol{
+	cp := &ControlProtocol{
 		hookProcessor:    hookProcessor,
 		sdkMCPServers:    sdkMCPServers,
 		pendingResponses: make(map[string]*pendingControlResponse),
-		inflightRequests: make(map[string]context.CancelFunc),
+		inflightRequests: make(map[string]context.CancelCauseFunc),
 		ctx:              cpCtx,
 		cancel:           cancel,
 		writeFn:          writeFn,
 	}
+
+	// Let SDK MCP servers push notifications (resource updates, list
+	// changes) to the CLI over the same mcp_message channel.
+	for name, server := range sdkMCPServers {
+		if notifier, ok := server.(mcpNotificationSetter); ok {
+			serverName := name
+			notifier.SetNotificationSender(func(notification []byte) error {
+				return cp.sendMCPNotification(serverName, notification)
+			})
+		}
+	}
+
+	return cp
+}
+
+// mcpNotificationSetter is implemented by SDK MCP servers that can emit
+// server-initiated JSON-RPC notifications.
+type mcpNotificationSetter interface {
+	SetNotificationSender(send func(notification []byte) error)
 }
 
 // Initialize sends initialization request to CLI.
@@ -89,8 +114,8 @@ func (cp *ControlProtocol) InitializeWithSkills(
 ) (map[string]any, error) {
 	// Build hooks configuration
 	var hooksConfig map[string]any
-	if cp.hookProcessor != nil {
-		rawConfig := cp.hookProcessor.BuildInitializeConfig()
+	if hookProcessor := cp.hooks(); hookProcessor != nil {
+		rawConfig := hookProcessor.BuildInitializeConfig()
 		if len(rawConfig) > 0 {
 			hooksConfig = make(map[string]any, len(rawConfig))
 			for k, v := range rawConfig {
@@ -222,8 +247,80 @@ func (cp *ControlProtocol) SetModel(model *string) error {
 	return err
 }
 
-// InterruptControl sends an interrupt control request to CLI.
+// hooks returns the hook processor, or nil if none was supplied and no
+// hook has been added at runtime.
+func (cp *ControlProtocol) hooks() *HookProcessor {
+	cp.hookMu.RLock()
+	defer cp.hookMu.RUnlock()
+	return cp.hookProcessor
+}
+
+// ensureHooks returns the hook processor, creating an empty one if needed.
+func (cp *ControlProtocol) ensureHooks() *HookProcessor {
+	cp.hookMu.Lock()
+	defer cp.hookMu.Unlock()
+	if cp.hookProcessor == nil {
+		cp.hookProcessor = NewHookProcessor(cp.ctx, nil)
+	}
+	return cp.hookProcessor
+}
+
+// setHooksTimeout bounds a set_hooks request. CLIs that do not implement
+// the subtype may never answer, so it is much shorter than other requests.
+const setHooksTimeout = 5 * time.Second
+
+// registerHooks sends the complete hooks configuration to the CLI with a
+// set_hooks request. Once the CLI has rejected or ignored one, later calls
+// fail without asking again.
+func (cp *ControlProtocol) registerHooks(config map[string][]shared.HookMatcherConfig) error {
+	cp.setHooksMu.Lock()
+	defer cp.setHooksMu.Unlock()
+	if cp.setHooksErr != nil {
+		return cp.setHooksErr
+	}
+
+	hooks := make(map[string]any, len(config))
+	for event, matchers := range config {
+		hooks[event] = matchers
+	}
+	request := map[string]any{
+		"subtype": shared.ControlSubtypeSetHooks,
+		"hooks":   hooks,
+	}
+	if _, err := cp.sendControlRequest(request, setHooksTimeout); err != nil {
+		cp.setHooksErr = fmt.Errorf("CLI did not accept updated hooks (dispatch the event in the SDK with WithRuntimeHooks): %w", err)
+		return cp.setHooksErr
+	}
+	return nil
+}
+
+// AddHook registers matcher for event on the running session. Events
+// configured with RuntimeHookEvents change without contacting the CLI;
+// others are re-registered with a set_hooks request.
+func (cp *ControlProtocol) AddHook(event shared.HookEvent, matcher shared.HookMatcher) (shared.HookHandle, error) {
+	return cp.ensureHooks().AddHook(event, matcher, cp.registerHooks)
+}
+
+// RemoveHook unregisters the matcher added under handle.
+func (cp *ControlProtocol) RemoveHook(handle shared.HookHandle) error {
+	hookProcessor := cp.hooks()
+	if hookProcessor == nil {
+		return fmt.Errorf("no hook matcher registered with handle %q", handle)
+	}
+	_, err := hookProcessor.RemoveHook(handle, cp.registerHooks)
+	return err
+}
+
+// ReplaceHooks replaces every matcher of event, including those set by
+// options, with matchers.
+func (cp *ControlProtocol) ReplaceHooks(event shared.HookEvent, matchers []shared.HookMatcher) ([]shared.HookHandle, error) {
+	return cp.ensureHooks().ReplaceHooks(event, matchers, cp.registerHooks)
+}
+
+// InterruptControl sends an interrupt control request to CLI. In-flight
+// hook and permission callbacks are aborted with ErrAbortInterrupted.
 func (cp *ControlProtocol) InterruptControl() error {
+	cp.abortInflight(shared.ErrAbortInterrupted)
 	request := map[string]any{
 		"subtype": shared.ControlSubtypeInterrupt,
 	}
@@ -297,7 +394,7 @@ func (cp *ControlProtocol) handleControlRequest(data []byte) error {
 
 	// Track this request as in-flight so a control_cancel_request from the
 	// CLI can cancel it (Python SDK fix #751).
-	reqCtx, cancelReq := context.WithCancel(cp.ctx)
+	reqCtx, cancelReq := context.WithCancelCause(cp.ctx)
 	cp.inflightMu.Lock()
 	cp.inflightRequests[request.RequestID] = cancelReq
 	cp.inflightMu.Unlock()
@@ -308,7 +405,7 @@ func (cp *ControlProtocol) handleControlRequest(data []byte) error {
 			cp.inflightMu.Lock()
 			delete(cp.inflightRequests, request.RequestID)
 			cp.inflightMu.Unlock()
-			cancelReq() // ensure context resources released
+			cancelReq(nil) // ensure context resources released
 		}()
 		cp.processControlRequestCtx(reqCtx, &request)
 	}()
@@ -332,13 +429,13 @@ func (cp *ControlProtocol) processControlRequestCtx(ctx context.Context, request
 
 	switch request.Request.Subtype {
 	case shared.ControlSubtypeCanUseTool:
-		responseData, err = cp.handleCanUseTool(request.Request.Data)
+		responseData, err = cp.handleCanUseTool(ctx, request.Request.Data)
 
 	case shared.ControlSubtypeHookCallback:
-		responseData, err = cp.handleHookCallback(request.Request.Data)
+		responseData, err = cp.handleHookCallback(ctx, request.Request.Data)
 
 	case shared.ControlSubtypeMCPMessage:
-		responseData, err = cp.handleMCPMessage(request.Request.Data)
+		responseData, err = cp.handleMCPMessage(ctx, request.Request.Data)
 
 	default:
 		err = fmt.Errorf("unsupported control request subtype: %s", request.Request.Subtype)
@@ -388,9 +485,11 @@ func (cp *ControlProtocol) processControlRequestCtx(ctx context.Context, request
 	}
 }
 
-// handleCanUseTool handles tool permission request.
-func (cp *ControlProtocol) handleCanUseTool(data map[string]any) (map[string]any, error) {
-	if cp.hookProcessor == nil {
+// handleCanUseTool handles tool permission request under the per-request
+// context.
+func (cp *ControlProtocol) handleCanUseTool(ctx context.Context, data map[string]any) (map[string]any, error) {
+	hookProcessor := cp.hooks()
+	if hookProcessor == nil {
 		return nil, fmt.Errorf("canUseTool callback is not provided")
 	}
 
@@ -433,7 +532,7 @@ func (cp *ControlProtocol) handleCanUseTool(data map[string]any) (map[string]any
 	}
 
 	// Process through hook processor
-	response, err := cp.hookProcessor.ProcessCanUseTool(request)
+	response, err := hookProcessor.ProcessCanUseToolCtx(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -462,8 +561,9 @@ func (cp *ControlProtocol) handleCanUseTool(data map[string]any) (map[string]any
 	return result, nil
 }
 
-// handleHookCallback handles hook callback request.
-func (cp *ControlProtocol) handleHookCallback(data map[string]any) (map[string]any, error) {
+// handleHookCallback handles hook callback request under the per-request
+// context.
+func (cp *ControlProtocol) handleHookCallback(ctx context.Context, data map[string]any) (map[string]any, error) {
 	// Convert data to HookCallbackRequest
 	request := &shared.HookCallbackRequest{
 		Subtype: shared.ControlSubtypeHookCallback,
@@ -481,12 +581,13 @@ func (cp *ControlProtocol) handleHookCallback(data map[string]any) (map[string]a
 		request.ToolUseID = &toolUseID
 	}
 
-	if cp.hookProcessor == nil {
+	hookProcessor := cp.hooks()
+	if hookProcessor == nil {
 		return nil, fmt.Errorf("no hook callback found for ID: %s", request.CallbackID)
 	}
 
 	// Process through hook processor
-	output, err := cp.hookProcessor.ProcessHookCallback(request)
+	output, err := hookProcessor.ProcessHookCallbackCtx(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -496,7 +597,11 @@ func (cp *ControlProtocol) handleHookCallback(data map[string]any) (map[string]a
 }
 
 // handleMCPMessage handles an MCP message request from CLI.
-func (cp *ControlProtocol) handleMCPMessage(data map[string]any) (map[string]any, error) {
+//
+// ctx is the per-request context: it is cancelled by a matching
+// control_cancel_request or when the protocol closes, and is handed to the
+// SDK MCP server so tool handlers observe it.
+func (cp *ControlProtocol) handleMCPMessage(ctx context.Context, data map[string]any) (map[string]any, error) {
 	serverName, ok := data["server_name"].(string)
 	if !ok || serverName == "" {
 		return nil, fmt.Errorf("missing server_name for MCP request")
@@ -530,7 +635,7 @@ func (cp *ControlProtocol) handleMCPMessage(data map[string]any) (map[string]any
 	}
 
 	// Route to the SDK MCP server
-	respBytes, err := server.HandleJSONRPC(cp.ctx, reqBytes)
+	respBytes, err := server.HandleJSONRPC(ctx, reqBytes)
 	if err != nil {
 		// Return JSONRPC error response
 		return map[string]any{
@@ -556,6 +661,39 @@ func (cp *ControlProtocol) handleMCPMessage(data map[string]any) (map[string]any
 	}, nil
 }
 
+// sendMCPNotification forwards a server-initiated JSON-RPC notification from
+// an SDK MCP server to the CLI as an mcp_message control request. The CLI's
+// acknowledgement is not awaited; handleControlResponse drops it.
+func (cp *ControlProtocol) sendMCPNotification(serverName string, notification []byte) error {
+	var message map[string]any
+	if err := json.Unmarshal(notification, &message); err != nil {
+		return fmt.Errorf("failed to unmarshal MCP notification: %w", err)
+	}
+
+	controlReq := shared.ControlRequest{
+		Type:      shared.ControlTypeRequest,
+		RequestID: cp.generateRequestID(),
+		Request: shared.RequestPayload{
+			Subtype: shared.ControlSubtypeMCPMessage,
+			Data: map[string]any{
+				"server_name": serverName,
+				"message":     message,
+			},
+		},
+	}
+
+	reqBytes, err := json.Marshal(controlReq)
+	if err != nil {
+		return fmt.Errorf("failed to marshal MCP notification: %w", err)
+	}
+	reqBytes = append(reqBytes, '\n')
+
+	if err := cp.writeFn(reqBytes); err != nil {
+		return fmt.Errorf("failed to send MCP notification: %w", err)
+	}
+	return nil
+}
+
 // handleControlCancel handles a control_cancel_request from the CLI.
 //
 // Cancels the matching in-flight inbound request (e.g. a long-running hook
@@ -579,11 +717,20 @@ func (cp *ControlProtocol) handleControlCancel(data []byte) error {
 	}
 	cp.inflightMu.Unlock()
 	if cancel != nil {
-		cancel()
+		cancel(shared.ErrAbortCancelled)
 	}
 	return nil
 }
 
+// abortInflight cancels every in-flight inbound request with reason.
+func (cp *ControlProtocol) abortInflight(reason error) {
+	cp.inflightMu.Lock()
+	defer cp.inflightMu.Unlock()
+	for _, cancel := range cp.inflightRequests {
+		cancel(reason)
+	}
+}
+
 // sendControlRequest sends a control request and waits for response.
 func (cp *ControlProtocol) sendControlRequest(
 	request map[string]any,
@@ -680,6 +827,9 @@ func (cp *ControlProtocol) RewindFiles(userMessageID string) error {
 // This is called when a fatal error occurs in the message reader to propagate errors
 // immediately instead of waiting for timeouts.
 func (cp *ControlProtocol) FailPendingRequests(err error) {
+	// Nothing can answer inbound requests either; abort their callbacks.
+	cp.abortInflight(fmt.Errorf("%w: %v", shared.ErrAbortDisconnected, err))
+
 	cp.responseMu.Lock()
 	defer cp.responseMu.Unlock()
 
@@ -694,7 +844,7 @@ func (cp *ControlProtocol) FailPendingRequests(err error) {
 
 // Close closes the control protocol handler.
 func (cp *ControlProtocol) Close() error {
-	cp.cancel()
+	cp.cancel(shared.ErrAbortDisconnected)
 
 	// Cancel all pending responses
 	cp.responseMu.Lock()
@@ -3,10 +3,13 @@ package query
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
 	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
 )
 
@@ -34,7 +37,7 @@ func TestInitializeTimeoutClampsToMinimum(t *testing.T) {
 func TestHandleCanUseToolWithoutHookProcessor(t *testing.T) {
 	cp := NewControlProtocol(context.Background(), nil, func([]byte) error { return nil }, nil)
 
-	_, err := cp.handleCanUseTool(map[string]any{
+	_, err := cp.handleCanUseTool(context.Background(), map[string]any{
 		"tool_name": "Bash",
 		"input": map[string]any{
 			"command": "echo hi",
@@ -51,7 +54,7 @@ func TestHandleCanUseToolWithoutHookProcessor(t *testing.T) {
 func TestHandleHookCallbackWithoutHookProcessor(t *testing.T) {
 	cp := NewControlProtocol(context.Background(), nil, func([]byte) error { return nil }, nil)
 
-	_, err := cp.handleHookCallback(map[string]any{
+	_, err := cp.handleHookCallback(context.Background(), map[string]any{
 		"callback_id": "hook_0",
 		"input":       map[string]any{},
 	})
@@ -75,7 +78,7 @@ func TestHandleHookCallbackAcceptsNonObjectInput(t *testing.T) {
 	}
 
 	cp := NewControlProtocol(ctx, hp, func([]byte) error { return nil }, nil)
-	resp, err := cp.handleHookCallback(map[string]any{
+	resp, err := cp.handleHookCallback(ctx, map[string]any{
 		"callback_id": callbackID,
 		"input":       "raw-input",
 	})
@@ -135,7 +138,7 @@ func TestHandleControlCancelCancelsInflightRequest(t *testing.T) {
 	// Manually register an in-flight request — emulates handleControlRequest
 	// having spawned a long-running handler.
 	reqID := "req_42"
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancelCause(context.Background())
 	cp.inflightMu.Lock()
 	cp.inflightRequests[reqID] = cancel
 	cp.inflightMu.Unlock()
@@ -171,6 +174,9 @@ func TestHandleControlCancelCancelsInflightRequest(t *testing.T) {
 	case <-time.After(500 * time.Millisecond):
 		t.Fatal("context not cancelled within deadline")
 	}
+	if cause := context.Cause(ctx); cause != shared.ErrAbortCancelled {
+		t.Fatalf("cause = %v, want ErrAbortCancelled", cause)
+	}
 }
 
 // TestHandleControlCancelUnknownIDIsNoOp ensures cancelling a stale id is
@@ -194,3 +200,169 @@ func TestHandleControlCancelMalformedReturnsError(t *testing.T) {
 		t.Fatal("expected error from malformed control_cancel_request")
 	}
 }
+
+type notifyingMCPServer struct {
+	send func([]byte) error
+}
+
+func (s *notifyingMCPServer) Name() string    { return "notifier" }
+func (s *notifyingMCPServer) Version() string { return "1.0.0" }
+func (s *notifyingMCPServer) HandleJSONRPC(interface{}, []byte) ([]byte, error) {
+	return []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`), nil
+}
+func (s *notifyingMCPServer) SetNotificationSender(send func([]byte) error) { s.send = send }
+
+// TestSDKMCPServerNotificationsForwardedAsMCPMessage verifies that
+// server-initiated notifications reach the CLI as mcp_message control
+// requests addressed to the originating server.
+func TestSDKMCPServerNotificationsForwardedAsMCPMessage(t *testing.T) {
+	var written [][]byte
+	server := &notifyingMCPServer{}
+	NewControlProtocol(context.Background(), nil, func(data []byte) error {
+		written = append(written, data)
+		return nil
+	}, map[string]shared.McpSDKServer{"docs": server})
+
+	if server.send == nil {
+		t.Fatal("expected notification sender to be installed")
+	}
+	if err := server.send([]byte(`{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"docs://a"}}`)); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if len(written) != 1 || !strings.HasSuffix(string(written[0]), "\n") {
+		t.Fatalf("written = %q", written)
+	}
+	var req shared.ControlRequest
+	if err := json.Unmarshal(written[0], &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if req.Type != shared.ControlTypeRequest || req.Request.Subtype != shared.ControlSubtypeMCPMessage {
+		t.Errorf("request = %+v", req)
+	}
+	if req.Request.Data["server_name"] != "docs" {
+		t.Errorf("server_name = %v", req.Request.Data["server_name"])
+	}
+	message, _ := req.Request.Data["message"].(map[string]any)
+	if message["method"] != "notifications/resources/updated" {
+		t.Errorf("message = %v", message)
+	}
+}
+
+// TestControlCancelCancelsSDKMCPToolCall verifies that a control_cancel_request
+// for an mcp_message reaches the tool handler's context and suppresses the
+// stale response.
+func TestControlCancelCancelsSDKMCPToolCall(t *testing.T) {
+	server := mcp.NewServer("slow", "1.0.0")
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	err := server.RegisterTool(&mcp.ToolDefinition{
+		Name: "wait",
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]mcp.Content, error) {
+			close(started)
+			<-ctx.Done()
+			close(stopped)
+			return nil, ctx.Err()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var writes atomic.Int32
+	cp := NewControlProtocol(context.Background(), nil, func([]byte) error {
+		writes.Add(1)
+		return nil
+	}, map[string]shared.McpSDKServer{"slow": server})
+
+	req, _ := json.Marshal(map[string]any{
+		"type":       "control_request",
+		"request_id": "req_mcp",
+		"request": map[string]any{
+			"subtype":     "mcp_message",
+			"server_name": "slow",
+			"message": map[string]any{
+				"jsonrpc": "2.0",
+				"id":      1,
+				"method":  "tools/call",
+				"params":  map[string]any{"name": "wait"},
+			},
+		},
+	})
+	if err := cp.handleControlRequest(req); err != nil {
+		t.Fatalf("handleControlRequest: %v", err)
+	}
+	<-started
+
+	cancelMsg, _ := json.Marshal(map[string]any{"type": "control_cancel_request", "request_id": "req_mcp"})
+	if err := cp.handleControlCancel(cancelMsg); err != nil {
+		t.Fatalf("handleControlCancel: %v", err)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("tool handler context was not cancelled")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := writes.Load(); n != 0 {
+		t.Errorf("expected no response for cancelled request, got %d writes", n)
+	}
+}
+
+// TestHookCallbackAbortReasons verifies that a hook's per-invocation
+// context and Signal fire with the right reason on control cancel,
+// interrupt and close.
+func TestHookCallbackAbortReasons(t *testing.T) {
+	cases := []struct {
+		name  string
+		abort func(cp *ControlProtocol)
+		want  error
+	}{
+		{"control cancel", func(cp *ControlProtocol) {
+			msg, _ := json.Marshal(map[string]any{"type": "control_cancel_request", "request_id": "req_hook"})
+			_ = cp.handleControlCancel(msg)
+		}, shared.ErrAbortCancelled},
+		{"interrupt", func(cp *ControlProtocol) { cp.abortInflight(shared.ErrAbortInterrupted) }, shared.ErrAbortInterrupted},
+		{"close", func(cp *ControlProtocol) { _ = cp.Close() }, shared.ErrAbortDisconnected},
+		{"reader exit", func(cp *ControlProtocol) { cp.FailPendingRequests(errors.New("EOF")) }, shared.ErrAbortDisconnected},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			started := make(chan struct{})
+			reasons := make(chan error, 1)
+			hp := NewHookProcessor(context.Background(), shared.NewOptions())
+			callbackID := hp.generateCallbackID()
+			hp.hookCallbacks[callbackID] = func(input shared.HookInput, toolUseID *string, hookCtx shared.HookContext) (shared.HookJSONOutput, error) {
+				close(started)
+				<-hookCtx.Signal.Done()
+				if hookCtx.Context.Err() == nil {
+					t.Error("Signal fired before Context was done")
+				}
+				reasons <- hookCtx.Signal.Reason()
+				return nil, hookCtx.Context.Err()
+			}
+
+			cp := NewControlProtocol(context.Background(), hp, func([]byte) error { return nil }, nil)
+			req, _ := json.Marshal(map[string]any{
+				"type":       "control_request",
+				"request_id": "req_hook",
+				"request":    map[string]any{"subtype": "hook_callback", "callback_id": callbackID, "input": map[string]any{}},
+			})
+			if err := cp.handleControlRequest(req); err != nil {
+				t.Fatal(err)
+			}
+			<-started
+			tc.abort(cp)
+
+			select {
+			case reason := <-reasons:
+				if !errors.Is(reason, tc.want) {
+					t.Errorf("reason = %v, want %v", reason, tc.want)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("hook was not aborted")
+			}
+		})
+	}
+}
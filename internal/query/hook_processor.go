@@ -6,30 +6,38 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
 )
 
 // HookProcessor manages hook callbacks and processes control protocol messages.
 type HookProcessor struct {
-	// Hook callbacks indexed by event type
-	hooks map[shared.HookEvent][]shared.HookMatcher
+	// Registered hook matchers per event, in registration order
+	entries hookEntries
+
+	// Events whose hooks run through a dispatcher callback
+	dispatched map[shared.HookEvent]bool
 
 	// Map callback IDs to actual callback functions
 	hookCallbacks map[string]shared.HookCallback
 
-	// Precomputed matcher configs to preserve deterministic callback-ID binding.
-	matcherConfigs map[shared.HookEvent][]shared.HookMatcherConfig
+	// Map callback IDs to their matcher's Timeout
+	hookTimeouts map[string]time.Duration
 
 	// Tool permission callback
 	canUseTool shared.CanUseToolCallback
 
-	// Counter for generating callback IDs
+	// Counters for generating callback IDs and matcher handles
 	nextCallbackID int64
+	nextHandle     int64
 
 	// Mutex for thread-safe access
 	mu sync.RWMutex
 
+	// Serializes runtime hook changes
+	updateMu sync.Mutex
+
 	// Context for cancellation
 	ctx context.Context
 }
@@ -37,15 +45,16 @@ type HookProcessor struct {
 // NewHookProcessor creates a new hook processor.
 func NewHookProcessor(ctx context.Context, options *shared.Options) *HookProcessor {
 	hp := &HookProcessor{
-		hooks:          make(map[shared.HookEvent][]shared.HookMatcher),
+		entries:        make(hookEntries),
+		dispatched:     make(map[shared.HookEvent]bool),
 		hookCallbacks:  make(map[string]shared.HookCallback),
-		matcherConfigs: make(map[shared.HookEvent][]shared.HookMatcherConfig),
+		hookTimeouts:   make(map[string]time.Duration),
 		nextCallbackID: 0,
 		ctx:            ctx,
 	}
 
 	// Load hooks from options
-	if options != nil && len(options.Hooks) > 0 {
+	if options != nil && (len(options.Hooks) > 0 || len(options.RuntimeHookEvents) > 0) {
 		hp.loadHooksFromOptions(options)
 	}
 
@@ -62,29 +71,16 @@ func (hp *HookProcessor) loadHooksFromOptions(options *shared.Options) {
 	hp.mu.Lock()
 	defer hp.mu.Unlock()
 
+	for _, event := range options.RuntimeHookEvents {
+		hp.dispatched[event] = true
+	}
+
 	for eventKey, matchers := range options.Hooks {
 		event := shared.HookEvent(eventKey)
 
 		for _, matcherAny := range matchers {
 			if matcher, ok := matcherAny.(shared.HookMatcher); ok {
-				hp.hooks[event] = append(hp.hooks[event], matcher)
-				callbackIDs := make([]string, 0, len(matcher.Hooks))
-
-				// Register callbacks
-				for _, callback := range matcher.Hooks {
-					callbackID := hp.generateCallbackID()
-					hp.hookCallbacks[callbackID] = callback
-					callbackIDs = append(callbackIDs, callbackID)
-				}
-
-				cfg := shared.HookMatcherConfig{
-					Matcher:         matcher.Matcher,
-					HookCallbackIDs: callbackIDs,
-				}
-				if matcher.Timeout != nil {
-					cfg.Timeout = matcher.Timeout
-				}
-				hp.matcherConfigs[event] = append(hp.matcherConfigs[event], cfg)
+				hp.entries[event] = append(hp.entries[event], hp.newEntryLocked(matcher))
 			}
 		}
 	}
@@ -97,48 +93,92 @@ func (hp *HookProcessor) generateCallbackID() string {
 }
 
 // BuildInitializeConfig builds the hooks configuration for CLI initialization.
+// Dispatched events are registered as a single catch-all dispatcher callback.
 func (hp *HookProcessor) BuildInitializeConfig() map[string][]shared.HookMatcherConfig {
 	hp.mu.RLock()
 	defer hp.mu.RUnlock()
 
-	if len(hp.matcherConfigs) == 0 {
-		return nil
-	}
-
 	config := make(map[string][]shared.HookMatcherConfig)
 
-	for event, matchers := range hp.matcherConfigs {
-		eventKey := string(event)
-		if len(matchers) > 0 {
-			out := make([]shared.HookMatcherConfig, len(matchers))
-			copy(out, matchers)
-			config[eventKey] = out
+	for event, entries := range hp.entries {
+		if hp.dispatched[event] || len(entries) == 0 {
+			continue
 		}
+		matchers := make([]shared.HookMatcherConfig, len(entries))
+		for i, entry := range entries {
+			matchers[i] = entry.config()
+		}
+		config[string(event)] = matchers
+	}
+	for event := range hp.dispatched {
+		config[string(event)] = []shared.HookMatcherConfig{{
+			HookCallbackIDs: []string{dispatcherCallbackID(event)},
+		}}
 	}
 
+	if len(config) == 0 {
+		return nil
+	}
 	return config
 }
 
-// ProcessHookCallback processes a hook callback request from CLI.
+// ProcessHookCallback processes a hook callback request from CLI under the
+// processor-wide context.
+//
+// Deprecated: use ProcessHookCallbackCtx so the hook observes cancellation
+// of its own request.
 func (hp *HookProcessor) ProcessHookCallback(
 	request *shared.HookCallbackRequest,
 ) (shared.HookJSONOutput, error) {
+	return hp.ProcessHookCallbackCtx(hp.ctx, request)
+}
+
+// ProcessHookCallbackCtx processes a hook callback request from CLI. The
+// hook receives a context derived from ctx, additionally bounded by its
+// matcher's Timeout, and an AbortSignal reporting why it was aborted.
+func (hp *HookProcessor) ProcessHookCallbackCtx(
+	ctx context.Context,
+	request *shared.HookCallbackRequest,
+) (shared.HookJSONOutput, error) {
+	if event, ok := dispatcherEvent(request.CallbackID); ok {
+		return hp.dispatch(ctx, event, request)
+	}
+
 	hp.mu.RLock()
 	callback, exists := hp.hookCallbacks[request.CallbackID]
+	timeout := hp.hookTimeouts[request.CallbackID]
 	hp.mu.RUnlock()
 
 	if !exists {
 		return nil, fmt.Errorf("no hook callback found for ID: %s", request.CallbackID)
 	}
 
+	return runHookCallback(ctx, callback, timeout, request.Input, request.ToolUseID)
+}
+
+// runHookCallback calls one hook under ctx, bounded by timeout when set.
+func runHookCallback(
+	ctx context.Context,
+	callback shared.HookCallback,
+	timeout time.Duration,
+	input shared.HookInput,
+	toolUseID *string,
+) (shared.HookJSONOutput, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout,
+			fmt.Errorf("%w after %s", shared.ErrAbortTimeout, timeout))
+		defer cancel()
+	}
+
 	// Prepare hook context
 	hookCtx := shared.HookContext{
-		Context: hp.ctx,
-		Signal:  nil, // TODO: Add abort signal support
+		Context: ctx,
+		Signal:  shared.NewAbortSignal(ctx),
 	}
 
 	// Call the user's hook callback
-	output, err := callback(request.Input, request.ToolUseID, hookCtx)
+	output, err := callback(input, toolUseID, hookCtx)
 	if err != nil {
 		return nil, fmt.Errorf("hook callback error: %w", err)
 	}
@@ -146,18 +186,34 @@ func (hp *HookProcessor) ProcessHookCallback(
 	return output, nil
 }
 
-// ProcessCanUseTool processes a tool permission request from CLI.
+// ProcessCanUseTool processes a tool permission request from CLI under the
+// processor-wide context.
+//
+// Deprecated: use ProcessCanUseToolCtx so the callback observes
+// cancellation of its own request.
 func (hp *HookProcessor) ProcessCanUseTool(
 	request *shared.CanUseToolRequest,
 ) (*shared.PermissionResponse, error) {
-	if hp.canUseTool == nil {
+	return hp.ProcessCanUseToolCtx(hp.ctx, request)
+}
+
+// ProcessCanUseToolCtx processes a tool permission request from CLI. The
+// callback receives ctx and an AbortSignal reporting why it was aborted.
+func (hp *HookProcessor) ProcessCanUseToolCtx(
+	ctx context.Context,
+	request *shared.CanUseToolRequest,
+) (*shared.PermissionResponse, error) {
+	hp.mu.RLock()
+	canUseTool := hp.canUseTool
+	hp.mu.RUnlock()
+	if canUseTool == nil {
 		return nil, fmt.Errorf("canUseTool callback is not provided")
 	}
 
 	// Prepare permission context
 	permCtx := shared.ToolPermissionContext{
-		Context:        hp.ctx,
-		Signal:         nil, // TODO: Add abort signal support
+		Context:        ctx,
+		Signal:         shared.NewAbortSignal(ctx),
 		Suggestions:    convertPermissionSuggestions(request.PermissionSuggestions),
 		ToolUseID:      request.ToolUseID,
 		AgentID:        request.AgentID,
@@ -169,7 +225,7 @@ func (hp *HookProcessor) ProcessCanUseTool(
 	}
 
 	// Call the permission callback
-	result, err := hp.canUseTool(request.ToolName, request.Input, permCtx)
+	result, err := canUseTool(request.ToolName, request.Input, permCtx)
 	if err != nil {
 		return nil, fmt.Errorf("permission callback error: %w", err)
 	}
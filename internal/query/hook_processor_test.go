@@ -2,7 +2,9 @@ package query
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
 )
@@ -394,3 +396,51 @@ func TestHookProcessor_BuildInitializeConfig_DoesNotCrossBindSameCallbackAcrossM
 		t.Fatalf("expected distinct callback IDs per matcher, got shared id %q", preToolUse[0].HookCallbackIDs[0])
 	}
 }
+
+func TestHookProcessor_MatcherTimeoutAbortsHook(t *testing.T) {
+	timeout := 0.05
+	var signal *shared.AbortSignal
+	hook := func(input shared.HookInput, toolUseID *string, hookCtx shared.HookContext) (shared.HookJSONOutput, error) {
+		signal = hookCtx.Signal
+		if signal.Aborted() {
+			t.Error("signal aborted before timeout")
+		}
+		<-hookCtx.Context.Done()
+		return shared.HookJSONOutput{}, nil
+	}
+	options := shared.NewOptions()
+	options.Hooks = map[string][]any{
+		string(shared.HookEventPreToolUse): {shared.HookMatcher{Hooks: []shared.HookCallback{hook}, Timeout: &timeout}},
+	}
+	hp := NewHookProcessor(context.Background(), options)
+
+	start := time.Now()
+	if _, err := hp.ProcessHookCallbackCtx(context.Background(), &shared.HookCallbackRequest{CallbackID: "hook_0"}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("hook ran %s, want ~50ms", elapsed)
+	}
+	if !signal.Aborted() || !errors.Is(signal.Reason(), shared.ErrAbortTimeout) {
+		t.Errorf("reason = %v, want ErrAbortTimeout", signal.Reason())
+	}
+}
+
+func TestHookProcessor_CanUseToolReceivesSignal(t *testing.T) {
+	options := shared.NewOptions()
+	options.CanUseTool = func(toolName string, input map[string]any, permCtx shared.ToolPermissionContext) (shared.PermissionResult, error) {
+		<-permCtx.Signal.Done()
+		return &shared.PermissionResultDeny{Message: permCtx.Signal.Reason().Error()}, nil
+	}
+	hp := NewHookProcessor(context.Background(), options)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(shared.ErrAbortInterrupted)
+	response, err := hp.ProcessCanUseToolCtx(ctx, &shared.CanUseToolRequest{ToolName: "Bash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Message != shared.ErrAbortInterrupted.Error() {
+		t.Errorf("message = %q", response.Message)
+	}
+}
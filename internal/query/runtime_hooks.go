@@ -0,0 +1,391 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// dispatcherPrefix marks the callback ID registered for a dispatched event.
+const dispatcherPrefix = "dispatch_"
+
+// hookEntry is one registered matcher and the callback IDs of its hooks.
+type hookEntry struct {
+	handle      shared.HookHandle
+	matcher     shared.HookMatcher
+	callbackIDs []string
+}
+
+// config returns the entry as sent to the CLI.
+func (e *hookEntry) config() shared.HookMatcherConfig {
+	return shared.HookMatcherConfig{
+		Matcher:         e.matcher.Matcher,
+		HookCallbackIDs: append([]string{}, e.callbackIDs...),
+		Timeout:         e.matcher.Timeout,
+	}
+}
+
+// timeout returns the matcher's Timeout as a duration, or zero if unset.
+func (e *hookEntry) timeout() time.Duration {
+	if e.matcher.Timeout != nil && *e.matcher.Timeout > 0 {
+		return time.Duration(*e.matcher.Timeout * float64(time.Second))
+	}
+	return 0
+}
+
+// hookEntries holds the registered matchers of each event in order.
+type hookEntries map[shared.HookEvent][]*hookEntry
+
+// clone copies the per-event slices so a snapshot survives later edits.
+func (h hookEntries) clone() hookEntries {
+	out := make(hookEntries, len(h))
+	for event, entries := range h {
+		out[event] = append([]*hookEntry(nil), entries...)
+	}
+	return out
+}
+
+// HookRegisterFunc re-registers hooks with the CLI. It receives the complete
+// configuration BuildInitializeConfig would now return.
+type HookRegisterFunc func(config map[string][]shared.HookMatcherConfig) error
+
+func dispatcherCallbackID(event shared.HookEvent) string {
+	return dispatcherPrefix + string(event)
+}
+
+func dispatcherEvent(callbackID string) (shared.HookEvent, bool) {
+	event, ok := strings.CutPrefix(callbackID, dispatcherPrefix)
+	return shared.HookEvent(event), ok
+}
+
+// newEntryLocked registers the callbacks of matcher under fresh callback
+// IDs. hp.mu must be held for writing.
+func (hp *HookProcessor) newEntryLocked(matcher shared.HookMatcher) *hookEntry {
+	entry := &hookEntry{
+		handle:      shared.HookHandle(fmt.Sprintf("hookmatcher_%d", atomic.AddInt64(&hp.nextHandle, 1))),
+		matcher:     matcher,
+		callbackIDs: make([]string, 0, len(matcher.Hooks)),
+	}
+	for _, callback := range matcher.Hooks {
+		callbackID := hp.generateCallbackID()
+		hp.hookCallbacks[callbackID] = callback
+		if timeout := entry.timeout(); timeout > 0 {
+			hp.hookTimeouts[callbackID] = timeout
+		}
+		entry.callbackIDs = append(entry.callbackIDs, callbackID)
+	}
+	return entry
+}
+
+// IsDispatched reports whether event's hooks run through the dispatcher
+// callback, so changing them needs no re-registration with the CLI.
+func (hp *HookProcessor) IsDispatched(event shared.HookEvent) bool {
+	hp.mu.RLock()
+	defer hp.mu.RUnlock()
+	return hp.dispatched[event]
+}
+
+// HookHandles returns the handles of event's matchers in registration
+// order.
+func (hp *HookProcessor) HookHandles(event shared.HookEvent) []shared.HookHandle {
+	hp.mu.RLock()
+	defer hp.mu.RUnlock()
+	handles := make([]shared.HookHandle, len(hp.entries[event]))
+	for i, entry := range hp.entries[event] {
+		handles[i] = entry.handle
+	}
+	return handles
+}
+
+// AddHook appends matcher to event's hooks and returns its handle. When
+// event is not dispatched, register is called with the new configuration and
+// the change is rolled back if it fails.
+func (hp *HookProcessor) AddHook(event shared.HookEvent, matcher shared.HookMatcher, register HookRegisterFunc) (shared.HookHandle, error) {
+	var handle shared.HookHandle
+	err := hp.update(event, register, func() error {
+		entry := hp.newEntryLocked(matcher)
+		hp.entries[event] = append(hp.entries[event], entry)
+		handle = entry.handle
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return handle, nil
+}
+
+// RemoveHook removes the matcher registered under handle and returns its
+// event.
+func (hp *HookProcessor) RemoveHook(handle shared.HookHandle, register HookRegisterFunc) (shared.HookEvent, error) {
+	event, ok := hp.eventOf(handle)
+	if !ok {
+		return "", fmt.Errorf("no hook matcher registered with handle %q", handle)
+	}
+	err := hp.update(event, register, func() error {
+		entries := hp.entries[event]
+		for i, entry := range entries {
+			if entry.handle == handle {
+				hp.entries[event] = append(entries[:i:i], entries[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("no hook matcher registered with handle %q", handle)
+	})
+	return event, err
+}
+
+// ReplaceHooks replaces all of event's matchers, including those set by
+// options, with matchers and returns their handles.
+func (hp *HookProcessor) ReplaceHooks(event shared.HookEvent, matchers []shared.HookMatcher, register HookRegisterFunc) ([]shared.HookHandle, error) {
+	var handles []shared.HookHandle
+	err := hp.update(event, register, func() error {
+		entries := make([]*hookEntry, len(matchers))
+		handles = make([]shared.HookHandle, len(matchers))
+		for i, matcher := range matchers {
+			entries[i] = hp.newEntryLocked(matcher)
+			handles[i] = entries[i].handle
+		}
+		hp.entries[event] = entries
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return handles, nil
+}
+
+func (hp *HookProcessor) eventOf(handle shared.HookHandle) (shared.HookEvent, bool) {
+	hp.mu.RLock()
+	defer hp.mu.RUnlock()
+	for event, entries := range hp.entries {
+		for _, entry := range entries {
+			if entry.handle == handle {
+				return event, true
+			}
+		}
+	}
+	return "", false
+}
+
+// update applies mutate and, for events that are not dispatched, registers the
+// result with the CLI, restoring the previous hooks if that fails. Updates
+// are serialized so a failed registration never rolls back a later change.
+func (hp *HookProcessor) update(event shared.HookEvent, register HookRegisterFunc, mutate func() error) error {
+	hp.updateMu.Lock()
+	defer hp.updateMu.Unlock()
+
+	hp.mu.Lock()
+	snapshot := hp.entries.clone()
+	if err := mutate(); err != nil {
+		hp.mu.Unlock()
+		return err
+	}
+	needsSync := register != nil && !hp.dispatched[event]
+	hp.mu.Unlock()
+
+	var err error
+	if needsSync {
+		if err = register(hp.BuildInitializeConfig()); err != nil {
+			hp.mu.Lock()
+			hp.entries = snapshot
+			hp.mu.Unlock()
+		}
+	}
+
+	// Callbacks are dropped only once the CLI no longer references them.
+	hp.mu.Lock()
+	hp.pruneCallbacksLocked()
+	hp.mu.Unlock()
+	return err
+}
+
+// pruneCallbacksLocked forgets callbacks no registered matcher refers to.
+func (hp *HookProcessor) pruneCallbacksLocked() {
+	live := make(map[string]bool, len(hp.hookCallbacks))
+	for _, entries := range hp.entries {
+		for _, entry := range entries {
+			for _, callbackID := range entry.callbackIDs {
+				live[callbackID] = true
+			}
+		}
+	}
+	for callbackID := range hp.hookCallbacks {
+		if !live[callbackID] {
+			delete(hp.hookCallbacks, callbackID)
+			delete(hp.hookTimeouts, callbackID)
+		}
+	}
+}
+
+// dispatch runs the hooks of a dispatched event whose matcher matches the
+// input, concurrently as the CLI does, and merges their outputs in
+// registration order. Failed hooks are skipped unless every hook failed.
+func (hp *HookProcessor) dispatch(
+	ctx context.Context,
+	event shared.HookEvent,
+	request *shared.HookCallbackRequest,
+) (shared.HookJSONOutput, error) {
+	type call struct {
+		callback shared.HookCallback
+		timeout  time.Duration
+	}
+
+	input, _ := request.Input.(map[string]any)
+	value, filtered := shared.HookMatchValue(event, input)
+
+	hp.mu.RLock()
+	var calls []call
+	for _, entry := range hp.entries[event] {
+		if filtered && !shared.HookMatcherMatches(entry.matcher.Matcher, value) {
+			continue
+		}
+		for _, callbackID := range entry.callbackIDs {
+			calls = append(calls, call{hp.hookCallbacks[callbackID], hp.hookTimeouts[callbackID]})
+		}
+	}
+	hp.mu.RUnlock()
+
+	if len(calls) == 0 {
+		return shared.HookJSONOutput{}, nil
+	}
+
+	outputs := make([]shared.HookJSONOutput, len(calls))
+	errs := make([]error, len(calls))
+	var wg sync.WaitGroup
+	for i, c := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outputs[i], errs[i] = runHookCallback(ctx, c.callback, c.timeout, request.Input, request.ToolUseID)
+		}()
+	}
+	wg.Wait()
+
+	var succeeded []shared.HookJSONOutput
+	for i, err := range errs {
+		if err == nil {
+			succeeded = append(succeeded, outputs[i])
+		}
+	}
+	if len(succeeded) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return mergeHookOutputs(succeeded), nil
+}
+
+// permissionDecisionRank orders PreToolUse decisions the way the CLI
+// combines them: any deny wins, then defer, then ask, then allow.
+var permissionDecisionRank = map[string]int{
+	shared.PermissionDecisionAllow: 1,
+	shared.PermissionDecisionAsk:   2,
+	shared.PermissionDecisionDefer: 3,
+	shared.PermissionDecisionDeny:  4,
+}
+
+// mergeHookOutputs combines the outputs of several hooks into the one the
+// dispatcher returns. The first hook to stop the run or block wins, the
+// strongest permission decision wins with its reason and updated input,
+// and messages and additional context are joined.
+func mergeHookOutputs(outputs []shared.HookJSONOutput) shared.HookJSONOutput {
+	if len(outputs) == 1 {
+		return outputs[0]
+	}
+
+	merged := shared.HookJSONOutput{}
+	specific := map[string]any{}
+	var messages, contexts []string
+	decisionRank := 0
+
+	for _, output := range outputs {
+		for key, value := range output {
+			switch key {
+			case "continue":
+				if merged["continue"] == false {
+					break
+				}
+				merged["continue"] = value
+				if value == false {
+					if reason, ok := output["stopReason"]; ok {
+						merged["stopReason"] = reason
+					}
+				}
+			case "stopReason":
+			case "systemMessage":
+				if text, ok := value.(string); ok && text != "" {
+					messages = append(messages, text)
+				}
+			case "decision", "reason":
+				if _, set := merged["decision"]; !set && output["decision"] != nil {
+					merged["decision"] = output["decision"]
+					if reason, ok := output["reason"]; ok {
+						merged["reason"] = reason
+					}
+				}
+			case "hookSpecificOutput":
+				mergeHookSpecificOutput(specific, value, &decisionRank, &contexts)
+			default:
+				if _, set := merged[key]; !set {
+					merged[key] = value
+				}
+			}
+		}
+	}
+
+	if len(messages) > 0 {
+		merged["systemMessage"] = strings.Join(messages, "\n")
+	}
+	if len(contexts) > 0 {
+		specific["additionalContext"] = strings.Join(contexts, "\n")
+	}
+	if len(specific) > 0 {
+		merged["hookSpecificOutput"] = specific
+	}
+	return merged
+}
+
+func mergeHookSpecificOutput(merged map[string]any, value any, decisionRank *int, contexts *[]string) {
+	output, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	if rank := permissionDecisionRank[stringValue(output["permissionDecision"])]; rank > *decisionRank {
+		*decisionRank = rank
+		delete(merged, "permissionDecisionReason")
+		delete(merged, "updatedInput")
+		for _, key := range []string{"permissionDecision", "permissionDecisionReason", "updatedInput"} {
+			if v, ok := output[key]; ok {
+				merged[key] = v
+			}
+		}
+	}
+	if decision, ok := output["decision"].(map[string]any); ok {
+		current, _ := merged["decision"].(map[string]any)
+		if current == nil || (current["behavior"] != shared.PermissionDecisionDeny && decision["behavior"] == shared.PermissionDecisionDeny) {
+			merged["decision"] = decision
+		}
+	}
+	for key, v := range output {
+		switch key {
+		case "permissionDecision", "permissionDecisionReason", "updatedInput", "decision":
+		case "additionalContext":
+			if text, ok := v.(string); ok && text != "" {
+				*contexts = append(*contexts, text)
+			}
+		default:
+			if _, set := merged[key]; !set {
+				merged[key] = v
+			}
+		}
+	}
+}
+
+func stringValue(v any) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,196 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+func decideHook(decision, reason string) shared.HookCallback {
+	return func(shared.HookInput, *string, shared.HookContext) (shared.HookJSONOutput, error) {
+		return shared.HookJSONOutput{"hookSpecificOutput": map[string]any{
+			"hookEventName":            "PreToolUse",
+			"permissionDecision":       decision,
+			"permissionDecisionReason": reason,
+		}}, nil
+	}
+}
+
+func preToolUse(hp *HookProcessor, toolName string) (shared.HookJSONOutput, error) {
+	return hp.ProcessHookCallbackCtx(context.Background(), &shared.HookCallbackRequest{
+		CallbackID: dispatcherCallbackID(shared.HookEventPreToolUse),
+		Input:      map[string]any{"hook_event_name": "PreToolUse", "tool_name": toolName},
+	})
+}
+
+func permissionDecision(output shared.HookJSONOutput) string {
+	specific, _ := output["hookSpecificOutput"].(map[string]any)
+	decision, _ := specific["permissionDecision"].(string)
+	return decision
+}
+
+func TestRuntimeHooksDispatched(t *testing.T) {
+	bash := "Bash"
+	hp := NewHookProcessor(context.Background(), &shared.Options{
+		Hooks: map[string][]any{
+			"PreToolUse": {shared.HookMatcher{Matcher: &bash, Hooks: []shared.HookCallback{decideHook("allow", "")}}},
+		},
+		RuntimeHookEvents: []shared.HookEvent{shared.HookEventPreToolUse},
+	})
+
+	config := hp.BuildInitializeConfig()
+	if matchers := config["PreToolUse"]; len(matchers) != 1 || matchers[0].Matcher != nil ||
+		len(matchers[0].HookCallbackIDs) != 1 || matchers[0].HookCallbackIDs[0] != "dispatch_PreToolUse" {
+		t.Fatalf("expected a catch-all dispatcher, got %+v", config)
+	}
+
+	failRegister := func(map[string][]shared.HookMatcherConfig) error {
+		t.Fatal("dispatched events must not be re-registered")
+		return nil
+	}
+	handle, err := hp.AddHook(shared.HookEventPreToolUse, shared.HookMatcher{
+		Matcher: &bash,
+		Hooks:   []shared.HookCallback{decideHook("deny", "no shell")},
+	}, failRegister)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := preToolUse(hp, "Bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	specific := output["hookSpecificOutput"].(map[string]any)
+	if specific["permissionDecision"] != "deny" || specific["permissionDecisionReason"] != "no shell" {
+		t.Fatalf("expected the added deny to win, got %v", output)
+	}
+	if output, _ := preToolUse(hp, "Read"); len(output) != 0 {
+		t.Fatalf("expected no hooks to match Read, got %v", output)
+	}
+
+	if _, err := hp.RemoveHook(handle, failRegister); err != nil {
+		t.Fatal(err)
+	}
+	if output, _ := preToolUse(hp, "Bash"); permissionDecision(output) != "allow" {
+		t.Fatalf("expected the option hook alone after removal, got %v", output)
+	}
+	if _, err := hp.RemoveHook(handle, failRegister); err == nil {
+		t.Fatal("expected removing twice to fail")
+	}
+
+	if _, err := hp.ReplaceHooks(shared.HookEventPreToolUse, nil, failRegister); err != nil {
+		t.Fatal(err)
+	}
+	if output, _ := preToolUse(hp, "Bash"); len(output) != 0 {
+		t.Fatalf("expected no hooks after replacing with none, got %v", output)
+	}
+	if len(hp.hookCallbacks) != 0 {
+		t.Fatalf("expected removed callbacks to be pruned, %d remain", len(hp.hookCallbacks))
+	}
+}
+
+func TestDispatchSkipsFailedHooks(t *testing.T) {
+	hp := NewHookProcessor(context.Background(), &shared.Options{
+		RuntimeHookEvents: []shared.HookEvent{shared.HookEventPreToolUse},
+	})
+	failing := func(shared.HookInput, *string, shared.HookContext) (shared.HookJSONOutput, error) {
+		return nil, errors.New("boom")
+	}
+	_, _ = hp.AddHook(shared.HookEventPreToolUse, shared.HookMatcher{Hooks: []shared.HookCallback{failing}}, nil)
+
+	if _, err := preToolUse(hp, "Bash"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected the only hook's error, got %v", err)
+	}
+
+	_, _ = hp.AddHook(shared.HookEventPreToolUse, shared.HookMatcher{Hooks: []shared.HookCallback{decideHook("ask", "")}}, nil)
+	if output, err := preToolUse(hp, "Bash"); err != nil || permissionDecision(output) != "ask" {
+		t.Fatalf("expected the failed hook to be skipped, got %v, %v", output, err)
+	}
+}
+
+func TestMergeHookOutputs(t *testing.T) {
+	merged := mergeHookOutputs([]shared.HookJSONOutput{
+		{"systemMessage": "one", "hookSpecificOutput": map[string]any{
+			"permissionDecision": "ask", "permissionDecisionReason": "check", "additionalContext": "a",
+		}},
+		{"continue": false, "stopReason": "first", "decision": "block", "reason": "r1"},
+		{"continue": false, "stopReason": "second", "systemMessage": "two", "hookSpecificOutput": map[string]any{
+			"permissionDecision": "deny", "permissionDecisionReason": "blocked", "updatedInput": map[string]any{"x": 1}, "additionalContext": "b",
+		}},
+		{"decision": "approve", "hookSpecificOutput": map[string]any{"permissionDecision": "allow"}},
+	})
+
+	got, _ := json.Marshal(merged)
+	want := `{"continue":false,"decision":"block","hookSpecificOutput":{"additionalContext":"a\nb","permissionDecision":"deny",` +
+		`"permissionDecisionReason":"blocked","updatedInput":{"x":1}},"reason":"r1","stopReason":"first","systemMessage":"one\ntwo"}`
+	if string(got) != want {
+		t.Fatalf("unexpected merge\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestControlProtocolSetHooks(t *testing.T) {
+	var (
+		cp       *ControlProtocol
+		sent     []map[string]any
+		rejected bool
+	)
+	writeFn := func(data []byte) error {
+		var req shared.ControlRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return err
+		}
+		var envelope struct {
+			Request map[string]any `json:"request"`
+		}
+		_ = json.Unmarshal(data, &envelope)
+		sent = append(sent, envelope.Request)
+
+		payload := shared.ResponsePayload{Subtype: shared.ControlSubtypeSuccess, RequestID: req.RequestID}
+		if rejected {
+			payload = shared.ResponsePayload{Subtype: shared.ControlSubtypeError, RequestID: req.RequestID, Error: "unknown subtype"}
+		}
+		resp, _ := json.Marshal(shared.ControlResponse{Type: shared.ControlTypeResponse, Response: payload})
+		return cp.handleControlResponse(resp)
+	}
+	cp = NewControlProtocol(context.Background(), nil, writeFn, nil)
+
+	handle, err := cp.AddHook(shared.HookEventPreToolUse, shared.HookMatcher{
+		Hooks: []shared.HookCallback{decideHook("deny", "")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0]["subtype"] != shared.ControlSubtypeSetHooks {
+		t.Fatalf("expected a set_hooks request, got %v", sent)
+	}
+	matchers, _ := sent[0]["hooks"].(map[string]any)["PreToolUse"].([]any)
+	if len(matchers) != 1 {
+		t.Fatalf("expected the new matcher to be registered, got %v", sent[0])
+	}
+	callbackID := matchers[0].(map[string]any)["hookCallbackIds"].([]any)[0].(string)
+	output, err := cp.handleHookCallback(context.Background(), map[string]any{"callback_id": callbackID, "input": map[string]any{}})
+	if err != nil || permissionDecision(output) != "deny" {
+		t.Fatalf("expected the registered callback to run, got %v, %v", output, err)
+	}
+
+	rejected = true
+	if _, err := cp.AddHook(shared.HookEventPostToolUse, shared.HookMatcher{}); err == nil || !strings.Contains(err.Error(), "WithRuntimeHooks") {
+		t.Fatalf("expected a rejected set_hooks to fail, got %v", err)
+	}
+	if config := cp.hooks().BuildInitializeConfig(); len(config) != 1 || len(config["PreToolUse"]) != 1 {
+		t.Fatalf("expected the rejected change to be rolled back, got %+v", config)
+	}
+	if err := cp.RemoveHook(handle); err == nil || !strings.Contains(err.Error(), "unknown subtype") {
+		t.Fatalf("expected removal to fail while the CLI rejects set_hooks, got %v", err)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("expected no set_hooks request after a rejection, got %d requests", len(sent))
+	}
+	if handles := cp.hooks().HookHandles(shared.HookEventPreToolUse); len(handles) != 1 || handles[0] != handle {
+		t.Fatalf("expected the matcher to survive a rejected removal, got %v", handles)
+	}
+}
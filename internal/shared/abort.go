@@ -0,0 +1,55 @@
+package shared
+
+import (
+	"context"
+	"errors"
+)
+
+// Abort reasons reported by AbortSignal.Reason. Reasons may wrap one of
+// these with more detail, so compare with errors.Is.
+var (
+	// ErrAbortCancelled means the CLI withdrew the request with a
+	// control_cancel_request.
+	ErrAbortCancelled = errors.New("cancelled by CLI")
+	// ErrAbortTimeout means the HookMatcher's Timeout elapsed.
+	ErrAbortTimeout = errors.New("hook timed out")
+	// ErrAbortInterrupted means the client interrupted the current turn.
+	ErrAbortInterrupted = errors.New("interrupted")
+	// ErrAbortDisconnected means the client closed or the CLI went away.
+	ErrAbortDisconnected = errors.New("disconnected")
+)
+
+// AbortSignal tells a hook or permission callback that its invocation was
+// aborted and why. It fires together with the invocation's Context; use
+// whichever is more convenient. A nil *AbortSignal never fires.
+type AbortSignal struct {
+	ctx context.Context
+}
+
+// NewAbortSignal returns a signal that fires when ctx is done, with
+// context.Cause(ctx) as its reason.
+func NewAbortSignal(ctx context.Context) *AbortSignal {
+	return &AbortSignal{ctx: ctx}
+}
+
+// Done returns a channel that is closed when the invocation is aborted.
+func (s *AbortSignal) Done() <-chan struct{} {
+	if s == nil || s.ctx == nil {
+		return nil
+	}
+	return s.ctx.Done()
+}
+
+// Aborted reports whether the invocation has been aborted.
+func (s *AbortSignal) Aborted() bool {
+	return s.Reason() != nil
+}
+
+// Reason returns why the invocation was aborted (see ErrAbortCancelled and
+// friends), or nil if it has not been.
+func (s *AbortSignal) Reason() error {
+	if s == nil || s.ctx == nil || s.ctx.Err() == nil {
+		return nil
+	}
+	return context.Cause(s.ctx)
+}
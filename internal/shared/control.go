@@ -26,6 +26,7 @@ const (
 	ControlSubtypeSetPermissionMode = "set_permission_mode"
 	ControlSubtypeSetModel          = "set_model"
 	ControlSubtypeInterrupt         = "interrupt"
+	ControlSubtypeSetHooks          = "set_hooks"
 )
 
 // Control response subtypes
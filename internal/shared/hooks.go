@@ -2,6 +2,10 @@ package shared
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
 )
 
 // HookEvent represents the type of hook event.
@@ -18,8 +22,44 @@ const (
 	HookEventNotification       HookEvent = "Notification"
 	HookEventSubagentStart      HookEvent = "SubagentStart"
 	HookEventPermissionRequest  HookEvent = "PermissionRequest"
+	HookEventSessionStart       HookEvent = "SessionStart"
+	HookEventSessionEnd         HookEvent = "SessionEnd"
+	HookEventPostCompact        HookEvent = "PostCompact"
+	HookEventSetup              HookEvent = "Setup"
+	HookEventTeammateIdle       HookEvent = "TeammateIdle"
+	HookEventTaskCompleted      HookEvent = "TaskCompleted"
+	HookEventElicitation        HookEvent = "Elicitation"
+	HookEventElicitationResult  HookEvent = "ElicitationResult"
 )
 
+// HookEvents lists every hook event the CLI emits.
+var HookEvents = []HookEvent{
+	HookEventPreToolUse,
+	HookEventPostToolUse,
+	HookEventPostToolUseFailure,
+	HookEventUserPromptSubmit,
+	HookEventStop,
+	HookEventSubagentStop,
+	HookEventPreCompact,
+	HookEventNotification,
+	HookEventSubagentStart,
+	HookEventPermissionRequest,
+	HookEventSessionStart,
+	HookEventSessionEnd,
+	HookEventPostCompact,
+	HookEventSetup,
+	HookEventTeammateIdle,
+	HookEventTaskCompleted,
+	HookEventElicitation,
+	HookEventElicitationResult,
+}
+
+// IsKnownHookEvent reports whether name is one of HookEvents.
+func IsKnownHookEvent(name string) bool {
+	_, ok := hookInputFactories[HookEvent(name)]
+	return ok
+}
+
 // BaseHookInput contains common fields present across many hook events.
 type BaseHookInput struct {
 	SessionID      string `json:"session_id"`
@@ -124,10 +164,202 @@ type PermissionRequestHookInput struct {
 	AgentType             *string        `json:"agent_type,omitempty"`
 }
 
+// SessionStartHookInput represents input data for SessionStart hook events.
+type SessionStartHookInput struct {
+	BaseHookInput
+	HookEventName string  `json:"hook_event_name"`
+	Source        string  `json:"source"` // "startup", "resume", "clear" or "compact"
+	AgentType     *string `json:"agent_type,omitempty"`
+	Model         *string `json:"model,omitempty"`
+}
+
+// SessionEndHookInput represents input data for SessionEnd hook events.
+type SessionEndHookInput struct {
+	BaseHookInput
+	HookEventName string `json:"hook_event_name"`
+	Reason        string `json:"reason"` // "clear", "logout", "prompt_input_exit", "other", ...
+}
+
+// PostCompactHookInput represents input data for PostCompact hook events.
+type PostCompactHookInput struct {
+	BaseHookInput
+	HookEventName  string `json:"hook_event_name"`
+	Trigger        string `json:"trigger"` // "manual" or "auto"
+	CompactSummary string `json:"compact_summary"`
+}
+
+// SetupHookInput represents input data for Setup hook events.
+type SetupHookInput struct {
+	BaseHookInput
+	HookEventName string `json:"hook_event_name"`
+	Trigger       string `json:"trigger"` // "init" or "maintenance"
+}
+
+// TeammateIdleHookInput represents input data for TeammateIdle hook events.
+type TeammateIdleHookInput struct {
+	BaseHookInput
+	HookEventName string `json:"hook_event_name"`
+	TeammateName  string `json:"teammate_name"`
+	TeamName      string `json:"team_name"`
+}
+
+// TaskCompletedHookInput represents input data for TaskCompleted hook events.
+type TaskCompletedHookInput struct {
+	BaseHookInput
+	HookEventName   string  `json:"hook_event_name"`
+	TaskID          string  `json:"task_id"`
+	TaskSubject     string  `json:"task_subject"`
+	TaskDescription *string `json:"task_description,omitempty"`
+	TeammateName    *string `json:"teammate_name,omitempty"`
+	TeamName        *string `json:"team_name,omitempty"`
+}
+
+// ElicitationHookInput represents input data for Elicitation hook events,
+// fired when an MCP server asks the user for input.
+type ElicitationHookInput struct {
+	BaseHookInput
+	HookEventName   string         `json:"hook_event_name"`
+	McpServerName   string         `json:"mcp_server_name"`
+	Message         string         `json:"message"`
+	Mode            *string        `json:"mode,omitempty"` // "form" or "url"
+	URL             *string        `json:"url,omitempty"`
+	ElicitationID   *string        `json:"elicitation_id,omitempty"`
+	RequestedSchema map[string]any `json:"requested_schema,omitempty"`
+}
+
+// ElicitationResultHookInput represents input data for ElicitationResult
+// hook events, fired after the user answers an MCP elicitation.
+type ElicitationResultHookInput struct {
+	BaseHookInput
+	HookEventName string         `json:"hook_event_name"`
+	McpServerName string         `json:"mcp_server_name"`
+	ElicitationID *string        `json:"elicitation_id,omitempty"`
+	Mode          *string        `json:"mode,omitempty"`
+	Action        string         `json:"action"` // "accept", "decline" or "cancel"
+	Content       map[string]any `json:"content,omitempty"`
+}
+
 // HookInput is a union type for all hook inputs.
-// Use type assertion to access specific fields based on hook_event_name.
+// Use type assertion to access specific fields based on hook_event_name,
+// or DecodeHookInput to convert a raw input into its typed struct.
 type HookInput = any
 
+// hookInputFactories creates the typed input struct of each event.
+var hookInputFactories = map[HookEvent]func() any{
+	HookEventPreToolUse:         func() any { return &PreToolUseHookInput{} },
+	HookEventPostToolUse:        func() any { return &PostToolUseHookInput{} },
+	HookEventPostToolUseFailure: func() any { return &PostToolUseFailureHookInput{} },
+	HookEventUserPromptSubmit:   func() any { return &UserPromptSubmitHookInput{} },
+	HookEventStop:               func() any { return &StopHookInput{} },
+	HookEventSubagentStop:       func() any { return &SubagentStopHookInput{} },
+	HookEventPreCompact:         func() any { return &PreCompactHookInput{} },
+	HookEventNotification:       func() any { return &NotificationHookInput{} },
+	HookEventSubagentStart:      func() any { return &SubagentStartHookInput{} },
+	HookEventPermissionRequest:  func() any { return &PermissionRequestHookInput{} },
+	HookEventSessionStart:       func() any { return &SessionStartHookInput{} },
+	HookEventSessionEnd:         func() any { return &SessionEndHookInput{} },
+	HookEventPostCompact:        func() any { return &PostCompactHookInput{} },
+	HookEventSetup:              func() any { return &SetupHookInput{} },
+	HookEventTeammateIdle:       func() any { return &TeammateIdleHookInput{} },
+	HookEventTaskCompleted:      func() any { return &TaskCompletedHookInput{} },
+	HookEventElicitation:        func() any { return &ElicitationHookInput{} },
+	HookEventElicitationResult:  func() any { return &ElicitationResultHookInput{} },
+}
+
+// DecodeHookInput converts a raw hook input (as passed to a HookCallback)
+// into a pointer to its typed struct, chosen by hook_event_name, e.g.
+// *SessionStartHookInput. Unknown events return an error.
+func DecodeHookInput(input HookInput) (any, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode hook input: %w", err)
+	}
+	var envelope struct {
+		HookEventName string `json:"hook_event_name"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode hook input: %w", err)
+	}
+	factory, ok := hookInputFactories[HookEvent(envelope.HookEventName)]
+	if !ok {
+		return nil, fmt.Errorf("unknown hook event %q", envelope.HookEventName)
+	}
+	typed := factory()
+	if err := json.Unmarshal(data, typed); err != nil {
+		return nil, fmt.Errorf("failed to decode %s hook input: %w", envelope.HookEventName, err)
+	}
+	return typed, nil
+}
+
+// HookHandle identifies a hook matcher registered with a connected
+// client, for removing it later.
+type HookHandle string
+
+// DefaultRuntimeHookEvents are the events routed through a dispatcher when
+// runtime hooks are enabled without naming events.
+var DefaultRuntimeHookEvents = []HookEvent{
+	HookEventPreToolUse,
+	HookEventPostToolUse,
+	HookEventPostToolUseFailure,
+	HookEventPermissionRequest,
+	HookEventUserPromptSubmit,
+	HookEventStop,
+	HookEventSubagentStart,
+	HookEventSubagentStop,
+	HookEventNotification,
+	HookEventPreCompact,
+}
+
+// hookMatchFields is the input field each event's matcher is tested
+// against; matchers of events not listed always match.
+var hookMatchFields = map[HookEvent]string{
+	HookEventPreToolUse:         "tool_name",
+	HookEventPostToolUse:        "tool_name",
+	HookEventPostToolUseFailure: "tool_name",
+	HookEventPermissionRequest:  "tool_name",
+	HookEventSessionStart:       "source",
+	HookEventSessionEnd:         "reason",
+	HookEventPreCompact:         "trigger",
+	HookEventPostCompact:        "trigger",
+	HookEventSetup:              "trigger",
+	HookEventNotification:       "notification_type",
+	HookEventSubagentStart:      "agent_type",
+	HookEventSubagentStop:       "agent_type",
+}
+
+// HookMatchValue returns the input value the CLI tests an event's matchers
+// against (the tool name for tool events), or false for events whose
+// matchers always match.
+func HookMatchValue(event HookEvent, input map[string]any) (string, bool) {
+	field, ok := hookMatchFields[event]
+	if !ok {
+		return "", false
+	}
+	value, _ := input[field].(string)
+	return value, true
+}
+
+var simpleHookMatcher = regexp.MustCompile(`^[A-Za-z0-9_|]+$`)
+
+// HookMatcherMatches applies a matcher the way the CLI does: nil, empty or
+// "*" matches everything, a "|"-separated list of names matches exactly,
+// and anything else is an unanchored regular expression.
+func HookMatcherMatches(matcher *string, value string) bool {
+	if matcher == nil || *matcher == "" || *matcher == "*" {
+		return true
+	}
+	if simpleHookMatcher.MatchString(*matcher) {
+		for _, name := range strings.Split(*matcher, "|") {
+			if name == value {
+				return true
+			}
+		}
+		return false
+	}
+	re, err := regexp.Compile(*matcher)
+	return err == nil && re.MatchString(value)
+}
+
 // PreToolUseHookSpecificOutput represents hook-specific output for PreToolUse events.
 type PreToolUseHookSpecificOutput struct {
 	HookEventName string `json:"hookEventName"`
@@ -166,6 +398,29 @@ type SessionStartHookSpecificOutput struct {
 	AdditionalContext string `json:"additionalContext,omitempty"`
 }
 
+// SetupHookSpecificOutput represents hook-specific output for Setup events.
+type SetupHookSpecificOutput struct {
+	HookEventName     string `json:"hookEventName"`
+	AdditionalContext string `json:"additionalContext,omitempty"`
+}
+
+// ElicitationHookSpecificOutput represents hook-specific output for
+// Elicitation events. Setting Action answers the elicitation without
+// prompting the user.
+type ElicitationHookSpecificOutput struct {
+	HookEventName string         `json:"hookEventName"`
+	Action        string         `json:"action,omitempty"` // "accept", "decline" or "cancel"
+	Content       map[string]any `json:"content,omitempty"`
+}
+
+// ElicitationResultHookSpecificOutput represents hook-specific output for
+// ElicitationResult events. Action and Content override the user's answer.
+type ElicitationResultHookSpecificOutput struct {
+	HookEventName string         `json:"hookEventName"`
+	Action        string         `json:"action,omitempty"`
+	Content       map[string]any `json:"content,omitempty"`
+}
+
 // PostToolUseFailureHookSpecificOutput represents hook-specific output for PostToolUseFailure events.
 type PostToolUseFailureHookSpecificOutput struct {
 	HookEventName     string `json:"hookEventName"`
@@ -220,16 +475,20 @@ type SyncHookJSONOutput struct {
 type HookJSONOutput = map[string]any
 
 // HookContext provides context information for hook callbacks.
+//
+// Context is derived per invocation and is cancelled when the CLI cancels
+// the request, the matcher's Timeout elapses, the client interrupts or
+// disconnects. Signal fires at the same time and carries the reason.
 type HookContext struct {
 	Context context.Context
-	Signal  any // Future: abort signal support
+	Signal  *AbortSignal
 }
 
 // HookCallback is the function signature for hook callbacks.
 // Parameters:
 //   - input: Hook input data with discriminated unions based on hook_event_name
 //   - toolUseID: Optional tool use identifier
-//   - ctx: Hook context with abort signal support (currently placeholder)
+//   - ctx: Hook context with a per-invocation Context and abort Signal
 //
 // Returns:
 //   - HookJSONOutput: Hook output with control and decision fields
@@ -292,6 +551,56 @@ func NewPostToolUseOutput(additionalContext string) HookJSONOutput {
 	return output
 }
 
+// NewSessionStartOutput creates a SessionStart hook output that adds
+// context to the start of the session.
+func NewSessionStartOutput(additionalContext string) HookJSONOutput {
+	output := make(HookJSONOutput)
+
+	if additionalContext != "" {
+		hookSpecific := map[string]any{
+			"hookEventName":     "SessionStart",
+			"additionalContext": additionalContext,
+		}
+		output["hookSpecificOutput"] = hookSpecific
+	}
+
+	return output
+}
+
+// NewSetupOutput creates a Setup hook output with additional context.
+func NewSetupOutput(additionalContext string) HookJSONOutput {
+	output := make(HookJSONOutput)
+
+	if additionalContext != "" {
+		hookSpecific := map[string]any{
+			"hookEventName":     "Setup",
+			"additionalContext": additionalContext,
+		}
+		output["hookSpecificOutput"] = hookSpecific
+	}
+
+	return output
+}
+
+// NewElicitationOutput creates an Elicitation hook output that answers the
+// elicitation with action ("accept", "decline" or "cancel") and, for
+// accept, the form content.
+func NewElicitationOutput(action string, content map[string]any) HookJSONOutput {
+	output := make(HookJSONOutput)
+
+	hookSpecific := map[string]any{
+		"hookEventName": "Elicitation",
+		"action":        action,
+	}
+
+	if content != nil {
+		hookSpecific["content"] = content
+	}
+
+	output["hookSpecificOutput"] = hookSpecific
+	return output
+}
+
 // NewBlockingOutput creates a hook output that blocks execution.
 func NewBlockingOutput(systemMessage, reason string) HookJSONOutput {
 	output := make(HookJSONOutput)
@@ -0,0 +1,39 @@
+package shared
+
+import "testing"
+
+func TestHookMatcherMatches(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		matcher *string
+		value   string
+		want    bool
+	}{
+		{nil, "Bash", true},
+		{str("*"), "Bash", true},
+		{str("Bash"), "Bash", true},
+		{str("Bash"), "BashOutput", false},
+		{str("Edit|Write"), "Write", true},
+		{str("mcp__.*__get_.*"), "mcp__github__get_issue", true},
+		{str("Notebook.*"), "NotebookEdit", true},
+		{str("("), "Bash", false},
+	}
+	for _, tc := range tests {
+		if got := HookMatcherMatches(tc.matcher, tc.value); got != tc.want {
+			t.Errorf("matcher %v on %q: got %v, want %v", tc.matcher, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestHookMatchValue(t *testing.T) {
+	input := map[string]any{"tool_name": "Bash", "source": "resume"}
+	if value, ok := HookMatchValue(HookEventPreToolUse, input); !ok || value != "Bash" {
+		t.Fatalf("PreToolUse: got %q, %v", value, ok)
+	}
+	if value, ok := HookMatchValue(HookEventSessionStart, input); !ok || value != "resume" {
+		t.Fatalf("SessionStart: got %q, %v", value, ok)
+	}
+	if _, ok := HookMatchValue(HookEventStop, input); ok {
+		t.Fatal("expected Stop matchers to be unfiltered")
+	}
+}
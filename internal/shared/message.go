@@ -2,6 +2,7 @@ package shared
 
 import (
 	"encoding/json"
+	"strings"
 )
 
 // Message type constants
@@ -829,17 +830,74 @@ type TaskUpdatedMessage struct {
 // is true. It surfaces hook lifecycle events (PreToolUse, PostToolUse, Stop, etc.)
 // into the message stream.
 //
-// Wire format: {"type":"system","subtype":"hook_started"|"hook_response","hook_event":"PreToolUse",...}.
+// Wire format: {"type":"system","subtype":"hook_started"|"hook_progress"|"hook_response","hook_event":"PreToolUse",...}.
 // The Subtype distinguishes lifecycle phase ("hook_started" when a hook begins,
-// "hook_response" when it completes — the latter carries output, exit_code,
-// and outcome keys in Data).
+// "hook_progress" while it streams output, "hook_response" when it completes —
+// the latter carries output, exit_code, and outcome keys in Data).
 type HookEventMessage struct {
 	SystemMessage
 	HookEventName string  `json:"hook_event_name"`
+	HookID        *string `json:"hook_id,omitempty"`
+	HookName      *string `json:"hook_name,omitempty"` // e.g. "PreToolUse:Bash"
+	Outcome       *string `json:"outcome,omitempty"`   // hook_response only
+	ExitCode      *int    `json:"exit_code,omitempty"` // hook_response only
 	SessionID     *string `json:"session_id,omitempty"`
 	UUID          *string `json:"uuid,omitempty"`
 }
 
+// Event returns the hook event the message belongs to. Use
+// IsKnownHookEvent to check it against the events this SDK types.
+func (m *HookEventMessage) Event() HookEvent {
+	return HookEvent(m.HookEventName)
+}
+
+// IsHookEventSubtype reports whether a system message subtype is a hook
+// lifecycle event carried by HookEventMessage.
+func IsHookEventSubtype(subtype string) bool {
+	switch subtype {
+	case "hook_started", "hook_progress", "hook_response":
+		return true
+	}
+	return false
+}
+
+// NewHookEventMessage builds a HookEventMessage from a hook lifecycle system
+// message. The CLI has spelled the event key three ways across versions
+// (hook_event, hook_event_name, and the "Event:matcher" hook_name); all are
+// accepted.
+func NewHookEventMessage(subtype string, data map[string]any) *HookEventMessage {
+	msg := &HookEventMessage{
+		SystemMessage: SystemMessage{Subtype: subtype, Data: data},
+	}
+	if v, ok := data["hook_event"].(string); ok {
+		msg.HookEventName = v
+	} else if v, ok := data["hook_event_name"].(string); ok {
+		msg.HookEventName = v
+	} else if v, ok := data["hook_name"].(string); ok {
+		msg.HookEventName, _, _ = strings.Cut(v, ":")
+	}
+	if v, ok := data["hook_id"].(string); ok {
+		msg.HookID = &v
+	}
+	if v, ok := data["hook_name"].(string); ok {
+		msg.HookName = &v
+	}
+	if v, ok := data["outcome"].(string); ok {
+		msg.Outcome = &v
+	}
+	if v, ok := data["exit_code"].(float64); ok {
+		code := int(v)
+		msg.ExitCode = &code
+	}
+	if v, ok := data["session_id"].(string); ok {
+		msg.SessionID = &v
+	}
+	if v, ok := data["uuid"].(string); ok {
+		msg.UUID = &v
+	}
+	return msg
+}
+
 // MirrorErrorMessage is a system message emitted when a SessionStore.Append
 // call fails. Non-fatal — the local-disk transcript is already durable, so the
 // session continues unaffected. The mirrored copy in the external store will
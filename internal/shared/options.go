@@ -171,6 +171,13 @@ type Options struct {
 	// be independent; do not rely on one completing before another starts.
 	Hooks map[string][]any `json:"hooks,omitempty"`
 
+	// RuntimeHookEvents are events whose hooks are dispatched by the SDK
+	// through one always-registered callback, so matchers can be added,
+	// removed and replaced on a connected client even when the CLI cannot
+	// re-register hooks. Streaming clients dispatch DefaultRuntimeHookEvents
+	// when it is nil; a non-nil empty slice dispatches nothing.
+	RuntimeHookEvents []HookEvent `json:"-"`
+
 	// Stderr callback for CLI debug output
 	Stderr func(string) `json:"-"` // Called with each stderr line from CLI
 
@@ -491,6 +498,9 @@ func (c *McpHTTPServerConfig) GetType() McpServerType {
 
 // McpSDKServer represents an in-process MCP server instance.
 // This is an interface to avoid circular dependencies.
+//
+// The control protocol passes the per-request context.Context as ctx so
+// tool handlers are cancelled with the request.
 type McpSDKServer interface {
 	Name() string
 	Version() string
@@ -2,6 +2,7 @@ package shared
 
 import (
 	"context"
+	"sync"
 )
 
 // PermissionUpdateType represents the type of permission update.
@@ -54,7 +55,7 @@ type PermissionUpdate struct {
 // ToolPermissionContext provides context for tool permission callbacks.
 type ToolPermissionContext struct {
 	Context     context.Context
-	Signal      any                // Future: abort signal support
+	Signal      *AbortSignal       // Fires with a reason when the request is aborted
 	Suggestions []PermissionUpdate // Permission suggestions from CLI
 	ToolUseID   *string
 	AgentID     *string
@@ -116,6 +117,40 @@ func (p *PermissionResultDeny) isPermissionResult()  {}
 //   - error: Error if callback execution fails
 type CanUseToolCallback func(toolName string, toolInput map[string]any, ctx ToolPermissionContext) (PermissionResult, error)
 
+type approverKey struct{}
+
+type approverRecorder struct {
+	mu       sync.Mutex
+	approver string
+}
+
+// WithApproverRecorder returns a context on which permission callbacks can
+// report who or what decided with RecordApprover, and a function returning
+// the last approver reported.
+func WithApproverRecorder(ctx context.Context) (context.Context, func() string) {
+	recorder := &approverRecorder{}
+	approver := func() string {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		return recorder.approver
+	}
+	return context.WithValue(ctx, approverKey{}, recorder), approver
+}
+
+// RecordApprover reports approver (e.g. "queue:alice" or "cache") as the
+// decider of the permission request ctx belongs to. The last report wins;
+// it does nothing unless ctx came from WithApproverRecorder.
+func RecordApprover(ctx context.Context, approver string) {
+	if ctx == nil {
+		return
+	}
+	if recorder, ok := ctx.Value(approverKey{}).(*approverRecorder); ok {
+		recorder.mu.Lock()
+		recorder.approver = approver
+		recorder.mu.Unlock()
+	}
+}
+
 // Helper functions to create permission results
 
 // NewPermissionAllow creates a permission allow result.
@@ -588,6 +588,42 @@ func (t *Transport) SetPermissionMode(_ context.Context, mode string) error {
 	return t.controlProtocol.SetPermissionMode(mode)
 }
 
+// AddHook registers a hook matcher for event on the running session.
+func (t *Transport) AddHook(_ context.Context, event shared.HookEvent, matcher shared.HookMatcher) (shared.HookHandle, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if !t.connected || t.controlProtocol == nil {
+		return "", fmt.Errorf("transport not connected")
+	}
+
+	return t.controlProtocol.AddHook(event, matcher)
+}
+
+// RemoveHook unregisters the hook matcher added under handle.
+func (t *Transport) RemoveHook(_ context.Context, handle shared.HookHandle) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if !t.connected || t.controlProtocol == nil {
+		return fmt.Errorf("transport not connected")
+	}
+
+	return t.controlProtocol.RemoveHook(handle)
+}
+
+// ReplaceHooks replaces every hook matcher of event with matchers.
+func (t *Transport) ReplaceHooks(_ context.Context, event shared.HookEvent, matchers []shared.HookMatcher) ([]shared.HookHandle, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if !t.connected || t.controlProtocol == nil {
+		return nil, fmt.Errorf("transport not connected")
+	}
+
+	return t.controlProtocol.ReplaceHooks(event, matchers)
+}
+
 // SetModel changes the AI model during conversation.
 func (t *Transport) SetModel(_ context.Context, model *string) error {
 	t.mu.RLock()
@@ -989,7 +1025,7 @@ func shouldCreateHookProcessor(options *shared.Options) bool {
 	if options == nil {
 		return false
 	}
-	return len(options.Hooks) > 0 || options.CanUseTool != nil
+	return len(options.Hooks) > 0 || options.CanUseTool != nil || len(options.RuntimeHookEvents) > 0
 }
 
 // isProcessAlreadyFinishedError checks if an error indicates the process has already terminated.
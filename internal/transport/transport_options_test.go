@@ -90,6 +90,13 @@ func TestShouldCreateHookProcessor(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "runtime hook events configured",
+			options: &shared.Options{
+				RuntimeHookEvents: []shared.HookEvent{shared.HookEventPreToolUse},
+			},
+			want: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -0,0 +1,135 @@
+package claudesdk
+
+import (
+	"context"
+	"net"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/approval"
+)
+
+// TerminalApprovalOptions configures a TerminalApprover: the terminal to
+// prompt on, the PermissionDestination "always" answers are saved to, an
+// optional $EDITOR for editing input, and diff colors.
+type TerminalApprovalOptions = approval.TerminalOptions
+
+// TerminalApprover is a human-in-the-loop permission prompt for local
+// tools. For each request it shows the tool, the command for Bash and a
+// diff for Edit, MultiEdit and Write, then lets the user allow once, allow
+// always (returning PermissionUpdates the CLI persists), deny with a
+// reason, or edit the input. Aborted requests are denied.
+type TerminalApprover = approval.Terminal
+
+// NewTerminalApprover creates a TerminalApprover; use its CanUseTool method
+// as the callback.
+func NewTerminalApprover(opts TerminalApprovalOptions) *TerminalApprover {
+	return approval.NewTerminal(opts)
+}
+
+// WithTerminalApproval prompts on the terminal for every tool permission
+// request.
+//
+// Example:
+//
+//	client := claudesdk.NewClient(claudesdk.WithTerminalApproval(claudesdk.TerminalApprovalOptions{
+//	    Destination: claudesdk.PermissionDestinationProjectSettings,
+//	    Editor:      os.Getenv("EDITOR"),
+//	    Color:       true,
+//	}))
+func WithTerminalApproval(opts TerminalApprovalOptions) Option {
+	return WithCanUseTool(NewTerminalApprover(opts).CanUseTool)
+}
+
+// ApprovalQueueOptions configures an ApprovalQueue: the decision timeout
+// (denied when it elapses, 5 minutes by default), the bearer token for the
+// API (generated when empty; see ApprovalQueue.Token), an audit writer
+// receiving one JSON record per line, and a hook for notifying reviewers.
+type ApprovalQueueOptions = approval.QueueOptions
+
+// ApprovalQueue parks permission requests until a reviewer approves or
+// denies them, e.g. from a web dashboard through the HTTP/JSON API
+// returned by Handler. Requests the CLI cancels are withdrawn.
+type ApprovalQueue = approval.Queue
+
+// PendingApproval is a permission request waiting in an ApprovalQueue.
+type PendingApproval = approval.PendingRequest
+
+// ApprovalResolution is a reviewer's answer to a PendingApproval.
+type ApprovalResolution = approval.Resolution
+
+// ApprovalRecord is the audit entry of a finished ApprovalQueue request.
+type ApprovalRecord = approval.Record
+
+// ApprovalQueue outcomes recorded in ApprovalRecord.Outcome.
+const (
+	ApprovalOutcomeApproved  = approval.OutcomeApproved
+	ApprovalOutcomeDenied    = approval.OutcomeDenied
+	ApprovalOutcomeTimeout   = approval.OutcomeTimeout
+	ApprovalOutcomeCancelled = approval.OutcomeCancelled
+)
+
+// ErrApprovalNotFound is returned when resolving an unknown or finished
+// request.
+var ErrApprovalNotFound = approval.ErrRequestNotFound
+
+// NewApprovalQueue creates an ApprovalQueue; use its CanUseTool method as
+// the callback.
+func NewApprovalQueue(opts ApprovalQueueOptions) *ApprovalQueue {
+	return approval.NewQueue(opts)
+}
+
+// ServeApprovalQueue listens on addr and serves the queue's API until ctx
+// ends. If ready is non-nil it is called with the bound address once the
+// listener is open.
+//
+// Example:
+//
+//	audit, _ := os.OpenFile("approvals.jsonl", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+//	queue := claudesdk.NewApprovalQueue(claudesdk.ApprovalQueueOptions{Audit: audit})
+//	fmt.Println("reviewer token:", queue.Token())
+//	go claudesdk.ServeApprovalQueue(ctx, queue, "127.0.0.1:8940", nil)
+//	client := claudesdk.NewClient(claudesdk.WithCanUseTool(queue.CanUseTool))
+func ServeApprovalQueue(ctx context.Context, queue *ApprovalQueue, addr string, ready func(addr net.Addr)) error {
+	return serveHTTP(ctx, queue.Handler(), addr, ready)
+}
+
+// PermissionCacheOptions configures a PermissionCache: decision TTL and
+// size, whether denials are memoized, input keys ignored by the signature,
+// and the destination CLI suggestions are accepted to on allow.
+type PermissionCacheOptions = approval.CacheOptions
+
+// PermissionCache wraps a CanUseToolCallback, memoizing its decisions by
+// tool name and normalized input and learning the rules of the permission
+// updates it accepts ("always allow" answers and applied suggestions), so
+// repeated requests are answered without asking again. Rules lists the
+// learned rules for review; SettingsPermissions and WriteSettings export
+// them in the settings file format.
+type PermissionCache = approval.Cache
+
+// LearnedPermissionRule is a rule learned by a PermissionCache.
+type LearnedPermissionRule = approval.LearnedRule
+
+// PermissionCacheStats counts how a PermissionCache answered requests.
+type PermissionCacheStats = approval.CacheStats
+
+// SettingsPermissions is the "permissions" block of a Claude settings
+// file.
+type SettingsPermissions = approval.SettingsPermissions
+
+// NewPermissionCache wraps next in a PermissionCache; use its CanUseTool
+// method as the callback.
+//
+// Example:
+//
+//	cache := claudesdk.NewPermissionCache(
+//	    claudesdk.NewTerminalApprover(claudesdk.TerminalApprovalOptions{}).CanUseTool,
+//	    claudesdk.PermissionCacheOptions{TTL: time.Hour},
+//	)
+//	client := claudesdk.NewClient(claudesdk.WithCanUseTool(cache.CanUseTool))
+//	// ... after the session:
+//	for _, rule := range cache.Rules() {
+//	    fmt.Println(rule.Destination, rule.Behavior, rule)
+//	}
+//	err := cache.WriteSettings(".claude/settings.local.json")
+func NewPermissionCache(next CanUseToolCallback, opts PermissionCacheOptions) *PermissionCache {
+	return approval.NewCache(next, opts)
+}
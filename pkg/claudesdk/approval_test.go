@@ -0,0 +1,81 @@
+package claudesdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithTerminalApproval(t *testing.T) {
+	opts := NewOptions(WithTerminalApproval(TerminalApprovalOptions{In: strings.NewReader("a\n"), Out: io.Discard}))
+	result, err := opts.CanUseTool("WebFetch", map[string]any{"url": "https://example.com"}, ToolPermissionContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	allow, ok := result.(*PermissionResultAllow)
+	if !ok || len(allow.UpdatedPermissions) != 1 || *allow.UpdatedPermissions[0].Destination != PermissionDestinationLocalSettings {
+		t.Errorf("result = %#v", result)
+	}
+}
+
+func TestServeApprovalQueue(t *testing.T) {
+	queue := NewApprovalQueue(ApprovalQueueOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	addrCh := make(chan net.Addr, 1)
+	errCh := make(chan error, 1)
+	go func() { errCh <- ServeApprovalQueue(ctx, queue, "127.0.0.1:0", func(a net.Addr) { addrCh <- a }) }()
+	addr := <-addrCh
+
+	results := make(chan PermissionResult, 1)
+	go func() {
+		result, _ := queue.CanUseTool("Bash", map[string]any{"command": "ls"}, ToolPermissionContext{})
+		results <- result
+	}()
+	var pending []PendingApproval
+	for len(pending) == 0 {
+		pending = queue.Pending()
+		time.Sleep(5 * time.Millisecond)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "http://"+addr.String()+"/requests/"+pending[0].ID+"/approve", nil)
+	req.Header.Set("Authorization", "Bearer "+queue.Token())
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("approve = %v, %v", resp, err)
+	}
+	if _, ok := (<-results).(*PermissionResultAllow); !ok {
+		t.Error("request was not approved")
+	}
+
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("ServeApprovalQueue = %v", err)
+	}
+}
+
+func TestPermissionCacheWithTerminalApprover(t *testing.T) {
+	approver := NewTerminalApprover(TerminalApprovalOptions{In: strings.NewReader("a\n"), Out: io.Discard})
+	cache := NewPermissionCache(approver.CanUseTool, PermissionCacheOptions{})
+
+	for range 2 {
+		result, err := cache.CanUseTool("Bash", map[string]any{"command": "make build"}, ToolPermissionContext{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := result.(*PermissionResultAllow); !ok {
+			t.Fatalf("expected allow, got %#v", result)
+		}
+	}
+	rules := cache.Rules()
+	if len(rules) != 1 || rules[0].String() != "Bash(make build)" || rules[0].Destination != PermissionDestinationLocalSettings {
+		t.Fatalf("expected the always answer to be learned, got %+v", rules)
+	}
+	if stats := cache.Stats(); stats.Misses != 1 || stats.RuleHits != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,118 @@
+package claudesdk
+
+import (
+	"context"
+	"io"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/audit"
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+)
+
+// AuditRecord is one entry of a tamper-evident audit log: the event, the
+// session and tool_use_id, a digest of the tool input, the permission
+// decision and its reason, timing and the tool result status. Each record
+// carries the hash of the one before it.
+type AuditRecord = audit.Record
+
+// AuditSink receives chained AuditRecords in order, e.g. to ship them to a
+// log service.
+type AuditSink = audit.Sink
+
+// AuditSinkFunc adapts a function to an AuditSink.
+type AuditSinkFunc = audit.SinkFunc
+
+// AuditOptions configures an AuditLogger: the clock, a write error
+// handler, FailClosed to deny calls that cannot be recorded, and the head
+// of an existing chain to continue.
+type AuditOptions = audit.Options
+
+// AuditLogger records tool usage and permission decisions to an
+// AuditSink. Register it with WithAuditLog and wrap the permission
+// callback with its CanUseTool method.
+type AuditLogger = audit.Logger
+
+// AuditReport summarizes a verified audit log.
+type AuditReport = audit.Report
+
+// AuditChainError reports where an audit log fails verification.
+type AuditChainError = audit.ChainError
+
+// ErrAuditChainBroken is wrapped by every AuditChainError.
+var ErrAuditChainBroken = audit.ErrChainBroken
+
+// AuditRecord events and statuses.
+const (
+	AuditEventPreToolUse         = audit.EventPreToolUse
+	AuditEventPostToolUse        = audit.EventPostToolUse
+	AuditEventPostToolUseFailure = audit.EventPostToolUseFailure
+	AuditEventPermissionRequest  = audit.EventPermissionRequest
+	AuditEventPermissionDecision = audit.EventPermissionDecision
+
+	AuditStatusSuccess     = audit.StatusSuccess
+	AuditStatusError       = audit.StatusError
+	AuditStatusInterrupted = audit.StatusInterrupted
+)
+
+// NewAuditLogger creates an AuditLogger writing to sink.
+func NewAuditLogger(sink AuditSink, opts AuditOptions) *AuditLogger {
+	return audit.NewLogger(sink, opts)
+}
+
+// NewAuditWriterSink creates an AuditSink writing JSON lines to w.
+func NewAuditWriterSink(w io.Writer) AuditSink {
+	return audit.NewWriterSink(w)
+}
+
+// OpenAuditLog verifies the JSONL audit log at path, if it exists, and
+// returns an AuditLogger appending to it that continues its chain. A log
+// that fails verification is refused with an AuditChainError.
+//
+// Example:
+//
+//	logger, err := claudesdk.OpenAuditLog("audit.jsonl", claudesdk.AuditOptions{FailClosed: true})
+//	defer logger.Close()
+//	client := claudesdk.NewClient(
+//	    claudesdk.WithAuditLog(logger),
+//	    claudesdk.WithCanUseTool(logger.CanUseTool(approver.CanUseTool)),
+//	)
+func OpenAuditLog(path string, opts AuditOptions) (*AuditLogger, error) {
+	return audit.OpenFile(path, opts)
+}
+
+// VerifyAuditLog checks the hash chain of a JSONL audit log and returns
+// an AuditChainError for the first record that was edited, removed or
+// reordered. The log must start the chain at sequence 1.
+func VerifyAuditLog(r io.Reader) (AuditReport, error) {
+	return audit.Verify(r)
+}
+
+// VerifyAuditLogAfter is VerifyAuditLog for a log continuing the chain
+// after head, e.g. the last record of the previous file of a rotated log.
+func VerifyAuditLogAfter(r io.Reader, head AuditRecord) (AuditReport, error) {
+	return audit.VerifyAfter(r, head)
+}
+
+// WithAuditLog records the PreToolUse, PostToolUse, PostToolUseFailure and
+// PermissionRequest events of every tool to logger. Permission decisions
+// are recorded by wrapping the callback with logger.CanUseTool.
+func WithAuditLog(logger *AuditLogger) Option {
+	return func(o *Options) {
+		matcher := HookMatcher{Hooks: []HookCallback{logger.Hook()}}
+		for _, event := range []HookEvent{
+			HookEventPreToolUse,
+			HookEventPostToolUse,
+			HookEventPostToolUseFailure,
+			HookEventPermissionRequest,
+		} {
+			WithHook(event, matcher)(o)
+		}
+	}
+}
+
+// RecordPermissionApprover reports who or what decided a permission
+// request, e.g. "ui:alice", from a callback wrapped by
+// AuditLogger.CanUseTool. The built-in approval queue, cache, terminal
+// prompt and policy engine report themselves.
+func RecordPermissionApprover(ctx context.Context, approver string) {
+	shared.RecordApprover(ctx, approver)
+}
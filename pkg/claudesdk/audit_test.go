@@ -0,0 +1,32 @@
+package claudesdk
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWithAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewAuditLogger(NewAuditWriterSink(&buf), AuditOptions{})
+	opts := NewOptions(WithAuditLog(logger))
+
+	for _, event := range []HookEvent{HookEventPreToolUse, HookEventPostToolUse, HookEventPostToolUseFailure, HookEventPermissionRequest} {
+		matchers := opts.Hooks[string(event)]
+		if len(matchers) != 1 {
+			t.Fatalf("expected one %s matcher, got %d", event, len(matchers))
+		}
+		callback := matchers[0].(HookMatcher).Hooks[0]
+		input := map[string]any{"hook_event_name": string(event), "session_id": "s", "tool_name": "Read", "tool_use_id": "t"}
+		if _, err := callback(input, nil, HookContext{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := VerifyAuditLog(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Records != 4 || report.Last.Event != AuditEventPermissionRequest {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
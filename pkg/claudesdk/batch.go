@@ -0,0 +1,459 @@
+package claudesdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BatchItem is one prompt in a batch run.
+type BatchItem struct {
+	// ID identifies the item in results and in the checkpoint file. When
+	// empty, the item's position in the input is used. IDs, including
+	// assigned ones, must be unique within a batch; an explicit "2" and a
+	// third item without an ID are rejected as duplicates.
+	ID string
+
+	// Prompt is sent as the one-shot query prompt.
+	Prompt string
+
+	// Options are applied after BatchConfig.Options, so they override the
+	// batch-wide defaults for this item only.
+	Options []Option
+}
+
+// BatchConfig configures RunBatch and RunBatchChan.
+type BatchConfig struct {
+	// Options are applied to every item before the item's own Options.
+	Options []Option
+
+	// Concurrency bounds how many queries run at once. Values < 1 mean 1.
+	Concurrency int
+
+	// ItemTimeout bounds a single attempt. Zero means no per-item timeout.
+	ItemTimeout time.Duration
+
+	// MaxRetries is the number of extra attempts after a failed one.
+	// An attempt fails when the query returns an error or ends without a
+	// ResultMessage.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry; it doubles on each
+	// subsequent retry. Zero retries immediately.
+	RetryBackoff time.Duration
+
+	// RetryResultErrors also retries attempts whose ResultMessage has
+	// IsError set (e.g. error_max_turns). Off by default because those are
+	// usually deterministic.
+	RetryResultErrors bool
+
+	// CheckpointPath, when set, appends one JSON line per finished item.
+	// A later run with the same path skips items that already succeeded and
+	// reports their recorded results with Resumed set.
+	CheckpointPath string
+
+	// OnItemDone is called after each item finishes, from the worker
+	// goroutine that ran it. It must be safe for concurrent use.
+	OnItemDone func(BatchItemResult)
+}
+
+// BatchItemResult is the outcome of a single batch item.
+type BatchItemResult struct {
+	ID     string
+	Index  int
+	Prompt string
+
+	// Result is the final ResultMessage, or nil if no attempt produced one.
+	Result *ResultMessage
+
+	// StructuredOutput mirrors Result.StructuredOutput for convenience.
+	StructuredOutput interface{}
+
+	// Attempts is the number of queries run for this item (0 when resumed).
+	Attempts int
+
+	// CostUSD sums the cost reported by every attempt, including failed
+	// and retried ones.
+	CostUSD float64
+
+	// Resumed is true when the result was loaded from the checkpoint file.
+	Resumed bool
+
+	// Err is the error from the last attempt, if any.
+	Err error
+}
+
+// Succeeded reports whether the item finished with a non-error result.
+func (r BatchItemResult) Succeeded() bool {
+	return r.Err == nil && r.Result != nil && !r.Result.IsError
+}
+
+// BatchResult aggregates the outcome of a batch run.
+type BatchResult struct {
+	// Items are ordered by input position.
+	Items []BatchItemResult
+
+	// TotalCostUSD sums CostUSD over every item, including resumed ones.
+	TotalCostUSD float64
+
+	Succeeded int
+	Failed    int
+	Resumed   int
+}
+
+// ErrDuplicateBatchItemID is returned when two batch items share an ID.
+var ErrDuplicateBatchItemID = errors.New("duplicate batch item ID")
+
+// batchQueryFunc runs one item. Overridden in tests.
+type batchQueryFunc func(ctx context.Context, prompt string, opts ...Option) (MessageIterator, error)
+
+// RunBatch runs every item through Query with bounded concurrency.
+//
+// Per-item failures are reported on the corresponding BatchItemResult; the
+// returned error is only non-nil when the batch itself could not run (bad
+// checkpoint file, duplicate IDs, or ctx cancelled).
+//
+// Example:
+//
+//	res, err := claudesdk.RunBatch(ctx, items, claudesdk.BatchConfig{
+//	    Options:        []claudesdk.Option{claudesdk.WithMaxTurns(3)},
+//	    Concurrency:    8,
+//	    ItemTimeout:    2 * time.Minute,
+//	    MaxRetries:     2,
+//	    CheckpointPath: "batch.checkpoint.jsonl",
+//	})
+func RunBatch(ctx context.Context, items []BatchItem, config BatchConfig) (*BatchResult, error) {
+	return runBatch(ctx, items, config, Query)
+}
+
+// RunBatchChan is RunBatch for a stream of items. It returns once the
+// channel is closed and every received item has finished. Items without an
+// ID are numbered in arrival order.
+func RunBatchChan(ctx context.Context, items <-chan BatchItem, config BatchConfig) (*BatchResult, error) {
+	if items == nil {
+		return nil, fmt.Errorf("items stream is required")
+	}
+	return runBatchChan(ctx, items, config, Query)
+}
+
+func runBatch(ctx context.Context, items []BatchItem, config BatchConfig, query batchQueryFunc) (*BatchResult, error) {
+	// Reject duplicate IDs before any item runs; RunBatchChan can only
+	// notice them on arrival.
+	seen := make(map[string]struct{}, len(items))
+	for index, item := range items {
+		id := batchItemID(item, index)
+		if _, dup := seen[id]; dup {
+			return nil, duplicateBatchItemIDError(item, id)
+		}
+		seen[id] = struct{}{}
+	}
+
+	ch := make(chan BatchItem, len(items))
+	for _, item := range items {
+		ch <- item
+	}
+	close(ch)
+	return runBatchChan(ctx, ch, config, query)
+}
+
+func runBatchChan(ctx context.Context, items <-chan BatchItem, config BatchConfig, query batchQueryFunc) (*BatchResult, error) {
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var checkpoint *batchCheckpoint
+	if config.CheckpointPath != "" {
+		var err error
+		checkpoint, err = openBatchCheckpoint(config.CheckpointPath)
+		if err != nil {
+			return nil, err
+		}
+		defer checkpoint.Close()
+	}
+
+	var (
+		mu      sync.Mutex
+		results []BatchItemResult
+		seen    = make(map[string]struct{})
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, concurrency)
+		runErr  error
+	)
+
+	record := func(r BatchItemResult) {
+		mu.Lock()
+		results[r.Index] = r
+		mu.Unlock()
+		if config.OnItemDone != nil {
+			config.OnItemDone(r)
+		}
+	}
+
+	index := 0
+loop:
+	for {
+		var item BatchItem
+		var ok bool
+		select {
+		case item, ok = <-items:
+			if !ok {
+				break loop
+			}
+		case <-ctx.Done():
+			runErr = ctx.Err()
+			break loop
+		}
+
+		if _, dup := seen[batchItemID(item, index)]; dup {
+			runErr = duplicateBatchItemIDError(item, batchItemID(item, index))
+			break loop
+		}
+		item.ID = batchItemID(item, index)
+		seen[item.ID] = struct{}{}
+
+		mu.Lock()
+		results = append(results, BatchItemResult{ID: item.ID, Index: index, Prompt: item.Prompt})
+		mu.Unlock()
+		itemIndex := index
+		index++
+
+		if checkpoint != nil {
+			if prior, ok := checkpoint.completed(item.ID); ok {
+				prior.Index = itemIndex
+				prior.Prompt = item.Prompt
+				record(prior)
+				continue
+			}
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			runErr = ctx.Err()
+			break loop
+		}
+		wg.Add(1)
+		go func(item BatchItem, itemIndex int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			r := runBatchItem(ctx, item, config, query)
+			r.Index = itemIndex
+			if checkpoint != nil && ctx.Err() == nil {
+				if err := checkpoint.append(r); err != nil && r.Err == nil {
+					r.Err = fmt.Errorf("failed to write checkpoint: %w", err)
+				}
+			}
+			record(r)
+		}(item, itemIndex)
+	}
+	wg.Wait()
+
+	out := &BatchResult{Items: results}
+	for _, r := range results {
+		out.TotalCostUSD += r.CostUSD
+		if r.Resumed {
+			out.Resumed++
+		}
+		if r.Succeeded() {
+			out.Succeeded++
+		} else {
+			out.Failed++
+		}
+	}
+	return out, runErr
+}
+
+// batchItemID returns item's ID, or its position when it has none.
+func batchItemID(item BatchItem, index int) string {
+	if item.ID == "" {
+		return strconv.Itoa(index)
+	}
+	return item.ID
+}
+
+func duplicateBatchItemIDError(item BatchItem, id string) error {
+	if item.ID == "" {
+		return fmt.Errorf("%w: %s (assigned to item %s, which has no ID)", ErrDuplicateBatchItemID, id, id)
+	}
+	return fmt.Errorf("%w: %s", ErrDuplicateBatchItemID, id)
+}
+
+func runBatchItem(ctx context.Context, item BatchItem, config BatchConfig, query batchQueryFunc) BatchItemResult {
+	opts := make([]Option, 0, len(config.Options)+len(item.Options))
+	opts = append(opts, config.Options...)
+	opts = append(opts, item.Options...)
+
+	result := BatchItemResult{ID: item.ID, Prompt: item.Prompt}
+	backoff := config.RetryBackoff
+	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
+		if attempt > 0 && backoff > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				return result
+			}
+			backoff *= 2
+		}
+
+		result.Attempts++
+		res, err := runBatchAttempt(ctx, item.Prompt, opts, config.ItemTimeout, query)
+		result.Result = res
+		result.Err = err
+		if res != nil {
+			result.StructuredOutput = res.StructuredOutput
+			if res.TotalCostUSD != nil {
+				result.CostUSD += *res.TotalCostUSD
+			}
+		}
+		if ctx.Err() != nil {
+			return result
+		}
+		if err == nil && !(config.RetryResultErrors && res.IsError) {
+			return result
+		}
+	}
+	return result
+}
+
+func runBatchAttempt(
+	ctx context.Context,
+	prompt string,
+	opts []Option,
+	timeout time.Duration,
+	query batchQueryFunc,
+) (*ResultMessage, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	iter, err := query(ctx, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var result *ResultMessage
+	for {
+		msg, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, ErrNoMoreMessages) {
+				break
+			}
+			return result, err
+		}
+		if rm, ok := msg.(*ResultMessage); ok {
+			result = rm
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("query ended without a result message")
+	}
+	return result, nil
+}
+
+// batchCheckpointRecord is one line of the checkpoint file.
+type batchCheckpointRecord struct {
+	ID       string         `json:"id"`
+	Attempts int            `json:"attempts"`
+	CostUSD  float64        `json:"cost_usd,omitempty"`
+	Result   *ResultMessage `json:"result,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// batchCheckpoint persists finished items as JSON lines. Later lines for the
+// same ID supersede earlier ones, so a failed item that succeeds on a rerun
+// is recorded as succeeded.
+type batchCheckpoint struct {
+	mu    sync.Mutex
+	file  *os.File
+	prior map[string]batchCheckpointRecord
+}
+
+func openBatchCheckpoint(path string) (*batchCheckpoint, error) {
+	prior := make(map[string]batchCheckpointRecord)
+	if f, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			var rec batchCheckpointRecord
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				_ = f.Close()
+				return nil, fmt.Errorf("invalid checkpoint %s line %d: %w", path, line, err)
+			}
+			prior[rec.ID] = rec
+		}
+		scanErr := scanner.Err()
+		_ = f.Close()
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to read checkpoint %s: %w", path, scanErr)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to open checkpoint %s: %w", path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint %s: %w", path, err)
+	}
+	return &batchCheckpoint{file: file, prior: prior}, nil
+}
+
+// completed returns the recorded result for id if it previously succeeded.
+func (c *batchCheckpoint) completed(id string) (BatchItemResult, bool) {
+	rec, ok := c.prior[id]
+	if !ok || rec.Error != "" || rec.Result == nil || rec.Result.IsError {
+		return BatchItemResult{}, false
+	}
+	r := BatchItemResult{
+		ID:               id,
+		Result:           rec.Result,
+		StructuredOutput: rec.Result.StructuredOutput,
+		Resumed:          true,
+	}
+	if rec.CostUSD != 0 {
+		r.CostUSD = rec.CostUSD
+	} else if rec.Result.TotalCostUSD != nil {
+		r.CostUSD = *rec.Result.TotalCostUSD
+	}
+	return r, true
+}
+
+func (c *batchCheckpoint) append(r BatchItemResult) error {
+	rec := batchCheckpointRecord{ID: r.ID, Attempts: r.Attempts, CostUSD: r.CostUSD, Result: r.Result}
+	if r.Err != nil {
+		rec.Error = r.Err.Error()
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.file.Write(data); err != nil {
+		return err
+	}
+	return c.file.Sync()
+}
+
+func (c *batchCheckpoint) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.file.Close()
+}
@@ -0,0 +1,235 @@
+package claudesdk
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type sliceIterator struct {
+	msgs []Message
+	pos  int
+}
+
+func (it *sliceIterator) Next(context.Context) (Message, error) {
+	if it.pos >= len(it.msgs) {
+		return nil, ErrNoMoreMessages
+	}
+	msg := it.msgs[it.pos]
+	it.pos++
+	return msg, nil
+}
+
+func (it *sliceIterator) Close() error { return nil }
+
+func batchResult(text string, cost float64) *ResultMessage {
+	return &ResultMessage{
+		MessageType:      MessageTypeResult,
+		Subtype:          "success",
+		Result:           &text,
+		TotalCostUSD:     &cost,
+		StructuredOutput: map[string]any{"echo": text},
+	}
+}
+
+func TestRunBatchBoundsConcurrencyAndAggregatesCost(t *testing.T) {
+	var inFlight, peak int32
+	query := func(ctx context.Context, prompt string, _ ...Option) (MessageIterator, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return &sliceIterator{msgs: []Message{batchResult(prompt, 0.5)}}, nil
+	}
+
+	items := make([]BatchItem, 6)
+	for i := range items {
+		items[i] = BatchItem{Prompt: string(rune('a' + i))}
+	}
+	res, err := runBatch(context.Background(), items, BatchConfig{Concurrency: 2}, query)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", peak)
+	}
+	if res.Succeeded != 6 || res.Failed != 0 {
+		t.Errorf("succeeded/failed = %d/%d, want 6/0", res.Succeeded, res.Failed)
+	}
+	if res.TotalCostUSD != 3.0 {
+		t.Errorf("TotalCostUSD = %v, want 3.0", res.TotalCostUSD)
+	}
+	for i, item := range res.Items {
+		if item.Index != i || item.ID != string(rune('0'+i)) {
+			t.Errorf("item %d has index %d id %q", i, item.Index, item.ID)
+		}
+		out, _ := item.StructuredOutput.(map[string]any)
+		if out["echo"] != items[i].Prompt {
+			t.Errorf("item %d structured output = %v", i, item.StructuredOutput)
+		}
+	}
+}
+
+func TestRunBatchAppliesItemOptionsAfterBatchOptions(t *testing.T) {
+	var mu sync.Mutex
+	models := map[string]string{}
+	query := func(_ context.Context, prompt string, opts ...Option) (MessageIterator, error) {
+		o := NewOptions(opts...)
+		mu.Lock()
+		models[prompt] = *o.Model
+		mu.Unlock()
+		return &sliceIterator{msgs: []Message{batchResult(prompt, 0)}}, nil
+	}
+
+	items := []BatchItem{
+		{Prompt: "default"},
+		{Prompt: "override", Options: []Option{WithModel("opus")}},
+	}
+	_, err := runBatch(context.Background(), items, BatchConfig{
+		Options: []Option{WithModel("sonnet")},
+	}, query)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if models["default"] != "sonnet" || models["override"] != "opus" {
+		t.Errorf("models = %v", models)
+	}
+}
+
+func TestRunBatchRetriesFailedAttempts(t *testing.T) {
+	var calls int32
+	query := func(_ context.Context, prompt string, _ ...Option) (MessageIterator, error) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return nil, errors.New("transient")
+		}
+		return &sliceIterator{msgs: []Message{batchResult(prompt, 0.1)}}, nil
+	}
+
+	res, err := runBatch(context.Background(), []BatchItem{{ID: "x", Prompt: "p"}}, BatchConfig{MaxRetries: 2}, query)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	item := res.Items[0]
+	if !item.Succeeded() || item.Attempts != 3 {
+		t.Errorf("item = %+v, want success after 3 attempts", item)
+	}
+}
+
+func TestRunBatchItemTimeout(t *testing.T) {
+	query := func(ctx context.Context, _ string, _ ...Option) (MessageIterator, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	res, err := runBatch(context.Background(), []BatchItem{{Prompt: "slow"}}, BatchConfig{
+		ItemTimeout: 20 * time.Millisecond,
+	}, query)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if !errors.Is(res.Items[0].Err, context.DeadlineExceeded) {
+		t.Errorf("Err = %v, want deadline exceeded", res.Items[0].Err)
+	}
+	if res.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", res.Failed)
+	}
+}
+
+func TestRunBatchResumesFromCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.jsonl")
+	items := []BatchItem{{ID: "a", Prompt: "a"}, {ID: "b", Prompt: "b"}}
+
+	first := func(_ context.Context, prompt string, _ ...Option) (MessageIterator, error) {
+		if prompt == "b" {
+			return nil, errors.New("boom")
+		}
+		return &sliceIterator{msgs: []Message{batchResult(prompt, 1)}}, nil
+	}
+	res, err := runBatch(context.Background(), items, BatchConfig{CheckpointPath: path}, first)
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if res.Succeeded != 1 || res.Failed != 1 {
+		t.Fatalf("first run succeeded/failed = %d/%d", res.Succeeded, res.Failed)
+	}
+
+	var reran []string
+	second := func(_ context.Context, prompt string, _ ...Option) (MessageIterator, error) {
+		reran = append(reran, prompt)
+		return &sliceIterator{msgs: []Message{batchResult(prompt, 2)}}, nil
+	}
+	res, err = runBatch(context.Background(), items, BatchConfig{CheckpointPath: path}, second)
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if len(reran) != 1 || reran[0] != "b" {
+		t.Errorf("reran = %v, want [b]", reran)
+	}
+	if !res.Items[0].Resumed || res.Resumed != 1 {
+		t.Errorf("item a not resumed: %+v", res.Items[0])
+	}
+	if res.Succeeded != 2 || res.TotalCostUSD != 3 {
+		t.Errorf("second run succeeded=%d cost=%v", res.Succeeded, res.TotalCostUSD)
+	}
+}
+
+func TestRunBatchRejectsDuplicateIDs(t *testing.T) {
+	query := func(_ context.Context, prompt string, _ ...Option) (MessageIterator, error) {
+		return &sliceIterator{msgs: []Message{batchResult(prompt, 0)}}, nil
+	}
+	_, err := runBatch(context.Background(), []BatchItem{{ID: "a"}, {ID: "a"}}, BatchConfig{}, query)
+	if !errors.Is(err, ErrDuplicateBatchItemID) {
+		t.Errorf("err = %v, want ErrDuplicateBatchItemID", err)
+	}
+}
+
+func TestRunBatchSumsCostAcrossAttempts(t *testing.T) {
+	var calls int32
+	query := func(_ context.Context, prompt string, _ ...Option) (MessageIterator, error) {
+		res := batchResult(prompt, 0.25)
+		if atomic.AddInt32(&calls, 1) < 3 {
+			res.IsError = true
+		}
+		return &sliceIterator{msgs: []Message{res}}, nil
+	}
+
+	res, err := runBatch(context.Background(), []BatchItem{{ID: "x", Prompt: "p"}},
+		BatchConfig{MaxRetries: 2, RetryResultErrors: true}, query)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if item := res.Items[0]; !item.Succeeded() || item.Attempts != 3 || item.CostUSD != 0.75 {
+		t.Errorf("item = %+v, want success after 3 attempts costing 0.75", item)
+	}
+	if res.TotalCostUSD != 0.75 {
+		t.Errorf("TotalCostUSD = %v, want 0.75", res.TotalCostUSD)
+	}
+}
+
+func TestRunBatchRejectsAssignedIDCollisionsBeforeRunning(t *testing.T) {
+	var calls int32
+	query := func(_ context.Context, prompt string, _ ...Option) (MessageIterator, error) {
+		atomic.AddInt32(&calls, 1)
+		return &sliceIterator{msgs: []Message{batchResult(prompt, 0)}}, nil
+	}
+	items := []BatchItem{{Prompt: "first"}, {ID: "0", Prompt: "second"}}
+	if _, err := runBatch(context.Background(), items, BatchConfig{}, query); !errors.Is(err, ErrDuplicateBatchItemID) {
+		t.Errorf("err = %v, want ErrDuplicateBatchItemID", err)
+	}
+	items = []BatchItem{{ID: "1", Prompt: "first"}, {Prompt: "second"}}
+	if _, err := runBatch(context.Background(), items, BatchConfig{}, query); !errors.Is(err, ErrDuplicateBatchItemID) {
+		t.Errorf("err = %v, want ErrDuplicateBatchItemID", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no item to run, got %d queries", calls)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"sync"
 
 	"github.com/jonnyquan/claude-agent-sdk-go/internal/discovery"
@@ -32,6 +33,9 @@ type Client interface {
 	StopTask(ctx context.Context, taskID string) error
 	SetPermissionMode(ctx context.Context, mode string) error
 	SetModel(ctx context.Context, model *string) error
+	AddHook(ctx context.Context, event HookEvent, matcher HookMatcher) (HookHandle, error)
+	RemoveHook(ctx context.Context, handle HookHandle) error
+	ReplaceHooks(ctx context.Context, event HookEvent, matchers ...HookMatcher) ([]HookHandle, error)
 	GetServerInfo() map[string]any
 }
 
@@ -49,9 +53,12 @@ type ClientImpl struct {
 	errChan         <-chan error
 }
 
-// NewClient creates a new Client with the given options.
+// NewClient creates a new Client with the given options. Unless
+// WithRuntimeHooks or WithoutRuntimeHooks says otherwise, the hooks of
+// DefaultRuntimeHookEvents are dispatched by the SDK so AddHook works
+// whether or not the CLI can re-register hooks.
 func NewClient(opts ...Option) Client {
-	options := NewOptions(opts...)
+	options := newClientOptions(opts)
 	client := &ClientImpl{
 		options: options,
 	}
@@ -60,13 +67,23 @@ func NewClient(opts ...Option) Client {
 
 // NewClientWithTransport creates a new Client with a custom transport (for testing).
 func NewClientWithTransport(transport Transport, opts ...Option) Client {
-	options := NewOptions(opts...)
+	options := newClientOptions(opts)
 	return &ClientImpl{
 		customTransport: transport,
 		options:         options,
 	}
 }
 
+// newClientOptions builds the options of a streaming client, dispatching
+// DefaultRuntimeHookEvents when runtime hooks were not configured.
+func newClientOptions(opts []Option) *Options {
+	options := NewOptions(opts...)
+	if options.RuntimeHookEvents == nil {
+		options.RuntimeHookEvents = slices.Clone(DefaultRuntimeHookEvents)
+	}
+	return options
+}
+
 // WithClient provides Go-idiomatic resource management equivalent to Python SDK's async context manager.
 // It automatically connects to Claude Code CLI, executes the provided function, and ensures proper cleanup.
 // This eliminates the need for manual Connect/Disconnect calls and prevents resource leaks.
@@ -762,6 +779,73 @@ func (c *ClientImpl) SetPermissionMode(ctx context.Context, mode string) error {
 	return transport.SetPermissionMode(ctx, mode)
 }
 
+// runtimeHookTransport is implemented by transports that can change hooks
+// on a running session.
+type runtimeHookTransport interface {
+	AddHook(ctx context.Context, event HookEvent, matcher HookMatcher) (HookHandle, error)
+	RemoveHook(ctx context.Context, handle HookHandle) error
+	ReplaceHooks(ctx context.Context, event HookEvent, matchers []HookMatcher) ([]HookHandle, error)
+}
+
+// hookTransport returns the connected transport's runtime hook support.
+func (c *ClientImpl) hookTransport(ctx context.Context) (runtimeHookTransport, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	c.mu.RLock()
+	connected := c.connected
+	transport := c.transport
+	c.mu.RUnlock()
+
+	if !connected || transport == nil {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	hooks, ok := transport.(runtimeHookTransport)
+	if !ok {
+		return nil, fmt.Errorf("transport does not support runtime hooks")
+	}
+	return hooks, nil
+}
+
+// AddHook registers a hook matcher for event on the connected session and
+// returns a handle for RemoveHook. Events the SDK dispatches, by default
+// DefaultRuntimeHookEvents, change immediately; others are re-registered
+// with the CLI and fail if it does not support that.
+//
+// Example:
+//
+//	guard, _ := claudesdk.NewTypedHookMatcher(claudesdk.HookEventPreToolUse, "Bash", blockForcePush)
+//	handle, err := client.AddHook(ctx, claudesdk.HookEventPreToolUse, guard)
+func (c *ClientImpl) AddHook(ctx context.Context, event HookEvent, matcher HookMatcher) (HookHandle, error) {
+	hooks, err := c.hookTransport(ctx)
+	if err != nil {
+		return "", err
+	}
+	return hooks.AddHook(ctx, event, matcher)
+}
+
+// RemoveHook unregisters the hook matcher added under handle.
+func (c *ClientImpl) RemoveHook(ctx context.Context, handle HookHandle) error {
+	hooks, err := c.hookTransport(ctx)
+	if err != nil {
+		return err
+	}
+	return hooks.RemoveHook(ctx, handle)
+}
+
+// ReplaceHooks replaces every hook matcher of event, including those set
+// with options, and returns the handles of the new matchers. With no
+// matchers it removes all of event's hooks.
+func (c *ClientImpl) ReplaceHooks(ctx context.Context, event HookEvent, matchers ...HookMatcher) ([]HookHandle, error) {
+	hooks, err := c.hookTransport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return hooks.ReplaceHooks(ctx, event, matchers)
+}
+
 // SetModel changes the AI model during conversation.
 func (c *ClientImpl) SetModel(ctx context.Context, model *string) error {
 	if ctx.Err() != nil {
@@ -956,3 +956,21 @@ func TestConfigureStreamingQueryOptionsAutoSetsStdio(t *testing.T) {
 		t.Fatalf("expected stdio permission prompt tool, got %q", got)
 	}
 }
+
+func TestAddHookRequiresRuntimeHookTransport(t *testing.T) {
+	t.Parallel()
+
+	client := NewClientWithTransport(newMockTransport())
+	matcher := HookMatcher{}
+	if _, err := client.AddHook(context.Background(), HookEventPreToolUse, matcher); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Fatalf("expected a not connected error, got %v", err)
+	}
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer client.Disconnect()
+	if _, err := client.AddHook(context.Background(), HookEventPreToolUse, matcher); err == nil || !strings.Contains(err.Error(), "does not support runtime hooks") {
+		t.Fatalf("expected an unsupported transport error, got %v", err)
+	}
+}
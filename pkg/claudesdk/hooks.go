@@ -19,8 +19,31 @@ const (
 	HookEventNotification       = shared.HookEventNotification
 	HookEventSubagentStart      = shared.HookEventSubagentStart
 	HookEventPermissionRequest  = shared.HookEventPermissionRequest
+	HookEventSessionStart       = shared.HookEventSessionStart
+	HookEventSessionEnd         = shared.HookEventSessionEnd
+	HookEventPostCompact        = shared.HookEventPostCompact
+	HookEventSetup              = shared.HookEventSetup
+	HookEventTeammateIdle       = shared.HookEventTeammateIdle
+	HookEventTaskCompleted      = shared.HookEventTaskCompleted
+	HookEventElicitation        = shared.HookEventElicitation
+	HookEventElicitationResult  = shared.HookEventElicitationResult
 )
 
+// HookEvents lists every hook event the CLI emits.
+var HookEvents = shared.HookEvents
+
+// HookHandle identifies a hook matcher added at runtime with Client.AddHook
+// or Client.ReplaceHooks.
+type HookHandle = shared.HookHandle
+
+// DefaultRuntimeHookEvents are the events a client dispatches in the SDK
+// by default, and those WithRuntimeHooks dispatches when called without
+// events.
+var DefaultRuntimeHookEvents = shared.DefaultRuntimeHookEvents
+
+// IsKnownHookEvent reports whether name is one of HookEvents.
+var IsKnownHookEvent = shared.IsKnownHookEvent
+
 // Hook input types
 type BaseHookInput = shared.BaseHookInput
 type PreToolUseHookInput = shared.PreToolUseHookInput
@@ -33,8 +56,20 @@ type NotificationHookInput = shared.NotificationHookInput
 type SubagentStartHookInput = shared.SubagentStartHookInput
 type PermissionRequestHookInput = shared.PermissionRequestHookInput
 type PostToolUseFailureHookInput = shared.PostToolUseFailureHookInput
+type SessionStartHookInput = shared.SessionStartHookInput
+type SessionEndHookInput = shared.SessionEndHookInput
+type PostCompactHookInput = shared.PostCompactHookInput
+type SetupHookInput = shared.SetupHookInput
+type TeammateIdleHookInput = shared.TeammateIdleHookInput
+type TaskCompletedHookInput = shared.TaskCompletedHookInput
+type ElicitationHookInput = shared.ElicitationHookInput
+type ElicitationResultHookInput = shared.ElicitationResultHookInput
 type HookInput = shared.HookInput
 
+// DecodeHookInput converts a raw hook input into a pointer to its typed
+// struct (e.g. *SessionStartHookInput), chosen by hook_event_name.
+var DecodeHookInput = shared.DecodeHookInput
+
 // Hook output types
 type PreToolUseHookSpecificOutput = shared.PreToolUseHookSpecificOutput
 type PostToolUseHookSpecificOutput = shared.PostToolUseHookSpecificOutput
@@ -44,6 +79,9 @@ type PostToolUseFailureHookSpecificOutput = shared.PostToolUseFailureHookSpecifi
 type NotificationHookSpecificOutput = shared.NotificationHookSpecificOutput
 type SubagentStartHookSpecificOutput = shared.SubagentStartHookSpecificOutput
 type PermissionRequestHookSpecificOutput = shared.PermissionRequestHookSpecificOutput
+type SetupHookSpecificOutput = shared.SetupHookSpecificOutput
+type ElicitationHookSpecificOutput = shared.ElicitationHookSpecificOutput
+type ElicitationResultHookSpecificOutput = shared.ElicitationResultHookSpecificOutput
 type HookSpecificOutput = shared.HookSpecificOutput
 type AsyncHookJSONOutput = shared.AsyncHookJSONOutput
 type SyncHookJSONOutput = shared.SyncHookJSONOutput
@@ -51,6 +89,19 @@ type HookJSONOutput = shared.HookJSONOutput
 
 // Hook context and callbacks
 type HookContext = shared.HookContext
+
+// AbortSignal fires when a hook or permission callback is aborted and
+// reports why; see HookContext.Signal and ToolPermissionContext.Signal.
+type AbortSignal = shared.AbortSignal
+
+// Abort reasons reported by AbortSignal.Reason; compare with errors.Is.
+var (
+	ErrAbortCancelled    = shared.ErrAbortCancelled
+	ErrAbortTimeout      = shared.ErrAbortTimeout
+	ErrAbortInterrupted  = shared.ErrAbortInterrupted
+	ErrAbortDisconnected = shared.ErrAbortDisconnected
+)
+
 type HookCallback = shared.HookCallback
 type HookMatcher = shared.HookMatcher
 
@@ -66,9 +117,12 @@ const (
 
 // Helper functions
 var (
-	NewPreToolUseOutput  = shared.NewPreToolUseOutput
-	NewPostToolUseOutput = shared.NewPostToolUseOutput
-	NewBlockingOutput    = shared.NewBlockingOutput
-	NewStopOutput        = shared.NewStopOutput
-	NewAsyncOutput       = shared.NewAsyncOutput
+	NewPreToolUseOutput   = shared.NewPreToolUseOutput
+	NewPostToolUseOutput  = shared.NewPostToolUseOutput
+	NewBlockingOutput     = shared.NewBlockingOutput
+	NewStopOutput         = shared.NewStopOutput
+	NewAsyncOutput        = shared.NewAsyncOutput
+	NewSessionStartOutput = shared.NewSessionStartOutput
+	NewSetupOutput        = shared.NewSetupOutput
+	NewElicitationOutput  = shared.NewElicitationOutput
 )
@@ -0,0 +1,94 @@
+package claudesdk
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/parser"
+)
+
+// TestHookEventConformance checks that every hook event the parser can
+// surface in a HookEventMessage has a typed input, a DecodeHookInput
+// mapping and a typed-hook registration.
+func TestHookEventConformance(t *testing.T) {
+	p := parser.New()
+	seen := map[HookEvent]bool{}
+	for _, event := range HookEvents {
+		if seen[event] {
+			t.Errorf("%s listed twice in HookEvents", event)
+		}
+		seen[event] = true
+
+		msg, err := p.ParseMessage(map[string]any{
+			"type":       "system",
+			"subtype":    "hook_started",
+			"hook_event": string(event),
+		})
+		if err != nil {
+			t.Errorf("%s: ParseMessage: %v", event, err)
+			continue
+		}
+		hem, ok := msg.(*HookEventMessage)
+		if !ok || hem.Event() != event || !IsKnownHookEvent(hem.HookEventName) {
+			t.Errorf("%s: parsed as %T %+v", event, msg, msg)
+			continue
+		}
+
+		types, ok := hookEventTypes[event]
+		if !ok {
+			t.Errorf("%s: no typed hook registration", event)
+			continue
+		}
+		input, err := DecodeHookInput(map[string]any{"hook_event_name": string(event), "session_id": "s1"})
+		if err != nil {
+			t.Errorf("%s: DecodeHookInput: %v", event, err)
+			continue
+		}
+		if got := reflect.TypeOf(input).Elem(); got != types[0] {
+			t.Errorf("%s: DecodeHookInput returned %s, typed hooks expect %s", event, got, types[0])
+		}
+		if name := reflect.ValueOf(input).Elem().FieldByName("HookEventName").String(); name != string(event) {
+			t.Errorf("%s: decoded HookEventName = %q", event, name)
+		}
+	}
+
+	for event := range hookEventTypes {
+		if !seen[event] {
+			t.Errorf("typed hook event %s missing from HookEvents", event)
+		}
+	}
+	if IsKnownHookEvent("NotAnEvent") {
+		t.Error("IsKnownHookEvent accepted an unknown event")
+	}
+	if _, err := DecodeHookInput(map[string]any{"hook_event_name": "NotAnEvent"}); err == nil {
+		t.Error("DecodeHookInput accepted an unknown event")
+	}
+}
+
+func TestOnSessionStartAndBuilders(t *testing.T) {
+	opts := NewOptions(OnSessionStart("resume", func(ctx context.Context, in *SessionStartHookInput) (*SessionStartDecision, error) {
+		return &SessionStartDecision{HookSpecificOutput: &SessionStartHookSpecificOutput{AdditionalContext: "source=" + in.Source}}, nil
+	}))
+	matcher := opts.Hooks[string(HookEventSessionStart)][0].(HookMatcher)
+	if matcher.Matcher == nil || *matcher.Matcher != "resume" {
+		t.Errorf("matcher = %v", matcher.Matcher)
+	}
+	out, err := matcher.Hooks[0](map[string]any{"hook_event_name": "SessionStart", "source": "resume"}, nil, HookContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewSessionStartOutput("source=resume")
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("output = %v, want %v", out, want)
+	}
+
+	elicit := NewElicitationOutput("accept", map[string]any{"name": "x"})
+	specific := elicit["hookSpecificOutput"].(map[string]any)
+	if specific["hookEventName"] != "Elicitation" || specific["action"] != "accept" {
+		t.Errorf("NewElicitationOutput = %v", elicit)
+	}
+	if out := NewSetupOutput(""); len(out) != 0 {
+		t.Errorf("NewSetupOutput(\"\") = %v, want empty", out)
+	}
+}
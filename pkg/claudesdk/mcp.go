@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
 	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
@@ -26,6 +27,8 @@ func convertContentsToMCP(contents []ToolContent) ([]mcp.Content, error) {
 			mcpContents[i] = &mcp.ResourceLinkContent{Type: mcp.ContentTypeResourceLink, Name: c.name, URI: c.uri, Description: c.description}
 		case *ResourceContent:
 			mcpContents[i] = &mcp.ResourceContent{Type: mcp.ContentTypeResource, Resource: mcp.EmbeddedResource{URI: c.uri, Text: c.text, Blob: c.blob, MimeType: c.mimeType}}
+		case *StructuredContent:
+			mcpContents[i] = &mcp.StructuredContent{Value: c.value}
 		default:
 			return nil, fmt.Errorf("unsupported content type: %T", content)
 		}
@@ -33,6 +36,29 @@ func convertContentsToMCP(contents []ToolContent) ([]mcp.Content, error) {
 	return mcpContents, nil
 }
 
+// convertContentsFromMCP converts internal mcp.Content values, as decoded
+// from a server response, into public ToolContent values.
+func convertContentsFromMCP(contents []mcp.Content) []ToolContent {
+	result := make([]ToolContent, 0, len(contents))
+	for _, content := range contents {
+		switch c := content.(type) {
+		case *mcp.TextContent:
+			result = append(result, NewTextContent(c.Text))
+		case *mcp.ImageContent:
+			result = append(result, NewImageContent(c.Data, c.MimeType))
+		case *mcp.AudioContent:
+			result = append(result, NewAudioContent(c.Data, c.MimeType))
+		case *mcp.ResourceLinkContent:
+			result = append(result, NewResourceLinkContent(c.Name, c.URI, c.Description))
+		case *mcp.ResourceContent:
+			result = append(result, &ResourceContent{uri: c.Resource.URI, text: c.Resource.Text, blob: c.Resource.Blob, mimeType: c.Resource.MimeType})
+		case *mcp.StructuredContent:
+			result = append(result, NewStructuredContent(c.Value))
+		}
+	}
+	return result
+}
+
 // ToolHandler is a function that handles tool execution.
 // It receives the tool arguments and returns content (text or images).
 //
@@ -45,6 +71,13 @@ func convertContentsToMCP(contents []ToolContent) ([]mcp.Content, error) {
 //	    }, nil
 //	}
 //
+// ctx is cancelled when the CLI abandons the call (interrupt,
+// control_cancel_request, MCP notifications/cancelled), when the tool's
+// Timeout elapses, or when the client disconnects. The cancellation is
+// reported to Claude as an is_error result, so handlers only need to stop
+// work and return. The call is answered without waiting for the handler,
+// which keeps running until it returns, so handlers must honor ctx.
+//
 // To return a tool-level error with custom content (Python parity for
 // `{"content": [...], "is_error": true}`), return a *ToolError. Plain
 // errors are converted to is_error=true with the error text as content.
@@ -229,6 +262,50 @@ func (r *ResourceContent) GetType() string {
 	return "resource"
 }
 
+// URI returns the resource URI.
+func (r *ResourceContent) URI() string {
+	return r.uri
+}
+
+// Text returns the text contents, if the resource is textual.
+func (r *ResourceContent) Text() string {
+	return r.text
+}
+
+// Blob returns the base64-encoded contents, if the resource is binary.
+func (r *ResourceContent) Blob() string {
+	return r.blob
+}
+
+// MimeType returns the resource MIME type.
+func (r *ResourceContent) MimeType() string {
+	return r.mimeType
+}
+
+// StructuredContent is a tool's structured result. It is sent as the MCP
+// result's structuredContent (matching the tool's OutputSchema) instead of
+// as a content block; return a TextContent alongside it for clients that
+// only read content.
+type StructuredContent struct {
+	value any
+}
+
+// NewStructuredContent creates structured content from a JSON-serializable
+// value, normally a struct or map.
+func NewStructuredContent(value any) *StructuredContent {
+	return &StructuredContent{value: value}
+}
+
+// GetType returns the content type.
+func (s *StructuredContent) GetType() string {
+	return "structured"
+}
+
+// Value returns the structured value.
+func (s *StructuredContent) Value() any {
+	return s.value
+}
+
 // ToolDef defines a tool with its schema and handler.
 //
 // Example:
@@ -257,8 +334,17 @@ type ToolDef struct {
 	// - map[string]interface{}: Full JSON Schema
 	InputSchema interface{}
 
+	// OutputSchema optionally describes the tool's structured result (see
+	// StructuredContent). Accepts the same forms as InputSchema.
+	OutputSchema interface{}
+
 	// Handler is the function that executes the tool.
 	Handler ToolHandler
+
+	// Timeout bounds a single call. When it elapses the handler's ctx is
+	// cancelled and the call is reported as an is_error result. Zero means
+	// no limit.
+	Timeout time.Duration
 }
 
 // SdkMcpTool is the public SDK MCP tool definition type.
@@ -308,40 +394,7 @@ func CreateSDKMcpServer(name string, version string, tools ...*ToolDef) *shared.
 		if tool == nil {
 			continue
 		}
-
-		// Wrap the handler to convert between public and internal types
-		wrappedHandler := func(ctx context.Context, args map[string]interface{}) ([]mcp.Content, error) {
-			// Call the user's handler
-			contents, err := tool.Handler(ctx, args)
-			if err != nil {
-				// ToolError signals a tool-level error: surface as a
-				// successful response with is_error=true plus the carried
-				// Content. The internal MCP bridge does this when the
-				// returned error is *mcp.ToolErrorContent.
-				var te *ToolError
-				if errors.As(err, &te) {
-					mcpContents, convErr := convertContentsToMCP(te.Content)
-					if convErr != nil {
-						return nil, convErr
-					}
-					return nil, &mcp.ToolErrorContent{Content: mcpContents, Message: te.Message}
-				}
-				return nil, err
-			}
-
-			// Convert public ToolContent to internal mcp.Content
-			return convertContentsToMCP(contents)
-		}
-
-		// Register with internal server
-		err := server.RegisterTool(&mcp.ToolDefinition{
-			Name:        tool.Name,
-			Description: tool.Description,
-			InputSchema: tool.InputSchema,
-			Annotations: tool.Annotations,
-			Handler:     wrappedHandler,
-		})
-		if err != nil {
+		if err := registerSDKTool(server, tool); err != nil {
 			// Log error but continue (don't fail server creation)
 			fmt.Printf("Warning: failed to register tool %s: %v\n", tool.Name, err)
 		}
@@ -355,6 +408,60 @@ func CreateSDKMcpServer(name string, version string, tools ...*ToolDef) *shared.
 	}
 }
 
+// toolResultToMCP converts a public tool result into the internal shape.
+// ToolError signals a tool-level error: it becomes *mcp.ToolErrorContent,
+// which the internal MCP bridge surfaces as a successful response with
+// is_error=true plus the carried Content.
+func toolResultToMCP(contents []ToolContent, err error) ([]mcp.Content, error) {
+	if err != nil {
+		var te *ToolError
+		if errors.As(err, &te) {
+			mcpContents, convErr := convertContentsToMCP(te.Content)
+			if convErr != nil {
+				return nil, convErr
+			}
+			return nil, &mcp.ToolErrorContent{Content: mcpContents, Message: te.Message}
+		}
+		return nil, err
+	}
+	return convertContentsToMCP(contents)
+}
+
+// toolResultFromMCP is the inverse of toolResultToMCP.
+func toolResultFromMCP(contents []mcp.Content, err error) ([]ToolContent, error) {
+	if err != nil {
+		var tec *mcp.ToolErrorContent
+		if errors.As(err, &tec) {
+			return nil, &ToolError{Content: convertContentsFromMCP(tec.Content), Message: tec.Message}
+		}
+		return nil, err
+	}
+	return convertContentsFromMCP(contents), nil
+}
+
+// registerSDKTool wraps a public ToolDef and registers it with server.
+func registerSDKTool(server *mcp.Server, tool *ToolDef) error {
+	if tool.Handler == nil {
+		return fmt.Errorf("tool handler cannot be nil")
+	}
+
+	// Wrap the handler to convert between public and internal types
+	wrappedHandler := func(ctx context.Context, args map[string]interface{}) ([]mcp.Content, error) {
+		return toolResultToMCP(tool.Handler(ctx, args))
+	}
+
+	// Register with internal server
+	return server.RegisterTool(&mcp.ToolDefinition{
+		Name:         tool.Name,
+		Description:  tool.Description,
+		InputSchema:  tool.InputSchema,
+		Annotations:  tool.Annotations,
+		Handler:      wrappedHandler,
+		OutputSchema: tool.OutputSchema,
+		Timeout:      tool.Timeout,
+	})
+}
+
 // Tool is a convenience function that creates a ToolDef.
 // It's equivalent to creating a ToolDef struct but more concise.
 //
@@ -389,3 +496,71 @@ func ToolWithAnnotations(
 		Handler:     handler,
 	}
 }
+
+// SetSDKMcpArgumentValidation enables or disables validating tools/call
+// arguments against each tool's InputSchema on a server created with
+// CreateSDKMcpServer. When enabled, calls with missing or mistyped
+// arguments, out-of-range numbers, unmatched patterns or values outside an
+// enum are answered with a descriptive is_error result and the handler is
+// not run. Disabled by default.
+//
+// Example:
+//
+//	server := CreateSDKMcpServer("my-tools", "1.0.0", greet)
+//	if err := SetSDKMcpArgumentValidation(server, true); err != nil {
+//	    return err
+//	}
+func SetSDKMcpArgumentValidation(server *McpSdkServerConfig, enabled bool) error {
+	instance, err := sdkMcpServerInstance(server)
+	if err != nil {
+		return err
+	}
+	instance.SetArgumentValidation(enabled)
+	return nil
+}
+
+// RegisterSDKMcpTools adds tools to a server created with
+// CreateSDKMcpServer, replacing tools of the same name. It may be called
+// while a Client is connected: the CLI is sent
+// notifications/tools/list_changed and picks up the new tools on its next
+// tools/list.
+//
+// Example:
+//
+//	if flags.Enabled("search") {
+//	    if err := RegisterSDKMcpTools(server, searchTool); err != nil {
+//	        return err
+//	    }
+//	}
+func RegisterSDKMcpTools(server *McpSdkServerConfig, tools ...*ToolDef) error {
+	instance, err := sdkMcpServerInstance(server)
+	if err != nil {
+		return err
+	}
+	for _, tool := range tools {
+		if tool == nil {
+			return fmt.Errorf("tool definition cannot be nil")
+		}
+		if err := registerSDKTool(instance, tool); err != nil {
+			return fmt.Errorf("failed to register tool %s: %w", tool.Name, err)
+		}
+	}
+	return nil
+}
+
+// UnregisterSDKMcpTools removes tools by name from a server created with
+// CreateSDKMcpServer and notifies the CLI as RegisterSDKMcpTools does.
+// Calls already running finish normally. Unknown names are an error; the
+// names before it are still removed.
+func UnregisterSDKMcpTools(server *McpSdkServerConfig, names ...string) error {
+	instance, err := sdkMcpServerInstance(server)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if !instance.UnregisterTool(name) {
+			return fmt.Errorf("tool %s is not registered", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package claudesdk
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcpclient"
+)
+
+// McpTool describes a tool offered by an MCP server.
+type McpTool = mcp.Tool
+
+// McpResource describes a static resource offered by an MCP server.
+type McpResource = mcp.Resource
+
+// McpResourceTemplate describes a resource template offered by an MCP server.
+type McpResourceTemplate = mcp.ResourceTemplate
+
+// McpPrompt describes a prompt offered by an MCP server.
+type McpPrompt = mcp.Prompt
+
+// McpInitializeResult is a server's answer to the initialize handshake.
+type McpInitializeResult = mcpclient.InitializeResult
+
+// McpProgress is a progress update for a request made with WithMcpProgress.
+type McpProgress = mcpclient.Progress
+
+// McpError is a JSON-RPC error returned by an MCP server.
+type McpError = mcpclient.RPCError
+
+// ErrMcpClientClosed is returned by McpClient requests made after, or still
+// pending when, the connection closes.
+var ErrMcpClientClosed = mcpclient.ErrClosed
+
+// McpClientOption configures ConnectMcpServer.
+type McpClientOption = mcpclient.Option
+
+// WithMcpClientInfo sets the client name and version sent to the server.
+func WithMcpClientInfo(name, version string) McpClientOption {
+	return mcpclient.WithClientInfo(name, version)
+}
+
+// WithMcpNotificationHandler receives server notifications such as
+// notifications/message and notifications/tools/list_changed.
+func WithMcpNotificationHandler(handler func(method string, params json.RawMessage)) McpClientOption {
+	return mcpclient.WithNotificationHandler(handler)
+}
+
+// WithMcpProgress returns a context whose McpClient requests ask the server
+// for progress updates and deliver them to fn.
+func WithMcpProgress(ctx context.Context, fn func(McpProgress)) context.Context {
+	return mcpclient.WithProgress(ctx, fn)
+}
+
+// McpToolResult is the result of McpClient.CallTool.
+type McpToolResult struct {
+	Content           []ToolContent
+	StructuredContent any
+	// IsError reports a tool-level failure; Content describes it.
+	IsError bool
+}
+
+// McpPromptResult is the result of McpClient.GetPrompt.
+type McpPromptResult struct {
+	Description string
+	Messages    []PromptMessage
+}
+
+// McpClient is a connection to an MCP server, for talking to the servers
+// configured in Options.McpServers directly from Go: pre-flight checks,
+// tests, or proxying. Its methods are safe for concurrent use.
+type McpClient struct {
+	client *mcpclient.Client
+}
+
+// ConnectMcpServer connects to the server described by config and performs
+// the initialize handshake. Stdio servers are started as subprocesses, SSE
+// and HTTP servers are reached at their URL, and servers created with
+// CreateSDKMcpServer are called in-process. Close the client when done.
+//
+// Example:
+//
+//	client, err := claudesdk.ConnectMcpServer(ctx, &claudesdk.McpStdioServerConfig{
+//	    Type:    claudesdk.McpServerTypeStdio,
+//	    Command: "npx",
+//	    Args:    []string{"-y", "@modelcontextprotocol/server-filesystem", "."},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer client.Close()
+//	tools, err := client.ListTools(ctx)
+func ConnectMcpServer(ctx context.Context, config McpServerConfig, opts ...McpClientOption) (*McpClient, error) {
+	client, err := mcpclient.ConnectConfig(ctx, config, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &McpClient{client: client}, nil
+}
+
+// ServerInfo returns the server's initialize result.
+func (c *McpClient) ServerInfo() McpInitializeResult {
+	return c.client.ServerInfo()
+}
+
+// Ping checks that the server is responsive.
+func (c *McpClient) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx)
+}
+
+// ListTools returns the server's tools.
+func (c *McpClient) ListTools(ctx context.Context) ([]McpTool, error) {
+	return c.client.ListTools(ctx)
+}
+
+// CallTool invokes a tool. Cancelling ctx sends notifications/cancelled to
+// the server.
+func (c *McpClient) CallTool(ctx context.Context, name string, args map[string]any) (*McpToolResult, error) {
+	result, err := c.client.CallTool(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	return &McpToolResult{
+		Content:           convertContentsFromMCP(result.Content),
+		StructuredContent: result.StructuredContent,
+		IsError:           result.IsError,
+	}, nil
+}
+
+// ListResources returns the server's static resources.
+func (c *McpClient) ListResources(ctx context.Context) ([]McpResource, error) {
+	return c.client.ListResources(ctx)
+}
+
+// ListResourceTemplates returns the server's resource templates.
+func (c *McpClient) ListResourceTemplates(ctx context.Context) ([]McpResourceTemplate, error) {
+	return c.client.ListResourceTemplates(ctx)
+}
+
+// ReadResource reads the resource identified by uri.
+func (c *McpClient) ReadResource(ctx context.Context, uri string) ([]*ResourceContent, error) {
+	contents, err := c.client.ReadResource(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ResourceContent, len(contents))
+	for i, r := range contents {
+		result[i] = &ResourceContent{uri: r.URI, text: r.Text, blob: r.Blob, mimeType: r.MimeType}
+	}
+	return result, nil
+}
+
+// ListPrompts returns the server's prompts.
+func (c *McpClient) ListPrompts(ctx context.Context) ([]McpPrompt, error) {
+	return c.client.ListPrompts(ctx)
+}
+
+// GetPrompt renders a prompt with the given arguments.
+func (c *McpClient) GetPrompt(ctx context.Context, name string, args map[string]string) (*McpPromptResult, error) {
+	prompt, err := c.client.GetPrompt(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	result := &McpPromptResult{Description: prompt.Description, Messages: make([]PromptMessage, 0, len(prompt.Messages))}
+	for _, msg := range prompt.Messages {
+		var content ToolContent
+		if converted := convertContentsFromMCP([]mcp.Content{msg.Content}); len(converted) > 0 {
+			content = converted[0]
+		}
+		result.Messages = append(result.Messages, PromptMessage{Role: msg.Role, Content: content})
+	}
+	return result, nil
+}
+
+// SetLogLevel sets the minimum level of log notifications the server sends.
+func (c *McpClient) SetLogLevel(ctx context.Context, level McpLogLevel) error {
+	return c.client.SetLogLevel(ctx, level)
+}
+
+// Close disconnects from the server, stopping it if it is a subprocess.
+func (c *McpClient) Close() error {
+	return c.client.Close()
+}
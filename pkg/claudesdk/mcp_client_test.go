@@ -0,0 +1,56 @@
+package claudesdk
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConnectMcpServerInProcess(t *testing.T) {
+	greet := Tool("greet", "Greet someone", map[string]any{"name": "string"},
+		func(ctx context.Context, args map[string]any) ([]ToolContent, error) {
+			_ = ToolNotifierFromContext(ctx).Progress(1, 1, "")
+			return []ToolContent{NewTextContent("hello " + args["name"].(string))}, nil
+		})
+	server := CreateSDKMcpServer("local", "1.0.0", greet)
+	if err := AddSDKMcpResources(server, &ResourceDef{
+		URI: "mem://note",
+		Handler: func(ctx context.Context, uri string) ([]*ResourceContent, error) {
+			return []*ResourceContent{NewResourceTextContent("", "remember", "text/plain")}, nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	client, err := ConnectMcpServer(ctx, server)
+	if err != nil {
+		t.Fatalf("ConnectMcpServer: %v", err)
+	}
+	defer client.Close()
+
+	if info := client.ServerInfo(); info.ServerInfo.Name != "local" {
+		t.Errorf("server info = %+v", info)
+	}
+	tools, err := client.ListTools(ctx)
+	if err != nil || len(tools) != 1 || tools[0].Name != "greet" {
+		t.Fatalf("ListTools = %v, %v", tools, err)
+	}
+
+	progressed := false
+	result, err := client.CallTool(WithMcpProgress(ctx, func(McpProgress) { progressed = true }), "greet", map[string]any{"name": "Ada"})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	text, ok := result.Content[0].(*TextContent)
+	if !ok || text.Text() != "hello Ada" || result.IsError {
+		t.Errorf("CallTool = %+v", result)
+	}
+	if !progressed {
+		t.Error("progress callback not called")
+	}
+
+	contents, err := client.ReadResource(ctx, "mem://note")
+	if err != nil || len(contents) != 1 || contents[0].Text() != "remember" || contents[0].URI() != "mem://note" {
+		t.Errorf("ReadResource = %v, %v", contents, err)
+	}
+}
@@ -0,0 +1,126 @@
+package claudesdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+)
+
+// ServeSDKMcpStdio serves a server created with CreateSDKMcpServer over the
+// MCP stdio transport (newline-delimited JSON-RPC), reading from r and
+// writing to w. Pass os.Stdin and os.Stdout to run the process as an MCP
+// server, for example as the Command of an McpStdioServerConfig or from any
+// other MCP client. Keep other output off w.
+//
+// It returns nil when r reaches EOF, or ctx's error when ctx ends first. A
+// server should be hosted by one transport at a time.
+//
+// Example:
+//
+//	func main() {
+//	    server := claudesdk.CreateSDKMcpServer("my-tools", "1.0.0", greet)
+//	    if err := claudesdk.ServeSDKMcpStdio(context.Background(), server, os.Stdin, os.Stdout); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+func ServeSDKMcpStdio(ctx context.Context, server *McpSdkServerConfig, r io.Reader, w io.Writer) error {
+	instance, err := sdkMcpServerInstance(server)
+	if err != nil {
+		return err
+	}
+	return instance.ServeStdio(ctx, r, w)
+}
+
+// McpHTTPOption configures NewSDKMcpHTTPHandler and ServeSDKMcpHTTP.
+type McpHTTPOption func(*mcp.HTTPOptions)
+
+// WithMcpHTTPAllowedOrigins accepts browser requests from the given Origin
+// values in addition to loopback origins. "*" accepts any origin.
+func WithMcpHTTPAllowedOrigins(origins ...string) McpHTTPOption {
+	return func(o *mcp.HTTPOptions) {
+		o.AllowedOrigins = append(o.AllowedOrigins, origins...)
+	}
+}
+
+// NewSDKMcpHTTPHandler returns an http.Handler that serves a server created
+// with CreateSDKMcpServer over MCP Streamable HTTP, plus the older HTTP+SSE
+// transport at <mount>/sse and <mount>/messages. Mount it on your own mux,
+// e.g. mux.Handle("/mcp", handler) and mux.Handle("/mcp/", handler).
+//
+// Requests carrying a non-loopback Origin are rejected unless allowed with
+// WithMcpHTTPAllowedOrigins. The handler receives the server's
+// notifications alongside a client it is also attached to; it implements
+// io.Closer to stop.
+func NewSDKMcpHTTPHandler(server *McpSdkServerConfig, opts ...McpHTTPOption) (http.Handler, error) {
+	instance, err := sdkMcpServerInstance(server)
+	if err != nil {
+		return nil, err
+	}
+	var options mcp.HTTPOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return mcp.NewHTTPHandler(instance, options), nil
+}
+
+// ServeSDKMcpHTTP listens on addr and serves a server created with
+// CreateSDKMcpServer at /mcp (Streamable HTTP) and /mcp/sse (HTTP+SSE)
+// until ctx ends. Bind to a loopback address such as "127.0.0.1:8931"
+// unless the server is meant to be reachable from other hosts.
+//
+// If ready is non-nil it is called with the bound address once the
+// listener is open, which is useful with port 0. Register the server with
+// the CLI as
+//
+//	&McpHTTPServerConfig{Type: McpServerTypeHTTP, URL: "http://" + addr + "/mcp"}
+func ServeSDKMcpHTTP(ctx context.Context, server *McpSdkServerConfig, addr string, ready func(addr net.Addr), opts ...McpHTTPOption) error {
+	handler, err := NewSDKMcpHTTPHandler(server, opts...)
+	if err != nil {
+		return err
+	}
+	if closer, ok := handler.(io.Closer); ok {
+		defer closer.Close()
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", handler)
+	mux.Handle("/mcp/", handler)
+	return serveHTTP(ctx, mux, addr, ready)
+}
+
+// serveHTTP listens on addr and serves handler until ctx ends, calling
+// ready with the bound address once the listener is open.
+func serveHTTP(ctx context.Context, handler http.Handler, addr string, ready func(addr net.Addr)) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	if ready != nil {
+		ready(listener.Addr())
+	}
+
+	httpServer := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		// Open streams end with their requests' contexts; Close rather
+		// than Shutdown so they do not hold the server open.
+		_ = httpServer.Close()
+		<-errCh
+		return ctx.Err()
+	}
+}
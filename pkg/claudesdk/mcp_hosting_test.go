@@ -0,0 +1,51 @@
+package claudesdk
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeSDKMcpHTTP(t *testing.T) {
+	greet := Tool("greet", "Greet someone", map[string]any{"name": "string"},
+		func(ctx context.Context, args map[string]any) ([]ToolContent, error) {
+			return []ToolContent{NewTextContent("hello " + args["name"].(string))}, nil
+		})
+	server := CreateSDKMcpServer("hosted", "1.0.0", greet)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	addrCh := make(chan net.Addr, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- ServeSDKMcpHTTP(ctx, server, "127.0.0.1:0", func(addr net.Addr) { addrCh <- addr })
+	}()
+
+	var addr net.Addr
+	select {
+	case addr = <-addrCh:
+	case err := <-done:
+		t.Fatalf("ServeSDKMcpHTTP: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not start")
+	}
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"greet","arguments":{"name":"Ada"}}}`
+	resp, err := http.Post("http://"+addr.String()+"/mcp", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(out), "hello Ada") {
+		t.Errorf("response = %s", out)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,106 @@
+package claudesdk
+
+import (
+	"context"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+)
+
+// McpToolCall describes one SDK MCP tool invocation as seen by middleware:
+// the tool name, its (validated) arguments and its annotations.
+type McpToolCall = mcp.ToolCall
+
+// ToolInvoker runs a tool call and returns its result.
+type ToolInvoker func(ctx context.Context, call *McpToolCall) ([]ToolContent, error)
+
+// ToolMiddleware wraps a ToolInvoker to add cross-cutting behavior such as
+// panic recovery, timeouts, rate limiting, audit logging, metrics or
+// argument redaction. Middleware may change the call before invoking next,
+// inspect or replace the result afterwards (a *ToolError result is a
+// tool-level failure), or return without calling next at all.
+type ToolMiddleware func(next ToolInvoker) ToolInvoker
+
+// ErrToolPanicked is wrapped by the error RecoverToolMiddleware returns
+// when a tool handler panics.
+var ErrToolPanicked = mcp.ErrToolPanicked
+
+// UseSDKMcpMiddleware appends middleware to a server created with
+// CreateSDKMcpServer. It wraps every tool, including tools registered
+// later, and the first middleware is the outermost.
+//
+// Example:
+//
+//	limiter := rate.NewLimiter(5, 1)
+//	rateLimit := func(next ToolInvoker) ToolInvoker {
+//	    return func(ctx context.Context, call *McpToolCall) ([]ToolContent, error) {
+//	        if !limiter.Allow() {
+//	            return nil, NewToolError("rate limit exceeded, try again shortly")
+//	        }
+//	        return next(ctx, call)
+//	    }
+//	}
+//	err := UseSDKMcpMiddleware(server,
+//	    LoggingToolMiddleware(func(msg string) { log.Print(msg) }),
+//	    RecoverToolMiddleware(),
+//	    rateLimit,
+//	)
+func UseSDKMcpMiddleware(server *McpSdkServerConfig, middleware ...ToolMiddleware) error {
+	instance, err := sdkMcpServerInstance(server)
+	if err != nil {
+		return err
+	}
+	for _, mw := range middleware {
+		if mw != nil {
+			instance.Use(toMCPMiddleware(mw))
+		}
+	}
+	return nil
+}
+
+// RecoverToolMiddleware turns a panic in a tool handler into an is_error
+// result wrapping ErrToolPanicked instead of crashing the process. It
+// covers the handler and the middleware after it; middleware before it sees
+// the panic as an ordinary error. TimeoutToolMiddleware recovers panics
+// after it in the same way, so the two work in either order.
+func RecoverToolMiddleware() ToolMiddleware {
+	return fromMCPMiddleware(mcp.RecoverMiddleware())
+}
+
+// TimeoutToolMiddleware bounds every tool call by d, in addition to any
+// per-tool ToolDef.Timeout. When d elapses the handler's ctx is cancelled
+// and the call is reported as an is_error result.
+func TimeoutToolMiddleware(d time.Duration) ToolMiddleware {
+	return fromMCPMiddleware(mcp.TimeoutMiddleware(d))
+}
+
+// LoggingToolMiddleware reports each tool call's outcome and duration to
+// log. Arguments are not logged.
+func LoggingToolMiddleware(log func(message string)) ToolMiddleware {
+	return fromMCPMiddleware(mcp.LoggingMiddleware(log))
+}
+
+// toMCPMiddleware adapts a public middleware to the internal content types.
+func toMCPMiddleware(mw ToolMiddleware) mcp.ToolMiddleware {
+	return func(next mcp.ToolInvoker) mcp.ToolInvoker {
+		wrapped := mw(func(ctx context.Context, call *McpToolCall) ([]ToolContent, error) {
+			return toolResultFromMCP(next(ctx, call))
+		})
+		return func(ctx context.Context, call *mcp.ToolCall) ([]mcp.Content, error) {
+			return toolResultToMCP(wrapped(ctx, call))
+		}
+	}
+}
+
+// fromMCPMiddleware adapts an internal middleware to the public content
+// types.
+func fromMCPMiddleware(mw mcp.ToolMiddleware) ToolMiddleware {
+	return func(next ToolInvoker) ToolInvoker {
+		wrapped := mw(func(ctx context.Context, call *mcp.ToolCall) ([]mcp.Content, error) {
+			return toolResultToMCP(next(ctx, call))
+		})
+		return func(ctx context.Context, call *McpToolCall) ([]ToolContent, error) {
+			return toolResultFromMCP(wrapped(ctx, call))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package claudesdk
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestUseSDKMcpMiddleware(t *testing.T) {
+	boom := Tool("boom", "Panics", nil, func(ctx context.Context, args map[string]any) ([]ToolContent, error) {
+		panic("kaboom")
+	})
+	denied := ToolWithAnnotations("wipe", "Destructive", nil, ToolAnnotations{"destructiveHint": true},
+		func(ctx context.Context, args map[string]any) ([]ToolContent, error) {
+			t.Error("destructive tool should not run")
+			return nil, nil
+		})
+	echo := Tool("echo", "Echo", nil, func(ctx context.Context, args map[string]any) ([]ToolContent, error) {
+		return []ToolContent{NewTextContent("echo")}, nil
+	})
+	server := CreateSDKMcpServer("mw", "1.0.0", boom, denied, echo)
+
+	var audit []string
+	guard := func(next ToolInvoker) ToolInvoker {
+		return func(ctx context.Context, call *McpToolCall) ([]ToolContent, error) {
+			if call.Annotations["destructiveHint"] == true {
+				return nil, NewToolError("blocked by policy")
+			}
+			content, err := next(ctx, call)
+			if err == nil {
+				content = append(content, NewTextContent("audited"))
+			}
+			return content, err
+		}
+	}
+	err := UseSDKMcpMiddleware(server,
+		LoggingToolMiddleware(func(msg string) { audit = append(audit, msg) }),
+		RecoverToolMiddleware(),
+		guard,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := callSDKTool(t, server, "tools/call", map[string]any{"name": "boom"})
+	if result["is_error"] != true || !strings.Contains(firstText(result), "kaboom") {
+		t.Errorf("boom = %v", result)
+	}
+	result = callSDKTool(t, server, "tools/call", map[string]any{"name": "wipe"})
+	if result["is_error"] != true || firstText(result) != "blocked by policy" {
+		t.Errorf("wipe = %v", result)
+	}
+	result = callSDKTool(t, server, "tools/call", map[string]any{"name": "echo"})
+	if content := result["content"].([]any); len(content) != 2 || content[1].(map[string]any)["text"] != "audited" {
+		t.Errorf("echo = %v", result)
+	}
+	if len(audit) != 3 {
+		t.Errorf("audit = %q", audit)
+	}
+}
+
+func firstText(result map[string]any) string {
+	content, _ := result["content"].([]any)
+	if len(content) == 0 {
+		return ""
+	}
+	text, _ := content[0].(map[string]any)["text"].(string)
+	return text
+}
@@ -0,0 +1,61 @@
+package claudesdk
+
+import (
+	"context"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+)
+
+// McpLogLevel is the severity of a log record sent by an SDK MCP tool.
+type McpLogLevel = mcp.LoggingLevel
+
+const (
+	McpLogDebug     McpLogLevel = mcp.LoggingLevelDebug
+	McpLogInfo      McpLogLevel = mcp.LoggingLevelInfo
+	McpLogNotice    McpLogLevel = mcp.LoggingLevelNotice
+	McpLogWarning   McpLogLevel = mcp.LoggingLevelWarning
+	McpLogError     McpLogLevel = mcp.LoggingLevelError
+	McpLogCritical  McpLogLevel = mcp.LoggingLevelCritical
+	McpLogAlert     McpLogLevel = mcp.LoggingLevelAlert
+	McpLogEmergency McpLogLevel = mcp.LoggingLevelEmergency
+)
+
+// ToolNotifier reports progress and log records for the tool call it was
+// obtained for. Notifications travel to the CLI over the control protocol's
+// mcp_message channel.
+type ToolNotifier struct {
+	notifier *mcp.RequestNotifier
+}
+
+// ToolNotifierFromContext returns the notifier for the tool call running
+// under ctx. It never returns nil; outside an SDK MCP tool call its methods
+// do nothing.
+//
+// Example:
+//
+//	func indexHandler(ctx context.Context, args map[string]interface{}) ([]ToolContent, error) {
+//	    notifier := ToolNotifierFromContext(ctx)
+//	    for i, file := range files {
+//	        notifier.Progress(float64(i), float64(len(files)), "indexing "+file)
+//	        if err := index(ctx, file); err != nil {
+//	            notifier.Log(McpLogWarning, map[string]any{"file": file, "error": err.Error()})
+//	        }
+//	    }
+//	    return []ToolContent{NewTextContent("indexed")}, nil
+//	}
+func ToolNotifierFromContext(ctx context.Context) *ToolNotifier {
+	return &ToolNotifier{notifier: mcp.NotifierFromContext(ctx)}
+}
+
+// Progress sends notifications/progress. total and message are omitted when
+// zero. It is a no-op unless the CLI supplied a progressToken for the call.
+func (n *ToolNotifier) Progress(progress, total float64, message string) error {
+	return n.notifier.Progress(progress, total, message)
+}
+
+// Log sends a notifications/message record with the tool name as logger.
+// data may be any JSON-serializable value. Records below the level the CLI
+// set with logging/setLevel are dropped.
+func (n *ToolNotifier) Log(level McpLogLevel, data any) error {
+	return n.notifier.Log(level, data)
+}
@@ -0,0 +1,230 @@
+package claudesdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+)
+
+// PromptArgument describes an argument accepted by an MCP prompt. Prompt
+// arguments are always transmitted as strings.
+type PromptArgument struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// PromptMessage is one message of a rendered prompt. Content may be any
+// ToolContent (text, image, audio, resource link or embedded resource).
+type PromptMessage struct {
+	// Role is "user" or "assistant".
+	Role    string
+	Content ToolContent
+}
+
+// NewUserPromptMessage creates a prompt message with the user role.
+func NewUserPromptMessage(content ToolContent) PromptMessage {
+	return PromptMessage{Role: "user", Content: content}
+}
+
+// NewAssistantPromptMessage creates a prompt message with the assistant role.
+func NewAssistantPromptMessage(content ToolContent) PromptMessage {
+	return PromptMessage{Role: "assistant", Content: content}
+}
+
+// PromptHandler renders a prompt from its arguments.
+type PromptHandler func(ctx context.Context, args map[string]string) ([]PromptMessage, error)
+
+// PromptDef defines a reusable prompt exposed by an SDK MCP server. The CLI
+// surfaces MCP prompts as slash commands.
+//
+// Example:
+//
+//	review := &PromptDef{
+//	    Name:        "review",
+//	    Description: "Review a file for bugs",
+//	    Arguments:   []PromptArgument{{Name: "path", Required: true}},
+//	    Handler: func(ctx context.Context, args map[string]string) ([]PromptMessage, error) {
+//	        return []PromptMessage{
+//	            NewUserPromptMessage(NewTextContent("Review " + args["path"] + " for bugs.")),
+//	        }, nil
+//	    },
+//	}
+type PromptDef struct {
+	// Name is the unique identifier for the prompt.
+	Name string
+
+	// Title is an optional human-readable title.
+	Title string
+
+	// Description explains what the prompt does.
+	Description string
+
+	// Arguments lists the arguments the prompt accepts. Missing required
+	// arguments are rejected before Handler runs.
+	Arguments []PromptArgument
+
+	// Handler renders the prompt messages.
+	Handler PromptHandler
+}
+
+// TypedPrompt creates a PromptDef whose arguments are described by the
+// struct type A. Each exported field becomes an argument named by its json
+// tag; fields tagged omitempty or of pointer type are optional, and the
+// description tag supplies the argument description. Non-string fields are
+// parsed from the argument string as JSON (numbers, booleans).
+//
+// Example:
+//
+//	type reviewArgs struct {
+//	    Path  string `json:"path" description:"File to review"`
+//	    Depth int    `json:"depth,omitempty"`
+//	}
+//	review := TypedPrompt("review", "Review a file",
+//	    func(ctx context.Context, args reviewArgs) ([]PromptMessage, error) {
+//	        return []PromptMessage{NewUserPromptMessage(NewTextContent("Review " + args.Path))}, nil
+//	    })
+func TypedPrompt[A any](name, description string, handler func(ctx context.Context, args A) ([]PromptMessage, error)) *PromptDef {
+	arguments, stringFields := promptArgumentsForType(reflect.TypeOf((*A)(nil)).Elem())
+	return &PromptDef{
+		Name:        name,
+		Description: description,
+		Arguments:   arguments,
+		Handler: func(ctx context.Context, raw map[string]string) ([]PromptMessage, error) {
+			values := make(map[string]any, len(raw))
+			for key, value := range raw {
+				if stringFields[key] {
+					values[key] = value
+					continue
+				}
+				var parsed any
+				if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+					return nil, fmt.Errorf("invalid value for argument %s: %w", key, err)
+				}
+				values[key] = parsed
+			}
+			data, err := json.Marshal(values)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode prompt arguments: %w", err)
+			}
+			var args A
+			if err := json.Unmarshal(data, &args); err != nil {
+				return nil, fmt.Errorf("failed to decode prompt arguments: %w", err)
+			}
+			return handler(ctx, args)
+		},
+	}
+}
+
+// promptArgumentsForType derives prompt arguments from a struct type and
+// reports which of them are plain strings.
+func promptArgumentsForType(t reflect.Type) ([]PromptArgument, map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	stringFields := make(map[string]bool)
+	if t.Kind() != reflect.Struct {
+		return nil, stringFields
+	}
+
+	var arguments []PromptArgument
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		required := field.Type.Kind() != reflect.Pointer
+		if tag := field.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, part := range parts[1:] {
+				if part == "omitempty" {
+					required = false
+				}
+			}
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.String {
+			stringFields[name] = true
+		}
+		arguments = append(arguments, PromptArgument{
+			Name:        name,
+			Description: field.Tag.Get("description"),
+			Required:    required,
+		})
+	}
+	return arguments, stringFields
+}
+
+// AddSDKMcpPrompts registers prompts on a server created with
+// CreateSDKMcpServer. Registering a name again replaces the previous
+// definition. Once a server has prompts it advertises the MCP prompts
+// capability on initialize.
+func AddSDKMcpPrompts(server *McpSdkServerConfig, prompts ...*PromptDef) error {
+	instance, err := sdkMcpServerInstance(server)
+	if err != nil {
+		return err
+	}
+	for _, prompt := range prompts {
+		if prompt == nil {
+			continue
+		}
+		arguments := make([]mcp.PromptArgument, len(prompt.Arguments))
+		for i, arg := range prompt.Arguments {
+			arguments[i] = mcp.PromptArgument{Name: arg.Name, Description: arg.Description, Required: arg.Required}
+		}
+		handler := prompt.Handler
+		var wrapped mcp.PromptHandler
+		if handler != nil {
+			wrapped = func(ctx context.Context, args map[string]string) (*mcp.GetPromptResult, error) {
+				messages, err := handler(ctx, args)
+				if err != nil {
+					return nil, err
+				}
+				return convertPromptMessagesToMCP(messages)
+			}
+		}
+		err := instance.RegisterPrompt(&mcp.PromptDefinition{
+			Name:        prompt.Name,
+			Title:       prompt.Title,
+			Description: prompt.Description,
+			Arguments:   arguments,
+			Handler:     wrapped,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register prompt %s: %w", prompt.Name, err)
+		}
+	}
+	return nil
+}
+
+func convertPromptMessagesToMCP(messages []PromptMessage) (*mcp.GetPromptResult, error) {
+	result := &mcp.GetPromptResult{Messages: make([]mcp.PromptMessage, 0, len(messages))}
+	for i, msg := range messages {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			return nil, fmt.Errorf("prompt message %d: invalid role %q", i, msg.Role)
+		}
+		if msg.Content == nil {
+			return nil, fmt.Errorf("prompt message %d: content cannot be nil", i)
+		}
+		contents, err := convertContentsToMCP([]ToolContent{msg.Content})
+		if err != nil {
+			return nil, fmt.Errorf("prompt message %d: %w", i, err)
+		}
+		result.Messages = append(result.Messages, mcp.PromptMessage{Role: msg.Role, Content: contents[0]})
+	}
+	return result, nil
+}
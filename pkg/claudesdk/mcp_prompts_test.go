@@ -0,0 +1,98 @@
+package claudesdk
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestTypedPromptServedBySDKMcpServer(t *testing.T) {
+	type reviewArgs struct {
+		Path   string  `json:"path" description:"File to review"`
+		Depth  int     `json:"depth,omitempty"`
+		Strict bool    `json:"strict,omitempty"`
+		Focus  *string `json:"focus"`
+	}
+
+	var got reviewArgs
+	prompt := TypedPrompt("review", "Review a file",
+		func(ctx context.Context, args reviewArgs) ([]PromptMessage, error) {
+			got = args
+			return []PromptMessage{
+				NewUserPromptMessage(NewTextContent("Review " + args.Path)),
+				NewAssistantPromptMessage(NewResourceTextContent("file:///"+args.Path, "code", "text/x-go")),
+			}, nil
+		})
+
+	if len(prompt.Arguments) != 4 {
+		t.Fatalf("arguments = %+v", prompt.Arguments)
+	}
+	if a := prompt.Arguments[0]; a.Name != "path" || !a.Required || a.Description != "File to review" {
+		t.Errorf("path argument = %+v", a)
+	}
+	if prompt.Arguments[1].Required || prompt.Arguments[3].Required {
+		t.Errorf("omitempty/pointer arguments should be optional: %+v", prompt.Arguments)
+	}
+
+	server := CreateSDKMcpServer("prompts", "1.0.0")
+	if err := AddSDKMcpPrompts(server, prompt); err != nil {
+		t.Fatalf("AddSDKMcpPrompts: %v", err)
+	}
+
+	req, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "prompts/get",
+		"params": map[string]any{
+			"name":      "review",
+			"arguments": map[string]any{"path": "main.go", "depth": "3", "strict": "true"},
+		},
+	})
+	resp, err := server.Instance.HandleJSONRPC(nil, req)
+	if err != nil {
+		t.Fatalf("HandleJSONRPC: %v", err)
+	}
+	var out struct {
+		Result struct {
+			Messages []struct {
+				Role    string         `json:"role"`
+				Content map[string]any `json:"content"`
+			} `json:"messages"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if got.Path != "main.go" || got.Depth != 3 || !got.Strict || got.Focus != nil {
+		t.Errorf("decoded args = %+v", got)
+	}
+	msgs := out.Result.Messages
+	if len(msgs) != 2 || msgs[0].Content["text"] != "Review main.go" || msgs[1].Role != "assistant" || msgs[1].Content["type"] != "resource" {
+		t.Errorf("messages = %s", resp)
+	}
+}
+
+func TestAddSDKMcpPromptsRejectsInvalidRole(t *testing.T) {
+	server := CreateSDKMcpServer("prompts", "1.0.0")
+	err := AddSDKMcpPrompts(server, &PromptDef{
+		Name: "bad",
+		Handler: func(context.Context, map[string]string) ([]PromptMessage, error) {
+			return []PromptMessage{{Role: "system", Content: NewTextContent("x")}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddSDKMcpPrompts: %v", err)
+	}
+	req := []byte(`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"bad"}}`)
+	resp, _ := server.Instance.HandleJSONRPC(nil, req)
+	var out map[string]any
+	_ = json.Unmarshal(resp, &out)
+	if _, ok := out["error"]; !ok {
+		t.Errorf("expected error response, got %s", resp)
+	}
+
+	if err := AddSDKMcpPrompts(&McpSdkServerConfig{Name: "external"}, &PromptDef{Name: "x"}); err == nil {
+		t.Error("expected error for server without SDK instance")
+	}
+}
@@ -0,0 +1,184 @@
+package claudesdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+)
+
+// ResourceHandler returns the contents of a static MCP resource.
+//
+// Build contents with NewResourceTextContent or NewResourceBlobContent. An
+// empty URI or MIME type is filled in from the request and the ResourceDef.
+type ResourceHandler func(ctx context.Context, uri string) ([]*ResourceContent, error)
+
+// ResourceTemplateHandler returns the contents of a resource matched by a
+// ResourceTemplateDef. params holds the percent-decoded values of the
+// template variables.
+type ResourceTemplateHandler func(ctx context.Context, uri string, params map[string]string) ([]*ResourceContent, error)
+
+// ResourceDef defines a static resource exposed by an SDK MCP server.
+//
+// Example:
+//
+//	readme := &ResourceDef{
+//	    URI:      "docs://readme",
+//	    Name:     "README",
+//	    MimeType: "text/markdown",
+//	    Handler: func(ctx context.Context, uri string) ([]*ResourceContent, error) {
+//	        return []*ResourceContent{NewResourceTextContent(uri, readmeText, "")}, nil
+//	    },
+//	}
+type ResourceDef struct {
+	// URI uniquely identifies the resource.
+	URI string
+
+	// Name is a short display name. Defaults to URI.
+	Name string
+
+	// Title is an optional human-readable title.
+	Title string
+
+	// Description explains what the resource contains.
+	Description string
+
+	// MimeType is the default MIME type of the contents.
+	MimeType string
+
+	// Handler returns the resource contents.
+	Handler ResourceHandler
+}
+
+// ResourceTemplateDef defines a family of resources addressed by an RFC 6570
+// URI template such as "file:///{path}" or "repo://{owner}/{+path}".
+// Simple ({var}) and reserved ({+var}) expansions are supported.
+type ResourceTemplateDef struct {
+	// URITemplate is the template matched against requested URIs.
+	URITemplate string
+
+	// Name is a short display name. Defaults to URITemplate.
+	Name string
+
+	// Title is an optional human-readable title.
+	Title string
+
+	// Description explains what the resources contain.
+	Description string
+
+	// MimeType is the default MIME type of the contents.
+	MimeType string
+
+	// Handler returns the contents of a matched resource.
+	Handler ResourceTemplateHandler
+}
+
+// AddSDKMcpResources registers static resources on a server created with
+// CreateSDKMcpServer. Registering a URI again replaces the previous
+// definition. Once a server has resources it advertises the MCP resources
+// capability (with subscribe and listChanged) on initialize.
+func AddSDKMcpResources(server *McpSdkServerConfig, resources ...*ResourceDef) error {
+	instance, err := sdkMcpServerInstance(server)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		if resource == nil {
+			continue
+		}
+		handler := resource.Handler
+		var wrapped mcp.ResourceHandler
+		if handler != nil {
+			wrapped = func(ctx context.Context, uri string) ([]mcp.EmbeddedResource, error) {
+				contents, err := handler(ctx, uri)
+				if err != nil {
+					return nil, err
+				}
+				return convertResourceContentsToMCP(contents), nil
+			}
+		}
+		err := instance.RegisterResource(&mcp.ResourceDefinition{
+			URI:         resource.URI,
+			Name:        resource.Name,
+			Title:       resource.Title,
+			Description: resource.Description,
+			MimeType:    resource.MimeType,
+			Handler:     wrapped,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register resource %s: %w", resource.URI, err)
+		}
+	}
+	return nil
+}
+
+// AddSDKMcpResourceTemplates registers URI-template resources on a server
+// created with CreateSDKMcpServer. Templates are tried in registration
+// order after static resources.
+func AddSDKMcpResourceTemplates(server *McpSdkServerConfig, templates ...*ResourceTemplateDef) error {
+	instance, err := sdkMcpServerInstance(server)
+	if err != nil {
+		return err
+	}
+	for _, template := range templates {
+		if template == nil {
+			continue
+		}
+		handler := template.Handler
+		var wrapped mcp.ResourceTemplateHandler
+		if handler != nil {
+			wrapped = func(ctx context.Context, uri string, params map[string]string) ([]mcp.EmbeddedResource, error) {
+				contents, err := handler(ctx, uri, params)
+				if err != nil {
+					return nil, err
+				}
+				return convertResourceContentsToMCP(contents), nil
+			}
+		}
+		err := instance.RegisterResourceTemplate(&mcp.ResourceTemplateDefinition{
+			URITemplate: template.URITemplate,
+			Name:        template.Name,
+			Title:       template.Title,
+			Description: template.Description,
+			MimeType:    template.MimeType,
+			Handler:     wrapped,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register resource template %s: %w", template.URITemplate, err)
+		}
+	}
+	return nil
+}
+
+// NotifySDKMcpResourceUpdated tells the CLI that the resource at uri changed.
+// The notification is only sent if the CLI subscribed to uri via
+// resources/subscribe and the server is attached to a connected session.
+func NotifySDKMcpResourceUpdated(server *McpSdkServerConfig, uri string) error {
+	instance, err := sdkMcpServerInstance(server)
+	if err != nil {
+		return err
+	}
+	return instance.NotifyResourceUpdated(uri)
+}
+
+func sdkMcpServerInstance(server *McpSdkServerConfig) (*mcp.Server, error) {
+	if server == nil {
+		return nil, fmt.Errorf("sdk mcp server cannot be nil")
+	}
+	instance, ok := server.Instance.(*mcp.Server)
+	if !ok {
+		return nil, fmt.Errorf("sdk mcp server %q was not created with CreateSDKMcpServer", server.Name)
+	}
+	return instance, nil
+}
+
+func convertResourceContentsToMCP(contents []*ResourceContent) []mcp.EmbeddedResource {
+	result := make([]mcp.EmbeddedResource, 0, len(contents))
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		result = append(result, mcp.EmbeddedResource{URI: c.uri, Text: c.text, Blob: c.blob, MimeType: c.mimeType})
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package claudesdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+)
+
+func readSDKMcpResource(t *testing.T, server *McpSdkServerConfig, uri string) map[string]any {
+	t.Helper()
+	req, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "resources/read",
+		"params":  map[string]any{"uri": uri},
+	})
+	resp, err := server.Instance.HandleJSONRPC(nil, req)
+	if err != nil {
+		t.Fatalf("HandleJSONRPC: %v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(resp, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return out
+}
+
+func resourceContents(t *testing.T, resp map[string]any) []map[string]any {
+	t.Helper()
+	result, ok := resp["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected a result, got %v", resp)
+	}
+	var contents []map[string]any
+	for _, c := range result["contents"].([]any) {
+		contents = append(contents, c.(map[string]any))
+	}
+	return contents
+}
+
+func TestAddSDKMcpResources(t *testing.T) {
+	server := CreateSDKMcpServer("docs", "1.0.0")
+	err := AddSDKMcpResources(server,
+		&ResourceDef{
+			URI:      "docs://readme",
+			Name:     "README",
+			MimeType: "text/markdown",
+			Handler: func(ctx context.Context, uri string) ([]*ResourceContent, error) {
+				return []*ResourceContent{NewResourceTextContent("", "# hello", "")}, nil
+			},
+		},
+		nil,
+		&ResourceDef{
+			URI: "docs://logo",
+			Handler: func(ctx context.Context, uri string) ([]*ResourceContent, error) {
+				return []*ResourceContent{NewResourceBlobContent(uri, "aGk=", "image/png")}, nil
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("AddSDKMcpResources: %v", err)
+	}
+
+	contents := resourceContents(t, readSDKMcpResource(t, server, "docs://readme"))
+	if len(contents) != 1 || contents[0]["uri"] != "docs://readme" || contents[0]["text"] != "# hello" ||
+		contents[0]["mimeType"] != "text/markdown" {
+		t.Errorf("readme contents = %v", contents)
+	}
+	contents = resourceContents(t, readSDKMcpResource(t, server, "docs://logo"))
+	if len(contents) != 1 || contents[0]["blob"] != "aGk=" || contents[0]["mimeType"] != "image/png" {
+		t.Errorf("logo contents = %v", contents)
+	}
+
+	if err := AddSDKMcpResources(&McpSdkServerConfig{Name: "external"}, &ResourceDef{URI: "docs://x"}); err == nil {
+		t.Error("expected error for server without SDK instance")
+	}
+}
+
+func TestAddSDKMcpResourceTemplates(t *testing.T) {
+	server := CreateSDKMcpServer("repo", "1.0.0")
+	err := AddSDKMcpResourceTemplates(server, &ResourceTemplateDef{
+		URITemplate: "repo://{owner}/{+path}",
+		MimeType:    "text/plain",
+		Handler: func(ctx context.Context, uri string, params map[string]string) ([]*ResourceContent, error) {
+			if params["owner"] == "broken" {
+				return nil, errors.New("backend down")
+			}
+			return []*ResourceContent{NewResourceTextContent(uri, params["owner"]+":"+params["path"], "")}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddSDKMcpResourceTemplates: %v", err)
+	}
+
+	contents := resourceContents(t, readSDKMcpResource(t, server, "repo://acme%20corp/src/main.go"))
+	if len(contents) != 1 || contents[0]["text"] != "acme corp:src/main.go" || contents[0]["mimeType"] != "text/plain" {
+		t.Errorf("contents = %v", contents)
+	}
+	if resp := readSDKMcpResource(t, server, "repo://broken/x"); resp["error"] == nil {
+		t.Errorf("expected the handler error to be reported, got %v", resp)
+	}
+
+	err = AddSDKMcpResourceTemplates(server, &ResourceTemplateDef{URITemplate: "repo://{owner?}"})
+	if err == nil || !strings.Contains(err.Error(), "repo://{owner?}") {
+		t.Errorf("expected an invalid template to be rejected, got %v", err)
+	}
+}
+
+func TestNotifySDKMcpResourceUpdated(t *testing.T) {
+	server := CreateSDKMcpServer("docs", "1.0.0")
+	err := AddSDKMcpResources(server, &ResourceDef{
+		URI:     "docs://readme",
+		Handler: func(context.Context, string) ([]*ResourceContent, error) { return nil, nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent []string
+	server.Instance.(*mcp.Server).SetNotificationSender(func(notification []byte) error {
+		sent = append(sent, string(notification))
+		return nil
+	})
+
+	if err := NotifySDKMcpResourceUpdated(server, "docs://readme"); err != nil || len(sent) != 0 {
+		t.Fatalf("expected no notification without a subscription, got %v, %v", sent, err)
+	}
+	req := []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"docs://readme"}}`)
+	if _, err := server.Instance.HandleJSONRPC(nil, req); err != nil {
+		t.Fatal(err)
+	}
+	if err := NotifySDKMcpResourceUpdated(server, "docs://readme"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || !strings.Contains(sent[0], `"notifications/resources/updated"`) || !strings.Contains(sent[0], `"docs://readme"`) {
+		t.Errorf("sent = %v", sent)
+	}
+
+	if err := NotifySDKMcpResourceUpdated(nil, "docs://readme"); err == nil {
+		t.Error("expected error for a nil server")
+	}
+}
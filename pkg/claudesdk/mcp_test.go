@@ -0,0 +1,60 @@
+package claudesdk
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRegisterSDKMcpToolsOnLiveServer(t *testing.T) {
+	server := CreateSDKMcpServer("live", "1.0.0")
+	changed := make(chan string, 4)
+	client, err := ConnectMcpServer(context.Background(), server,
+		WithMcpNotificationHandler(func(method string, _ json.RawMessage) { changed <- method }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ping := Tool("ping", "Ping", nil, func(ctx context.Context, args map[string]any) ([]ToolContent, error) {
+		return []ToolContent{NewTextContent("pong")}, nil
+	})
+	if err := RegisterSDKMcpTools(server, ping); err != nil {
+		t.Fatal(err)
+	}
+	expectListChanged(t, changed)
+
+	ctx := context.Background()
+	tools, err := client.ListTools(ctx)
+	if err != nil || len(tools) != 1 || tools[0].Name != "ping" {
+		t.Fatalf("ListTools = %v, %v", tools, err)
+	}
+
+	if err := UnregisterSDKMcpTools(server, "ping"); err != nil {
+		t.Fatal(err)
+	}
+	expectListChanged(t, changed)
+	if tools, _ := client.ListTools(ctx); len(tools) != 0 {
+		t.Errorf("tools after unregister = %v", tools)
+	}
+
+	if err := UnregisterSDKMcpTools(server, "ping"); err == nil {
+		t.Error("expected error unregistering an unknown tool")
+	}
+	if err := RegisterSDKMcpTools(server, &ToolDef{Name: "broken"}); err == nil {
+		t.Error("expected error registering a tool without a handler")
+	}
+}
+
+func expectListChanged(t *testing.T, changed <-chan string) {
+	t.Helper()
+	select {
+	case method := <-changed:
+		if method != "notifications/tools/list_changed" {
+			t.Errorf("notification = %s", method)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no notifications/tools/list_changed")
+	}
+}
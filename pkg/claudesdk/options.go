@@ -1,6 +1,8 @@
 package claudesdk
 
 import (
+	"slices"
+
 	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
 )
 
@@ -529,6 +531,51 @@ func WithHook(event HookEvent, matcher HookMatcher) Option {
 	}
 }
 
+// WithRuntimeHooks routes the hooks of events through one dispatcher
+// callback registered at connect, so Client.AddHook, RemoveHook and
+// ReplaceHooks take effect immediately without the CLI re-registering
+// hooks. With no events, DefaultRuntimeHookEvents are dispatched.
+//
+// Clients dispatch DefaultRuntimeHookEvents unless configured otherwise;
+// use this option to dispatch a different set. Hooks of other events can
+// still be changed at runtime when the CLI accepts a set_hooks request. A
+// dispatched event calls back into the SDK for every occurrence, even
+// while it has no matchers.
+//
+// Example:
+//
+//	client := claudesdk.NewClient(claudesdk.WithRuntimeHooks(claudesdk.HookEventPreToolUse))
+//	// ... later, after Connect:
+//	handle, err := client.AddHook(ctx, claudesdk.HookEventPreToolUse, guard)
+//	// ...
+//	err = client.RemoveHook(ctx, handle)
+func WithRuntimeHooks(events ...HookEvent) Option {
+	return func(o *Options) {
+		dispatched := events
+		if len(dispatched) == 0 {
+			dispatched = DefaultRuntimeHookEvents
+		}
+		for _, event := range dispatched {
+			if !slices.Contains(o.RuntimeHookEvents, event) {
+				o.RuntimeHookEvents = append(o.RuntimeHookEvents, event)
+			}
+		}
+	}
+}
+
+// WithoutRuntimeHooks stops the client dispatching any event in the SDK,
+// so the CLI only calls back for events with hooks at connect. Hooks added
+// later then depend on the CLI accepting a set_hooks request.
+//
+// Example:
+//
+//	client := claudesdk.NewClient(claudesdk.WithoutRuntimeHooks())
+func WithoutRuntimeHooks() Option {
+	return func(o *Options) {
+		o.RuntimeHookEvents = []HookEvent{}
+	}
+}
+
 const customTransportMarker = "custom_transport"
 
 // WithTransport sets a custom transport for testing.
@@ -1,6 +1,9 @@
 package claudesdk
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestWithMcpConfig(t *testing.T) {
 	t.Parallel()
@@ -37,3 +40,42 @@ func TestWithFallbackModel(t *testing.T) {
 		t.Fatalf("unexpected FallbackModel: %q", got)
 	}
 }
+
+func TestWithRuntimeHooks(t *testing.T) {
+	t.Parallel()
+
+	options := NewOptions(WithRuntimeHooks())
+	if len(options.RuntimeHookEvents) != len(DefaultRuntimeHookEvents) {
+		t.Fatalf("expected the default events, got %v", options.RuntimeHookEvents)
+	}
+
+	options = NewOptions(
+		WithRuntimeHooks(HookEventPreToolUse),
+		WithRuntimeHooks(HookEventPreToolUse, HookEventSessionStart),
+	)
+	if got := options.RuntimeHookEvents; len(got) != 2 || got[0] != HookEventPreToolUse || got[1] != HookEventSessionStart {
+		t.Fatalf("unexpected RuntimeHookEvents: %v", got)
+	}
+}
+
+func TestClientDispatchesRuntimeHooksByDefault(t *testing.T) {
+	t.Parallel()
+
+	client := NewClient().(*ClientImpl)
+	if !slices.Equal(client.options.RuntimeHookEvents, DefaultRuntimeHookEvents) {
+		t.Fatalf("expected the default events, got %v", client.options.RuntimeHookEvents)
+	}
+
+	client = NewClient(WithRuntimeHooks(HookEventStop)).(*ClientImpl)
+	if got := client.options.RuntimeHookEvents; len(got) != 1 || got[0] != HookEventStop {
+		t.Fatalf("expected only the configured event, got %v", got)
+	}
+
+	client = NewClient(WithoutRuntimeHooks()).(*ClientImpl)
+	if got := client.options.RuntimeHookEvents; len(got) != 0 {
+		t.Fatalf("expected no dispatched events, got %v", got)
+	}
+	if got := NewOptions().RuntimeHookEvents; got != nil {
+		t.Fatalf("expected plain options to leave runtime hooks unset, got %v", got)
+	}
+}
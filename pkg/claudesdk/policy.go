@@ -0,0 +1,87 @@
+package claudesdk
+
+import (
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/policy"
+)
+
+// ToolPolicy is a declarative tool permission policy: ordered rules
+// matching tool name globs, Bash command prefixes and regexes, file path
+// globs, MCP server and tool patterns, time windows and call budgets, each
+// deciding "allow", "deny" or "ask". The first matching rule wins and
+// Default applies when none matches.
+type ToolPolicy = policy.Policy
+
+// ToolPolicyRule is one rule of a ToolPolicy.
+type ToolPolicyRule = policy.Rule
+
+// ToolPolicyTimeWindow restricts a rule to certain days and hours.
+type ToolPolicyTimeWindow = policy.TimeWindow
+
+// ToolPolicyDecision is the outcome of evaluating a tool call; Explain
+// renders the per-rule trace.
+type ToolPolicyDecision = policy.Decision
+
+// ToolPolicyTraceStep records why one rule did or did not match.
+type ToolPolicyTraceStep = policy.TraceStep
+
+// ToolPolicyEngine evaluates tool calls against a ToolPolicy. CanUseTool
+// and PreToolUseHook compile it into a permission callback or a hook.
+type ToolPolicyEngine = policy.Engine
+
+// ToolPolicyEngineOption configures a ToolPolicyEngine.
+type ToolPolicyEngineOption = policy.Option
+
+// PolicyUnmarshaler decodes a policy document, like json.Unmarshal or
+// yaml.Unmarshal.
+type PolicyUnmarshaler = policy.Unmarshaler
+
+// ParseToolPolicy decodes a policy document with unmarshal, or as JSON when
+// unmarshal is nil. The SDK has no YAML dependency; pass yaml.Unmarshal
+// from the YAML package of your choice to read YAML.
+//
+// Example:
+//
+//	p, err := claudesdk.ParseToolPolicy(data, yaml.Unmarshal)
+//	engine, err := claudesdk.NewToolPolicyEngine(p)
+//	client := claudesdk.NewClient(
+//	    claudesdk.WithCanUseTool(engine.CanUseTool(nil)),
+//	)
+func ParseToolPolicy(data []byte, unmarshal PolicyUnmarshaler) (*ToolPolicy, error) {
+	return policy.Parse(data, unmarshal)
+}
+
+// LoadToolPolicy reads and parses a policy file; see ParseToolPolicy.
+func LoadToolPolicy(path string, unmarshal PolicyUnmarshaler) (*ToolPolicy, error) {
+	return policy.Load(path, unmarshal)
+}
+
+// NewToolPolicyEngine validates and compiles a policy.
+func NewToolPolicyEngine(p *ToolPolicy, opts ...ToolPolicyEngineOption) (*ToolPolicyEngine, error) {
+	return policy.New(p, opts...)
+}
+
+// WithPolicyClock overrides the time source used by policy time windows.
+func WithPolicyClock(now func() time.Time) ToolPolicyEngineOption {
+	return policy.WithClock(now)
+}
+
+// WithPolicyDecisionHandler reports every policy decision, e.g. to log
+// decision.Explain().
+func WithPolicyDecisionHandler(fn func(toolName string, decision *ToolPolicyDecision)) ToolPolicyEngineOption {
+	return policy.WithDecisionHandler(fn)
+}
+
+// WithToolPolicy enforces engine as the CanUseTool callback. "ask"
+// decisions go to fallback, or are denied when fallback is nil.
+func WithToolPolicy(engine *ToolPolicyEngine, fallback CanUseToolCallback) Option {
+	return WithCanUseTool(engine.CanUseTool(fallback))
+}
+
+// WithToolPolicyHook enforces engine as a PreToolUse hook for every tool.
+// Unlike WithToolPolicy it also sees calls the CLI would allow without
+// asking, and "ask" decisions fall through to the CLI's permission flow.
+func WithToolPolicyHook(engine *ToolPolicyEngine) Option {
+	return WithHook(HookEventPreToolUse, HookMatcher{Hooks: []HookCallback{engine.PreToolUseHook()}})
+}
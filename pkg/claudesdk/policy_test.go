@@ -0,0 +1,45 @@
+package claudesdk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToolPolicyOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	doc := `{"default": "deny", "rules": [{"name": "read", "tools": ["Read"], "decision": "allow"}]}`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadToolPolicy(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var explained []string
+	engine, err := NewToolPolicyEngine(policy, WithPolicyDecisionHandler(func(toolName string, d *ToolPolicyDecision) {
+		explained = append(explained, d.Explain())
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := NewOptions(WithToolPolicy(engine, nil), WithToolPolicyHook(engine))
+	result, err := opts.CanUseTool("Write", map[string]any{"file_path": "/x"}, ToolPermissionContext{})
+	if deny, ok := result.(*PermissionResultDeny); err != nil || !ok || !strings.Contains(deny.Message, "Write") {
+		t.Errorf("Write = %#v, %v", result, err)
+	}
+
+	matcher := opts.Hooks[string(HookEventPreToolUse)][0].(HookMatcher)
+	output, err := matcher.Hooks[0](map[string]any{"hook_event_name": "PreToolUse", "tool_name": "Read"}, nil, HookContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := output["hookSpecificOutput"].(map[string]any)["permissionDecision"]; got != PermissionDecisionAllow {
+		t.Errorf("hook decision = %v", got)
+	}
+	if len(explained) != 2 || !strings.HasSuffix(explained[1], `=> allow by rule "read"`) {
+		t.Errorf("explained = %q", explained)
+	}
+}
@@ -0,0 +1,306 @@
+package claudesdk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Size limits enforced by UserMessageBuilder.Build. They mirror the
+// Anthropic Messages API limits so oversized input fails locally instead of
+// after a round trip through the CLI.
+const (
+	// MaxImageBytes is the maximum decoded size of a single image.
+	MaxImageBytes = 5 * 1024 * 1024
+	// MaxDocumentBytes is the maximum decoded size of a single document.
+	MaxDocumentBytes = 32 * 1024 * 1024
+	// MaxUserMessageBytes is the maximum encoded size of one user message.
+	MaxUserMessageBytes = 32 * 1024 * 1024
+)
+
+// supportedImageTypes are the image media types the API accepts.
+var supportedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// UserMessageBuilder builds a user StreamMessage from typed content blocks
+// (text, images, documents, tool results) so callers don't have to
+// hand-build Anthropic message JSON.
+//
+// Errors from individual blocks (unreadable files, unsupported media types)
+// are deferred and returned by Build.
+//
+// Example:
+//
+//	msg, err := claudesdk.NewUserMessage().
+//	    Text("What is in this screenshot?").
+//	    ImageFile("screenshot.png").
+//	    CacheControl().
+//	    Build()
+//	if err != nil {
+//	    return err
+//	}
+//	err = client.Connect(ctx, msg)
+type UserMessageBuilder struct {
+	blocks          []map[string]any
+	sessionID       string
+	parentToolUseID *string
+	err             error
+}
+
+// NewUserMessage starts a new user message.
+func NewUserMessage() *UserMessageBuilder {
+	return &UserMessageBuilder{}
+}
+
+// Text appends a text block.
+func (b *UserMessageBuilder) Text(text string) *UserMessageBuilder {
+	return b.add(map[string]any{"type": ContentBlockTypeText, "text": text})
+}
+
+// ImageBytes appends a base64 image block. When mediaType is empty it is
+// sniffed from the data.
+func (b *UserMessageBuilder) ImageBytes(data []byte, mediaType string) *UserMessageBuilder {
+	if mediaType == "" {
+		mediaType = sniffMediaType(data)
+	}
+	if !supportedImageTypes[mediaType] {
+		return b.fail(fmt.Errorf("unsupported image media type %q", mediaType))
+	}
+	if len(data) > MaxImageBytes {
+		return b.fail(fmt.Errorf("image is %d bytes, exceeds limit of %d", len(data), MaxImageBytes))
+	}
+	return b.add(map[string]any{
+		"type":   "image",
+		"source": base64Source(data, mediaType),
+	})
+}
+
+// ImageFile appends an image read from path, sniffing its media type.
+func (b *UserMessageBuilder) ImageFile(path string) *UserMessageBuilder {
+	data, err := readPromptFile(path, MaxImageBytes)
+	if err != nil {
+		return b.fail(err)
+	}
+	return b.ImageBytes(data, "")
+}
+
+// ImageURL appends an image referenced by an http(s) URL.
+func (b *UserMessageBuilder) ImageURL(imageURL string) *UserMessageBuilder {
+	if err := validatePromptURL(imageURL); err != nil {
+		return b.fail(err)
+	}
+	return b.add(map[string]any{
+		"type":   "image",
+		"source": map[string]any{"type": "url", "url": imageURL},
+	})
+}
+
+// DocumentBytes appends a base64 document block (e.g. a PDF). When
+// mediaType is empty it is sniffed from the data. Plain-text documents are
+// sent as a text source. title may be empty.
+func (b *UserMessageBuilder) DocumentBytes(data []byte, mediaType, title string) *UserMessageBuilder {
+	if mediaType == "" {
+		mediaType = sniffMediaType(data)
+	}
+	if len(data) > MaxDocumentBytes {
+		return b.fail(fmt.Errorf("document is %d bytes, exceeds limit of %d", len(data), MaxDocumentBytes))
+	}
+
+	var source map[string]any
+	switch {
+	case mediaType == "application/pdf":
+		source = base64Source(data, mediaType)
+	case strings.HasPrefix(mediaType, "text/plain"):
+		source = map[string]any{"type": "text", "media_type": "text/plain", "data": string(data)}
+	default:
+		return b.fail(fmt.Errorf("unsupported document media type %q", mediaType))
+	}
+	return b.add(documentBlock(source, title))
+}
+
+// DocumentFile appends a document read from path, sniffing its media type.
+func (b *UserMessageBuilder) DocumentFile(path, title string) *UserMessageBuilder {
+	data, err := readPromptFile(path, MaxDocumentBytes)
+	if err != nil {
+		return b.fail(err)
+	}
+	return b.DocumentBytes(data, "", title)
+}
+
+// DocumentURL appends a PDF document referenced by an http(s) URL.
+func (b *UserMessageBuilder) DocumentURL(documentURL, title string) *UserMessageBuilder {
+	if err := validatePromptURL(documentURL); err != nil {
+		return b.fail(err)
+	}
+	return b.add(documentBlock(map[string]any{"type": "url", "url": documentURL}, title))
+}
+
+// ToolResult appends a tool_result block answering toolUseID. content is
+// either a string or content blocks previously built with another
+// UserMessageBuilder (see Blocks).
+func (b *UserMessageBuilder) ToolResult(toolUseID string, content any, isError bool) *UserMessageBuilder {
+	if toolUseID == "" {
+		return b.fail(fmt.Errorf("tool result requires a tool_use_id"))
+	}
+	block := map[string]any{
+		"type":        ContentBlockTypeToolResult,
+		"tool_use_id": toolUseID,
+		"content":     content,
+	}
+	if isError {
+		block["is_error"] = true
+	}
+	return b.add(block)
+}
+
+// CacheControl marks the most recently added block as a prompt-cache
+// breakpoint ({"type": "ephemeral"}).
+func (b *UserMessageBuilder) CacheControl() *UserMessageBuilder {
+	if len(b.blocks) == 0 {
+		return b.fail(fmt.Errorf("cache control requires a preceding content block"))
+	}
+	b.blocks[len(b.blocks)-1]["cache_control"] = map[string]any{"type": "ephemeral"}
+	return b
+}
+
+// SessionID sets the session ID of the built message. Client.QueryStream
+// fills in "default" when it is left empty.
+func (b *UserMessageBuilder) SessionID(sessionID string) *UserMessageBuilder {
+	b.sessionID = sessionID
+	return b
+}
+
+// ParentToolUseID sets the parent tool use ID of the built message.
+func (b *UserMessageBuilder) ParentToolUseID(id string) *UserMessageBuilder {
+	b.parentToolUseID = &id
+	return b
+}
+
+// Blocks returns the content blocks added so far, or the first error.
+func (b *UserMessageBuilder) Blocks() ([]map[string]any, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	out := make([]map[string]any, len(b.blocks))
+	copy(out, b.blocks)
+	return out, nil
+}
+
+// Build validates the message and returns it as a StreamMessage for
+// Client.Connect, Client.QueryStream or QueryStream.
+func (b *UserMessageBuilder) Build() (StreamMessage, error) {
+	if b.err != nil {
+		return StreamMessage{}, b.err
+	}
+	if len(b.blocks) == 0 {
+		return StreamMessage{}, fmt.Errorf("user message has no content")
+	}
+
+	msg := StreamMessage{
+		Type: MessageTypeUser,
+		Message: map[string]interface{}{
+			"role":    "user",
+			"content": b.blocks,
+		},
+		ParentToolUseID: b.parentToolUseID,
+		SessionID:       b.sessionID,
+	}
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		return StreamMessage{}, fmt.Errorf("failed to encode user message: %w", err)
+	}
+	if len(encoded) > MaxUserMessageBytes {
+		return StreamMessage{}, fmt.Errorf(
+			"user message is %d bytes encoded, exceeds limit of %d", len(encoded), MaxUserMessageBytes,
+		)
+	}
+	return msg, nil
+}
+
+// StreamMessages returns a closed, buffered channel carrying msgs, for
+// passing pre-built messages to QueryStream or Client.QueryStream.
+func StreamMessages(msgs ...StreamMessage) <-chan StreamMessage {
+	ch := make(chan StreamMessage, len(msgs))
+	for _, msg := range msgs {
+		ch <- msg
+	}
+	close(ch)
+	return ch
+}
+
+func (b *UserMessageBuilder) add(block map[string]any) *UserMessageBuilder {
+	if b.err == nil {
+		b.blocks = append(b.blocks, block)
+	}
+	return b
+}
+
+func (b *UserMessageBuilder) fail(err error) *UserMessageBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+func base64Source(data []byte, mediaType string) map[string]any {
+	return map[string]any{
+		"type":       "base64",
+		"media_type": mediaType,
+		"data":       base64.StdEncoding.EncodeToString(data),
+	}
+}
+
+func documentBlock(source map[string]any, title string) map[string]any {
+	block := map[string]any{"type": "document", "source": source}
+	if title != "" {
+		block["title"] = title
+	}
+	return block
+}
+
+// sniffMediaType detects the media type of data, dropping any parameters
+// (e.g. "; charset=utf-8") except for text/plain.
+func sniffMediaType(data []byte) string {
+	mediaType := http.DetectContentType(data)
+	if strings.HasPrefix(mediaType, "text/plain") {
+		return "text/plain"
+	}
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+	return mediaType
+}
+
+func readPromptFile(path string, limit int64) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if info.Size() > limit {
+		return nil, fmt.Errorf("%s is %d bytes, exceeds limit of %d", path, info.Size(), limit)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+func validatePromptURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL %q must use http or https", raw)
+	}
+	return nil
+}
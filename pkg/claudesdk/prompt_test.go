@@ -0,0 +1,106 @@
+package claudesdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestUserMessageBuilderBuildsContentBlocks(t *testing.T) {
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "shot.png")
+	if err := os.WriteFile(imagePath, pngHeader, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := NewUserMessage().
+		Text("describe").
+		ImageFile(imagePath).
+		CacheControl().
+		DocumentBytes([]byte("%PDF-1.7\n"), "", "spec").
+		ImageURL("https://example.com/cat.jpg").
+		ToolResult("toolu_1", "ok", true).
+		SessionID("s1").
+		Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if msg.Type != MessageTypeUser || msg.SessionID != "s1" {
+		t.Errorf("msg = %+v", msg)
+	}
+
+	blocks := msg.Message.(map[string]interface{})["content"].([]map[string]any)
+	if len(blocks) != 5 {
+		t.Fatalf("got %d blocks, want 5", len(blocks))
+	}
+	image := blocks[1]["source"].(map[string]any)
+	if image["media_type"] != "image/png" || image["data"] != base64.StdEncoding.EncodeToString(pngHeader) {
+		t.Errorf("image source = %v", image)
+	}
+	if blocks[1]["cache_control"].(map[string]any)["type"] != "ephemeral" {
+		t.Errorf("cache_control not set on image block: %v", blocks[1])
+	}
+	doc := blocks[2]
+	if doc["title"] != "spec" || doc["source"].(map[string]any)["media_type"] != "application/pdf" {
+		t.Errorf("document block = %v", doc)
+	}
+	if blocks[3]["source"].(map[string]any)["type"] != "url" {
+		t.Errorf("url image block = %v", blocks[3])
+	}
+	if blocks[4]["tool_use_id"] != "toolu_1" || blocks[4]["is_error"] != true {
+		t.Errorf("tool result block = %v", blocks[4])
+	}
+}
+
+func TestUserMessageBuilderValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *UserMessageBuilder
+		want    string
+	}{
+		{"empty", NewUserMessage(), "no content"},
+		{"unsupported image", NewUserMessage().ImageBytes([]byte("plain text"), ""), "unsupported image media type"},
+		{"oversized image", NewUserMessage().ImageBytes(bytes.Repeat([]byte{0}, MaxImageBytes+1), "image/png"), "exceeds limit"},
+		{"bad url", NewUserMessage().ImageURL("file:///etc/passwd"), "http or https"},
+		{"missing file", NewUserMessage().ImageFile("/does/not/exist.png"), "failed to read"},
+		{"cache control first", NewUserMessage().CacheControl().Text("x"), "preceding content block"},
+		{"tool result without id", NewUserMessage().ToolResult("", "x", false), "tool_use_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Build err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserMessageBuilderWorksWithClientQueryStream(t *testing.T) {
+	transport := newMockTransport()
+	client := NewClientWithTransport(transport)
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Disconnect()
+
+	msg, err := NewUserMessage().Text("hi").ImageBytes(pngHeader, "").Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if err := client.QueryStream(ctx, StreamMessages(msg)); err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+
+	sent := transport.snapshotSentMessages()
+	if len(sent) != 1 || sent[0].SessionID != defaultSessionID {
+		t.Fatalf("sent = %+v", sent)
+	}
+}
@@ -0,0 +1,298 @@
+package claudesdk
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/parsing"
+)
+
+// ToolInvocationStatus is the lifecycle state of a tracked tool call.
+type ToolInvocationStatus string
+
+const (
+	ToolInvocationRunning   ToolInvocationStatus = "running"
+	ToolInvocationCompleted ToolInvocationStatus = "completed"
+	ToolInvocationFailed    ToolInvocationStatus = "failed"
+)
+
+// ToolInvocation is one tool call reconstructed from the message stream.
+type ToolInvocation struct {
+	ID    string
+	Name  string
+	Input map[string]any
+
+	Status ToolInvocationStatus
+	Result *ToolResultBlock
+
+	// IsError mirrors Result.IsError.
+	IsError bool
+
+	// ParentToolUseID is the tool_use_id of the Task/Agent call whose
+	// subagent made this call, or nil for top-level calls.
+	ParentToolUseID *string
+
+	// TaskID is the background task the owning subagent runs as, when the
+	// CLI reported one via a task_started message.
+	TaskID string
+
+	// StartedAt and EndedAt are observation times. They are zero when the
+	// invocation was rebuilt from session history.
+	StartedAt time.Time
+	EndedAt   time.Time
+
+	// Children holds calls made by this call's subagent. Only populated on
+	// values returned by ToolTracker.Tree.
+	Children []*ToolInvocation
+}
+
+// Duration is EndedAt - StartedAt, or zero when either is unknown.
+func (i *ToolInvocation) Duration() time.Duration {
+	if i.StartedAt.IsZero() || i.EndedAt.IsZero() {
+		return 0
+	}
+	return i.EndedAt.Sub(i.StartedAt)
+}
+
+// ToolEventType distinguishes ToolEvent kinds.
+type ToolEventType string
+
+const (
+	ToolEventStarted  ToolEventType = "started"
+	ToolEventFinished ToolEventType = "finished"
+)
+
+// ToolEvent is delivered to ToolTracker subscribers. Invocation is a
+// snapshot taken when the event fired.
+type ToolEvent struct {
+	Type       ToolEventType
+	Invocation ToolInvocation
+}
+
+// ToolTracker pairs ToolUseBlocks with their ToolResultBlocks across
+// messages, including calls made by subagents, and keeps a timeline of
+// tool invocations.
+//
+// Feed it every message from ReceiveMessages/ReceiveResponse/Query with
+// Observe, or rebuild a finished session with ObserveSessionMessages.
+// It is safe for concurrent use.
+//
+// Example:
+//
+//	tracker := claudesdk.NewToolTracker()
+//	tracker.Subscribe(func(ev claudesdk.ToolEvent) {
+//	    fmt.Printf("%s %s %s\n", ev.Type, ev.Invocation.Name, ev.Invocation.Duration())
+//	})
+//	for msg := range client.ReceiveMessages(ctx) {
+//	    tracker.Observe(msg)
+//	}
+//	roots := tracker.Tree()
+type ToolTracker struct {
+	mu          sync.Mutex
+	invocations map[string]*ToolInvocation
+	order       []string
+	tasks       map[string]string // Task tool_use_id -> task_id
+	subscribers map[int]func(ToolEvent)
+	nextSubID   int
+	now         func() time.Time
+}
+
+// NewToolTracker returns an empty tracker.
+func NewToolTracker() *ToolTracker {
+	return &ToolTracker{
+		invocations: make(map[string]*ToolInvocation),
+		tasks:       make(map[string]string),
+		subscribers: make(map[int]func(ToolEvent)),
+		now:         time.Now,
+	}
+}
+
+// Subscribe registers fn to receive tool events. fn is called synchronously
+// from Observe, so it must not call back into the tracker's Observe. The
+// returned function unsubscribes.
+func (t *ToolTracker) Subscribe(fn func(ToolEvent)) (unsubscribe func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.nextSubID
+	t.nextSubID++
+	t.subscribers[id] = fn
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.subscribers, id)
+	}
+}
+
+// Observe updates the timeline from one message. Messages that carry no
+// tool information are ignored.
+func (t *ToolTracker) Observe(msg Message) {
+	t.observe(msg, t.now())
+}
+
+// ObserveSessionMessages replays a stored transcript, as returned by
+// GetSessionMessages, into the tracker. Timestamps are left zero.
+func (t *ToolTracker) ObserveSessionMessages(messages []SessionMessage) error {
+	parser := parsing.New()
+	for _, sm := range messages {
+		data := map[string]any{
+			"type":       sm.Type,
+			"message":    sm.Message,
+			"uuid":       sm.UUID,
+			"session_id": sm.SessionID,
+		}
+		if sm.ParentToolUseID != nil {
+			data["parent_tool_use_id"] = *sm.ParentToolUseID
+		}
+		msg, err := parser.ParseMessage(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse session message %s: %w", sm.UUID, err)
+		}
+		if msg != nil {
+			t.observe(msg, time.Time{})
+		}
+	}
+	return nil
+}
+
+func (t *ToolTracker) observe(msg Message, at time.Time) {
+	var events []ToolEvent
+
+	t.mu.Lock()
+	switch m := msg.(type) {
+	case *AssistantMessage:
+		for _, block := range m.Content {
+			use, ok := block.(*ToolUseBlock)
+			if !ok {
+				continue
+			}
+			if _, seen := t.invocations[use.ID]; seen {
+				continue
+			}
+			inv := &ToolInvocation{
+				ID:              use.ID,
+				Name:            use.Name,
+				Input:           use.Input,
+				Status:          ToolInvocationRunning,
+				ParentToolUseID: m.ParentToolUseID,
+				StartedAt:       at,
+			}
+			if m.ParentToolUseID != nil {
+				inv.TaskID = t.tasks[*m.ParentToolUseID]
+			}
+			t.invocations[use.ID] = inv
+			t.order = append(t.order, use.ID)
+			events = append(events, ToolEvent{Type: ToolEventStarted, Invocation: *inv})
+		}
+	case *UserMessage:
+		blocks, _ := m.Content.([]ContentBlock)
+		for _, block := range blocks {
+			result, ok := block.(*ToolResultBlock)
+			if !ok {
+				continue
+			}
+			inv, ok := t.invocations[result.ToolUseID]
+			if !ok || inv.Result != nil {
+				continue
+			}
+			inv.Result = result
+			inv.IsError = result.IsError != nil && *result.IsError
+			inv.EndedAt = at
+			inv.Status = ToolInvocationCompleted
+			if inv.IsError {
+				inv.Status = ToolInvocationFailed
+			}
+			events = append(events, ToolEvent{Type: ToolEventFinished, Invocation: *inv})
+		}
+	case *TaskStartedMessage:
+		if m.ToolUseID != nil {
+			t.tasks[*m.ToolUseID] = m.TaskID
+			for _, inv := range t.invocations {
+				if inv.ParentToolUseID != nil && *inv.ParentToolUseID == *m.ToolUseID && inv.TaskID == "" {
+					inv.TaskID = m.TaskID
+				}
+			}
+		}
+	}
+	subscribers := make([]func(ToolEvent), 0, len(t.subscribers))
+	if len(events) > 0 {
+		ids := make([]int, 0, len(t.subscribers))
+		for id := range t.subscribers {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			subscribers = append(subscribers, t.subscribers[id])
+		}
+	}
+	t.mu.Unlock()
+
+	for _, ev := range events {
+		for _, fn := range subscribers {
+			fn(ev)
+		}
+	}
+}
+
+// Timeline returns snapshots of every invocation in the order they started.
+func (t *ToolTracker) Timeline() []ToolInvocation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]ToolInvocation, 0, len(t.order))
+	for _, id := range t.order {
+		out = append(out, *t.invocations[id])
+	}
+	return out
+}
+
+// Pending returns snapshots of invocations that have not produced a result.
+func (t *ToolTracker) Pending() []ToolInvocation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []ToolInvocation
+	for _, id := range t.order {
+		if inv := t.invocations[id]; inv.Status == ToolInvocationRunning {
+			out = append(out, *inv)
+		}
+	}
+	return out
+}
+
+// Get returns a snapshot of the invocation with the given tool_use_id.
+func (t *ToolTracker) Get(toolUseID string) (ToolInvocation, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inv, ok := t.invocations[toolUseID]
+	if !ok {
+		return ToolInvocation{}, false
+	}
+	return *inv, true
+}
+
+// Tree returns the invocations nested under the Task/Agent call whose
+// subagent made them. Calls whose parent was never observed are roots.
+// The returned values are copies.
+func (t *ToolTracker) Tree() []*ToolInvocation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	nodes := make(map[string]*ToolInvocation, len(t.order))
+	for _, id := range t.order {
+		inv := *t.invocations[id]
+		inv.Children = nil
+		nodes[id] = &inv
+	}
+	var roots []*ToolInvocation
+	for _, id := range t.order {
+		node := nodes[id]
+		if node.ParentToolUseID != nil {
+			if parent, ok := nodes[*node.ParentToolUseID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
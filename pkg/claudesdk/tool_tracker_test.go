@@ -0,0 +1,107 @@
+package claudesdk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToolTrackerPairsCallsAcrossSubagents(t *testing.T) {
+	tracker := NewToolTracker()
+	clock := time.Unix(1000, 0)
+	tracker.now = func() time.Time { return clock }
+
+	var events []ToolEvent
+	unsubscribe := tracker.Subscribe(func(ev ToolEvent) { events = append(events, ev) })
+
+	taskID := "toolu_task"
+	isErr := true
+	tracker.Observe(&AssistantMessage{Content: []ContentBlock{
+		&TextBlock{Text: "delegating"},
+		&ToolUseBlock{ID: taskID, Name: "Task", Input: map[string]any{"prompt": "search"}},
+	}})
+	tracker.Observe(&TaskStartedMessage{TaskID: "task-1", ToolUseID: &taskID})
+	clock = clock.Add(time.Second)
+	tracker.Observe(&AssistantMessage{
+		ParentToolUseID: &taskID,
+		Content:         []ContentBlock{&ToolUseBlock{ID: "toolu_grep", Name: "Grep"}},
+	})
+	clock = clock.Add(2 * time.Second)
+	tracker.Observe(&UserMessage{
+		ParentToolUseID: &taskID,
+		Content:         []ContentBlock{&ToolResultBlock{ToolUseID: "toolu_grep", Content: "boom", IsError: &isErr}},
+	})
+
+	if pending := tracker.Pending(); len(pending) != 1 || pending[0].ID != taskID {
+		t.Errorf("Pending = %+v, want only the Task call", pending)
+	}
+
+	clock = clock.Add(time.Second)
+	tracker.Observe(&UserMessage{Content: []ContentBlock{&ToolResultBlock{ToolUseID: taskID, Content: "found"}}})
+
+	timeline := tracker.Timeline()
+	if len(timeline) != 2 || timeline[0].ID != taskID || timeline[1].ID != "toolu_grep" {
+		t.Fatalf("Timeline = %+v", timeline)
+	}
+	grep := timeline[1]
+	if grep.Status != ToolInvocationFailed || !grep.IsError || grep.TaskID != "task-1" {
+		t.Errorf("grep invocation = %+v", grep)
+	}
+	if grep.Duration() != 2*time.Second {
+		t.Errorf("grep duration = %v, want 2s", grep.Duration())
+	}
+	if task, _ := tracker.Get(taskID); task.Status != ToolInvocationCompleted || task.Duration() != 4*time.Second {
+		t.Errorf("task invocation = %+v", task)
+	}
+
+	roots := tracker.Tree()
+	if len(roots) != 1 || len(roots[0].Children) != 1 || roots[0].Children[0].ID != "toolu_grep" {
+		t.Errorf("Tree = %+v", roots)
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("got %d events, want 4", len(events))
+	}
+	if events[0].Type != ToolEventStarted || events[2].Type != ToolEventFinished || events[2].Invocation.ID != "toolu_grep" {
+		t.Errorf("events = %+v", events)
+	}
+
+	unsubscribe()
+	tracker.Observe(&AssistantMessage{Content: []ContentBlock{&ToolUseBlock{ID: "toolu_late", Name: "Read"}}})
+	if len(events) != 4 {
+		t.Errorf("unsubscribed callback still received events")
+	}
+}
+
+func TestToolTrackerObserveSessionMessages(t *testing.T) {
+	tracker := NewToolTracker()
+	err := tracker.ObserveSessionMessages([]SessionMessage{
+		{Type: "user", UUID: "u1", Message: map[string]any{"role": "user", "content": "read it"}},
+		{Type: "assistant", UUID: "a1", Message: map[string]any{
+			"role":  "assistant",
+			"model": "claude",
+			"content": []any{
+				map[string]any{"type": "tool_use", "id": "toolu_1", "name": "Read", "input": map[string]any{"file_path": "a.go"}},
+			},
+		}},
+		{Type: "user", UUID: "u2", Message: map[string]any{
+			"role": "user",
+			"content": []any{
+				map[string]any{"type": "tool_result", "tool_use_id": "toolu_1", "content": "package a"},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("ObserveSessionMessages: %v", err)
+	}
+
+	inv, ok := tracker.Get("toolu_1")
+	if !ok {
+		t.Fatal("toolu_1 not tracked")
+	}
+	if inv.Name != "Read" || inv.Status != ToolInvocationCompleted || inv.Result == nil {
+		t.Errorf("invocation = %+v", inv)
+	}
+	if !inv.StartedAt.IsZero() || inv.Duration() != 0 {
+		t.Errorf("history invocation should have no timestamps: %+v", inv)
+	}
+}
@@ -0,0 +1,183 @@
+package claudesdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ToolCall pairs a ToolUseBlock with the ToolResultBlock that answered it.
+type ToolCall struct {
+	Use *ToolUseBlock
+
+	// Result is nil when the turn ended before the tool returned (for
+	// example because the tool was denied or the turn was interrupted).
+	Result *ToolResultBlock
+
+	// ParentToolUseID is set when the call was made inside a subagent.
+	ParentToolUseID *string
+}
+
+// IsError reports whether the tool result was flagged as an error.
+func (c ToolCall) IsError() bool {
+	return c.Result != nil && c.Result.IsError != nil && *c.Result.IsError
+}
+
+// Turn is everything received in response to one Ask.
+type Turn struct {
+	// Messages holds every message received for the turn, in order.
+	Messages []Message
+
+	// AssistantMessages holds the assistant messages, in order.
+	AssistantMessages []*AssistantMessage
+
+	// Text concatenates the text blocks of top-level assistant messages
+	// (subagent output is excluded), separated by newlines.
+	Text string
+
+	// ToolCalls lists tool uses in the order they were requested.
+	ToolCalls []ToolCall
+
+	// Thinking holds the thinking blocks of all assistant messages.
+	Thinking []*ThinkingBlock
+
+	// PermissionDenials mirrors Result.PermissionDenials.
+	PermissionDenials []any
+
+	// Result is the ResultMessage that ended the turn.
+	Result *ResultMessage
+
+	// Errors collects assistant message errors and result errors.
+	Errors []string
+}
+
+// AskOption configures a single Ask call.
+type AskOption func(*askConfig)
+
+type askConfig struct {
+	sessionID string
+	onMessage func(Message)
+}
+
+// WithAskSessionID sends the prompt on the given session instead of "default".
+func WithAskSessionID(sessionID string) AskOption {
+	return func(c *askConfig) {
+		c.sessionID = sessionID
+	}
+}
+
+// WithAskMessageCallback calls fn for every message as it arrives, before
+// Ask returns. Use it to drive streaming UIs while still getting a Turn.
+func WithAskMessageCallback(fn func(Message)) AskOption {
+	return func(c *askConfig) {
+		c.onMessage = fn
+	}
+}
+
+// Ask sends prompt on client and collects the response up to and including
+// the ResultMessage into a Turn. It is a function rather than a Client
+// method so existing Client implementations keep satisfying the interface.
+//
+// The returned Turn is non-nil even when err is non-nil, so callers can
+// inspect whatever arrived before the failure.
+//
+// Example:
+//
+//	turn, err := claudesdk.Ask(ctx, client, "List the Go files in this repo")
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(turn.Text)
+//	for _, call := range turn.ToolCalls {
+//	    fmt.Println(call.Use.Name, call.IsError())
+//	}
+func Ask(ctx context.Context, client Client, prompt string, opts ...AskOption) (*Turn, error) {
+	var cfg askConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	turn := &Turn{}
+	if client == nil {
+		return turn, fmt.Errorf("client is required")
+	}
+	if err := client.QueryWithSession(ctx, prompt, cfg.sessionID); err != nil {
+		return turn, err
+	}
+
+	builder := newTurnBuilder(turn)
+	iter := client.ReceiveResponse(ctx)
+	defer iter.Close()
+	for {
+		msg, err := iter.Next(ctx)
+		if err != nil {
+			builder.finish()
+			if errors.Is(err, ErrNoMoreMessages) {
+				if turn.Result == nil {
+					return turn, fmt.Errorf("response ended without a result message")
+				}
+				return turn, nil
+			}
+			return turn, err
+		}
+		builder.add(msg)
+		if cfg.onMessage != nil {
+			cfg.onMessage(msg)
+		}
+	}
+}
+
+// turnBuilder accumulates messages into a Turn.
+type turnBuilder struct {
+	turn    *Turn
+	text    []string
+	pending map[string]int // tool_use_id -> index in turn.ToolCalls
+}
+
+func newTurnBuilder(turn *Turn) *turnBuilder {
+	return &turnBuilder{turn: turn, pending: make(map[string]int)}
+}
+
+func (b *turnBuilder) add(msg Message) {
+	b.turn.Messages = append(b.turn.Messages, msg)
+
+	switch m := msg.(type) {
+	case *AssistantMessage:
+		b.turn.AssistantMessages = append(b.turn.AssistantMessages, m)
+		if m.Error != nil {
+			b.turn.Errors = append(b.turn.Errors, string(*m.Error))
+		}
+		for _, block := range m.Content {
+			switch bl := block.(type) {
+			case *TextBlock:
+				if m.ParentToolUseID == nil {
+					b.text = append(b.text, bl.Text)
+				}
+			case *ThinkingBlock:
+				b.turn.Thinking = append(b.turn.Thinking, bl)
+			case *ToolUseBlock:
+				b.pending[bl.ID] = len(b.turn.ToolCalls)
+				b.turn.ToolCalls = append(b.turn.ToolCalls, ToolCall{Use: bl, ParentToolUseID: m.ParentToolUseID})
+			}
+		}
+	case *UserMessage:
+		blocks, _ := m.Content.([]ContentBlock)
+		for _, block := range blocks {
+			if result, ok := block.(*ToolResultBlock); ok {
+				if i, ok := b.pending[result.ToolUseID]; ok {
+					b.turn.ToolCalls[i].Result = result
+					delete(b.pending, result.ToolUseID)
+				}
+			}
+		}
+	case *ResultMessage:
+		b.turn.Result = m
+		b.turn.PermissionDenials = m.PermissionDenials
+		b.turn.Errors = append(b.turn.Errors, m.Errors...)
+	}
+}
+
+func (b *turnBuilder) finish() {
+	b.turn.Text = strings.Join(b.text, "\n")
+}
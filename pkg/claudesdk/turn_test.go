@@ -0,0 +1,118 @@
+package claudesdk
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClientAskCollectsTurn(t *testing.T) {
+	transport := newMockTransport()
+	client := NewClientWithTransport(transport)
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Disconnect()
+
+	isErr := true
+	subagent := "toolu_task"
+	result := "final"
+	denyErr := AssistantMessageErrorRateLimit
+	transport.msgChan <- &AssistantMessage{Content: []ContentBlock{
+		&ThinkingBlock{Thinking: "hmm"},
+		&TextBlock{Text: "Let me look."},
+		&ToolUseBlock{ID: "toolu_1", Name: "Read", Input: map[string]any{"file_path": "a.go"}},
+		&ToolUseBlock{ID: "toolu_2", Name: "Bash", Input: map[string]any{"command": "rm -rf /"}},
+	}}
+	transport.msgChan <- &AssistantMessage{
+		ParentToolUseID: &subagent,
+		Content:         []ContentBlock{&TextBlock{Text: "subagent chatter"}},
+	}
+	transport.msgChan <- &UserMessage{Content: []ContentBlock{
+		&ToolResultBlock{ToolUseID: "toolu_1", Content: "package a"},
+		&ToolResultBlock{ToolUseID: "toolu_2", Content: "denied", IsError: &isErr},
+	}}
+	transport.msgChan <- &AssistantMessage{
+		Content: []ContentBlock{&TextBlock{Text: "Done."}},
+		Error:   &denyErr,
+	}
+	transport.msgChan <- &ResultMessage{
+		Subtype:           "success",
+		Result:            &result,
+		PermissionDenials: []any{map[string]any{"tool_name": "Bash"}},
+		Errors:            []string{"warning"},
+	}
+
+	var streamed int
+	turn, err := Ask(ctx, client, "look around", WithAskMessageCallback(func(Message) { streamed++ }))
+	if err != nil {
+		t.Fatalf("Ask: %v", err)
+	}
+
+	if streamed != 5 || len(turn.Messages) != 5 {
+		t.Errorf("streamed %d, collected %d messages, want 5", streamed, len(turn.Messages))
+	}
+	if len(turn.AssistantMessages) != 3 {
+		t.Errorf("assistant messages = %d, want 3", len(turn.AssistantMessages))
+	}
+	if turn.Text != "Let me look.\nDone." {
+		t.Errorf("Text = %q", turn.Text)
+	}
+	if len(turn.Thinking) != 1 || turn.Thinking[0].Thinking != "hmm" {
+		t.Errorf("Thinking = %v", turn.Thinking)
+	}
+	if len(turn.ToolCalls) != 2 {
+		t.Fatalf("ToolCalls = %d, want 2", len(turn.ToolCalls))
+	}
+	if turn.ToolCalls[0].Result == nil || turn.ToolCalls[0].IsError() {
+		t.Errorf("first tool call = %+v", turn.ToolCalls[0])
+	}
+	if !turn.ToolCalls[1].IsError() {
+		t.Errorf("second tool call should be an error: %+v", turn.ToolCalls[1])
+	}
+	if turn.Result == nil || len(turn.PermissionDenials) != 1 {
+		t.Errorf("Result = %+v, denials = %v", turn.Result, turn.PermissionDenials)
+	}
+	if len(turn.Errors) != 2 || turn.Errors[0] != "rate_limit" || turn.Errors[1] != "warning" {
+		t.Errorf("Errors = %v", turn.Errors)
+	}
+
+	sent := transport.snapshotSentMessages()
+	if len(sent) != 1 || sent[0].SessionID != defaultSessionID {
+		t.Errorf("sent = %+v", sent)
+	}
+}
+
+func TestClientAskWithoutResultReturnsError(t *testing.T) {
+	transport := newMockTransport()
+	client := NewClientWithTransport(transport)
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Disconnect()
+
+	transport.msgChan <- &AssistantMessage{Content: []ContentBlock{&TextBlock{Text: "partial"}}}
+	close(transport.msgChan)
+
+	turn, err := Ask(ctx, client, "hi", WithAskSessionID("s2"))
+	if err == nil {
+		t.Fatal("expected error when stream ends without result")
+	}
+	if turn.Text != "partial" {
+		t.Errorf("Text = %q, want partial turn", turn.Text)
+	}
+	if sent := transport.snapshotSentMessages(); sent[0].SessionID != "s2" {
+		t.Errorf("session = %q, want s2", sent[0].SessionID)
+	}
+}
+
+func TestClientAskNotConnected(t *testing.T) {
+	client := NewClientWithTransport(newMockTransport())
+	if _, err := Ask(context.Background(), client, "hi"); err == nil {
+		t.Fatal("expected error when not connected")
+	}
+	if turn, err := Ask(context.Background(), nil, "hi"); err == nil || turn == nil {
+		t.Fatalf("expected error for a nil client, got %v, %v", turn, err)
+	}
+}
@@ -0,0 +1,275 @@
+package claudesdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// NoHookSpecificOutput is the hook-specific output type of events that
+// have none (Stop, SubagentStop, PreCompact, SessionEnd, ...).
+type NoHookSpecificOutput struct{}
+
+// HookResult is the typed output of a typed hook callback. S is the event's
+// hook-specific output type; its hookEventName is filled in automatically.
+// A nil *HookResult lets the action proceed unchanged.
+type HookResult[S any] struct {
+	// Continue set to false stops Claude after the hook, with StopReason
+	// shown to the user.
+	Continue       *bool  `json:"continue,omitempty"`
+	SuppressOutput bool   `json:"suppressOutput,omitempty"`
+	StopReason     string `json:"stopReason,omitempty"`
+
+	// Decision "block" blocks the action, with Reason shown to Claude.
+	Decision      string `json:"decision,omitempty"`
+	SystemMessage string `json:"systemMessage,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+
+	HookSpecificOutput *S `json:"hookSpecificOutput,omitempty"`
+}
+
+// Typed hook results per event.
+type (
+	PreToolUseDecision         = HookResult[PreToolUseHookSpecificOutput]
+	PostToolUseDecision        = HookResult[PostToolUseHookSpecificOutput]
+	PostToolUseFailureDecision = HookResult[PostToolUseFailureHookSpecificOutput]
+	UserPromptSubmitDecision   = HookResult[UserPromptSubmitHookSpecificOutput]
+	NotificationDecision       = HookResult[NotificationHookSpecificOutput]
+	SubagentStartDecision      = HookResult[SubagentStartHookSpecificOutput]
+	PermissionRequestDecision  = HookResult[PermissionRequestHookSpecificOutput]
+	StopDecision               = HookResult[NoHookSpecificOutput]
+	SubagentStopDecision       = HookResult[NoHookSpecificOutput]
+	PreCompactDecision         = HookResult[NoHookSpecificOutput]
+	SessionStartDecision       = HookResult[SessionStartHookSpecificOutput]
+	SessionEndDecision         = HookResult[NoHookSpecificOutput]
+	PostCompactDecision        = HookResult[NoHookSpecificOutput]
+	SetupDecision              = HookResult[SetupHookSpecificOutput]
+	TeammateIdleDecision       = HookResult[NoHookSpecificOutput]
+	TaskCompletedDecision      = HookResult[NoHookSpecificOutput]
+	ElicitationDecision        = HookResult[ElicitationHookSpecificOutput]
+	ElicitationResultDecision  = HookResult[ElicitationResultHookSpecificOutput]
+)
+
+// NewPreToolUseDecision creates a PreToolUse result with a permission
+// decision (PermissionDecisionAllow, Deny, Ask or Defer). The typed
+// counterpart of NewPreToolUseOutput.
+func NewPreToolUseDecision(decision, reason string, updatedInput map[string]any) *PreToolUseDecision {
+	return &PreToolUseDecision{
+		Reason: reason,
+		HookSpecificOutput: &PreToolUseHookSpecificOutput{
+			PermissionDecision:       decision,
+			PermissionDecisionReason: reason,
+			UpdatedInput:             updatedInput,
+		},
+	}
+}
+
+// TypedHookFunc handles one hook event with a decoded input.
+type TypedHookFunc[In, S any] func(ctx context.Context, input *In) (*HookResult[S], error)
+
+// hookEventTypes maps each event to its input and hook-specific output
+// types, used to reject mismatched typed hooks at registration.
+var hookEventTypes = map[HookEvent][2]reflect.Type{
+	HookEventPreToolUse:         {reflect.TypeOf(PreToolUseHookInput{}), reflect.TypeOf(PreToolUseHookSpecificOutput{})},
+	HookEventPostToolUse:        {reflect.TypeOf(PostToolUseHookInput{}), reflect.TypeOf(PostToolUseHookSpecificOutput{})},
+	HookEventPostToolUseFailure: {reflect.TypeOf(PostToolUseFailureHookInput{}), reflect.TypeOf(PostToolUseFailureHookSpecificOutput{})},
+	HookEventUserPromptSubmit:   {reflect.TypeOf(UserPromptSubmitHookInput{}), reflect.TypeOf(UserPromptSubmitHookSpecificOutput{})},
+	HookEventStop:               {reflect.TypeOf(StopHookInput{}), reflect.TypeOf(NoHookSpecificOutput{})},
+	HookEventSubagentStop:       {reflect.TypeOf(SubagentStopHookInput{}), reflect.TypeOf(NoHookSpecificOutput{})},
+	HookEventPreCompact:         {reflect.TypeOf(PreCompactHookInput{}), reflect.TypeOf(NoHookSpecificOutput{})},
+	HookEventNotification:       {reflect.TypeOf(NotificationHookInput{}), reflect.TypeOf(NotificationHookSpecificOutput{})},
+	HookEventSubagentStart:      {reflect.TypeOf(SubagentStartHookInput{}), reflect.TypeOf(SubagentStartHookSpecificOutput{})},
+	HookEventPermissionRequest:  {reflect.TypeOf(PermissionRequestHookInput{}), reflect.TypeOf(PermissionRequestHookSpecificOutput{})},
+	HookEventSessionStart:       {reflect.TypeOf(SessionStartHookInput{}), reflect.TypeOf(SessionStartHookSpecificOutput{})},
+	HookEventSessionEnd:         {reflect.TypeOf(SessionEndHookInput{}), reflect.TypeOf(NoHookSpecificOutput{})},
+	HookEventPostCompact:        {reflect.TypeOf(PostCompactHookInput{}), reflect.TypeOf(NoHookSpecificOutput{})},
+	HookEventSetup:              {reflect.TypeOf(SetupHookInput{}), reflect.TypeOf(SetupHookSpecificOutput{})},
+	HookEventTeammateIdle:       {reflect.TypeOf(TeammateIdleHookInput{}), reflect.TypeOf(NoHookSpecificOutput{})},
+	HookEventTaskCompleted:      {reflect.TypeOf(TaskCompletedHookInput{}), reflect.TypeOf(NoHookSpecificOutput{})},
+	HookEventElicitation:        {reflect.TypeOf(ElicitationHookInput{}), reflect.TypeOf(ElicitationHookSpecificOutput{})},
+	HookEventElicitationResult:  {reflect.TypeOf(ElicitationResultHookInput{}), reflect.TypeOf(ElicitationResultHookSpecificOutput{})},
+}
+
+// NewTypedHookMatcher builds a HookMatcher for event whose callback decodes
+// the CLI's input into In and encodes the returned HookResult into the wire
+// shape. It returns an error if In or S is not the input or hook-specific
+// output type of event, or if fn is nil. An empty matcher matches all
+// tools; set Timeout on the result if needed.
+//
+// The On* options (OnPreToolUse, OnStop, ...) cover the common events with
+// fixed types and cannot fail; use NewTypedHookMatcher for the others
+// (Setup, TeammateIdle, Elicitation, ...) or when the event is chosen at
+// runtime.
+func NewTypedHookMatcher[In, S any](event HookEvent, matcher string, fn TypedHookFunc[In, S]) (HookMatcher, error) {
+	if fn == nil {
+		return HookMatcher{}, fmt.Errorf("hook callback for %s cannot be nil", event)
+	}
+	types, ok := hookEventTypes[event]
+	if !ok {
+		return HookMatcher{}, fmt.Errorf("typed hooks are not supported for event %q", event)
+	}
+	if in := reflect.TypeOf((*In)(nil)).Elem(); in != types[0] {
+		return HookMatcher{}, fmt.Errorf("%s hook input must be %s, got %s", event, types[0].Name(), in)
+	}
+	if out := reflect.TypeOf((*S)(nil)).Elem(); out != types[1] {
+		return HookMatcher{}, fmt.Errorf("%s hook output must be HookResult[%s], got HookResult[%s]", event, types[1].Name(), out)
+	}
+	return typedHookMatcher(event, matcher, fn), nil
+}
+
+func typedHookMatcher[In, S any](event HookEvent, matcher string, fn TypedHookFunc[In, S]) HookMatcher {
+	callback := func(raw HookInput, _ *string, hookCtx HookContext) (HookJSONOutput, error) {
+		input, err := decodeHookInput[In](event, raw)
+		if err != nil {
+			return nil, err
+		}
+		ctx := hookCtx.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		result, err := fn(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return encodeHookResult(event, result)
+	}
+
+	m := HookMatcher{Hooks: []HookCallback{callback}}
+	if matcher != "" {
+		m.Matcher = &matcher
+	}
+	return m
+}
+
+// decodeHookInput converts the CLI's raw hook input into *In.
+func decodeHookInput[In any](event HookEvent, raw HookInput) (*In, error) {
+	if typed, ok := raw.(*In); ok {
+		return typed, nil
+	}
+	if typed, ok := raw.(In); ok {
+		return &typed, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s hook input: %w", event, err)
+	}
+	var envelope struct {
+		HookEventName string `json:"hook_event_name"`
+	}
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.HookEventName != "" && envelope.HookEventName != string(event) {
+		return nil, fmt.Errorf("%s hook received %s input", event, envelope.HookEventName)
+	}
+	input := new(In)
+	if err := json.Unmarshal(data, input); err != nil {
+		return nil, fmt.Errorf("failed to decode %s hook input: %w", event, err)
+	}
+	return input, nil
+}
+
+// encodeHookResult converts a typed result into the wire shape.
+func encodeHookResult[S any](event HookEvent, result *HookResult[S]) (HookJSONOutput, error) {
+	output := HookJSONOutput{}
+	if result == nil {
+		return output, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s hook output: %w", event, err)
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to encode %s hook output: %w", event, err)
+	}
+
+	if specific, ok := output["hookSpecificOutput"].(map[string]any); ok {
+		if _, none := any(result.HookSpecificOutput).(*NoHookSpecificOutput); none {
+			delete(output, "hookSpecificOutput")
+		} else {
+			specific["hookEventName"] = string(event)
+		}
+	}
+	return output, nil
+}
+
+// OnPreToolUse registers a typed PreToolUse hook for tools matching
+// matcher (a tool name or regex; empty matches all tools).
+//
+// Example:
+//
+//	claudesdk.OnPreToolUse("Bash", func(ctx context.Context, in *claudesdk.PreToolUseHookInput) (*claudesdk.PreToolUseDecision, error) {
+//	    if cmd, _ := in.ToolInput["command"].(string); strings.Contains(cmd, "rm -rf") {
+//	        return claudesdk.NewPreToolUseDecision(claudesdk.PermissionDecisionDeny, "destructive command", nil), nil
+//	    }
+//	    return nil, nil
+//	})
+func OnPreToolUse(matcher string, fn TypedHookFunc[PreToolUseHookInput, PreToolUseHookSpecificOutput]) Option {
+	return WithHook(HookEventPreToolUse, typedHookMatcher(HookEventPreToolUse, matcher, fn))
+}
+
+// OnPostToolUse registers a typed PostToolUse hook for tools matching
+// matcher.
+func OnPostToolUse(matcher string, fn TypedHookFunc[PostToolUseHookInput, PostToolUseHookSpecificOutput]) Option {
+	return WithHook(HookEventPostToolUse, typedHookMatcher(HookEventPostToolUse, matcher, fn))
+}
+
+// OnPostToolUseFailure registers a typed PostToolUseFailure hook for tools
+// matching matcher.
+func OnPostToolUseFailure(matcher string, fn TypedHookFunc[PostToolUseFailureHookInput, PostToolUseFailureHookSpecificOutput]) Option {
+	return WithHook(HookEventPostToolUseFailure, typedHookMatcher(HookEventPostToolUseFailure, matcher, fn))
+}
+
+// OnPermissionRequest registers a typed PermissionRequest hook for tools
+// matching matcher.
+func OnPermissionRequest(matcher string, fn TypedHookFunc[PermissionRequestHookInput, PermissionRequestHookSpecificOutput]) Option {
+	return WithHook(HookEventPermissionRequest, typedHookMatcher(HookEventPermissionRequest, matcher, fn))
+}
+
+// OnUserPromptSubmit registers a typed UserPromptSubmit hook.
+func OnUserPromptSubmit(fn TypedHookFunc[UserPromptSubmitHookInput, UserPromptSubmitHookSpecificOutput]) Option {
+	return WithHook(HookEventUserPromptSubmit, typedHookMatcher(HookEventUserPromptSubmit, "", fn))
+}
+
+// OnNotification registers a typed Notification hook.
+func OnNotification(fn TypedHookFunc[NotificationHookInput, NotificationHookSpecificOutput]) Option {
+	return WithHook(HookEventNotification, typedHookMatcher(HookEventNotification, "", fn))
+}
+
+// OnSubagentStart registers a typed SubagentStart hook for agent types
+// matching matcher.
+func OnSubagentStart(matcher string, fn TypedHookFunc[SubagentStartHookInput, SubagentStartHookSpecificOutput]) Option {
+	return WithHook(HookEventSubagentStart, typedHookMatcher(HookEventSubagentStart, matcher, fn))
+}
+
+// OnStop registers a typed Stop hook.
+func OnStop(fn TypedHookFunc[StopHookInput, NoHookSpecificOutput]) Option {
+	return WithHook(HookEventStop, typedHookMatcher(HookEventStop, "", fn))
+}
+
+// OnSubagentStop registers a typed SubagentStop hook.
+func OnSubagentStop(fn TypedHookFunc[SubagentStopHookInput, NoHookSpecificOutput]) Option {
+	return WithHook(HookEventSubagentStop, typedHookMatcher(HookEventSubagentStop, "", fn))
+}
+
+// OnPreCompact registers a typed PreCompact hook for triggers matching
+// matcher ("manual" or "auto"; empty matches both).
+func OnPreCompact(matcher string, fn TypedHookFunc[PreCompactHookInput, NoHookSpecificOutput]) Option {
+	return WithHook(HookEventPreCompact, typedHookMatcher(HookEventPreCompact, matcher, fn))
+}
+
+// OnSessionStart registers a typed SessionStart hook for sources matching
+// matcher ("startup", "resume", "clear" or "compact"; empty matches all).
+func OnSessionStart(matcher string, fn TypedHookFunc[SessionStartHookInput, SessionStartHookSpecificOutput]) Option {
+	return WithHook(HookEventSessionStart, typedHookMatcher(HookEventSessionStart, matcher, fn))
+}
+
+// OnSessionEnd registers a typed SessionEnd hook.
+func OnSessionEnd(fn TypedHookFunc[SessionEndHookInput, NoHookSpecificOutput]) Option {
+	return WithHook(HookEventSessionEnd, typedHookMatcher(HookEventSessionEnd, "", fn))
+}
+
+// OnPostCompact registers a typed PostCompact hook for triggers matching
+// matcher ("manual" or "auto"; empty matches both).
+func OnPostCompact(matcher string, fn TypedHookFunc[PostCompactHookInput, NoHookSpecificOutput]) Option {
+	return WithHook(HookEventPostCompact, typedHookMatcher(HookEventPostCompact, matcher, fn))
+}
@@ -0,0 +1,118 @@
+package claudesdk
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestOnPreToolUseDecodesInputAndEncodesDecision(t *testing.T) {
+	var got *PreToolUseHookInput
+	opts := NewOptions(OnPreToolUse("Bash", func(ctx context.Context, in *PreToolUseHookInput) (*PreToolUseDecision, error) {
+		got = in
+		return NewPreToolUseDecision(PermissionDecisionDeny, "no rm", map[string]any{"command": "ls"}), nil
+	}))
+
+	matchers := opts.Hooks[string(HookEventPreToolUse)]
+	if len(matchers) != 1 {
+		t.Fatalf("matchers = %v", matchers)
+	}
+	matcher := matchers[0].(HookMatcher)
+	if matcher.Matcher == nil || *matcher.Matcher != "Bash" {
+		t.Errorf("matcher = %v", matcher.Matcher)
+	}
+
+	raw := map[string]any{
+		"hook_event_name": "PreToolUse",
+		"session_id":      "s1",
+		"cwd":             "/repo",
+		"tool_name":       "Bash",
+		"tool_input":      map[string]any{"command": "rm -rf /"},
+		"tool_use_id":     "toolu_1",
+	}
+	out, err := matcher.Hooks[0](raw, nil, HookContext{Context: context.Background()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.SessionID != "s1" || got.ToolName != "Bash" || got.ToolInput["command"] != "rm -rf /" || got.ToolUseID != "toolu_1" {
+		t.Errorf("decoded input = %+v", got)
+	}
+
+	specific := out["hookSpecificOutput"].(map[string]any)
+	if specific["hookEventName"] != "PreToolUse" || specific["permissionDecision"] != "deny" ||
+		specific["permissionDecisionReason"] != "no rm" || specific["updatedInput"].(map[string]any)["command"] != "ls" {
+		t.Errorf("output = %v", out)
+	}
+	if out["reason"] != "no rm" {
+		t.Errorf("reason = %v", out["reason"])
+	}
+}
+
+func TestTypedHookNilResultAndNoSpecificOutput(t *testing.T) {
+	opts := NewOptions(
+		OnPostToolUse("", func(ctx context.Context, in *PostToolUseHookInput) (*PostToolUseDecision, error) {
+			return nil, nil
+		}),
+		OnStop(func(ctx context.Context, in *StopHookInput) (*StopDecision, error) {
+			stop := false
+			return &StopDecision{Continue: &stop, StopReason: "done", HookSpecificOutput: &NoHookSpecificOutput{}}, nil
+		}),
+	)
+
+	post := opts.Hooks[string(HookEventPostToolUse)][0].(HookMatcher)
+	if post.Matcher != nil {
+		t.Errorf("empty matcher should match all, got %q", *post.Matcher)
+	}
+	out, err := post.Hooks[0](map[string]any{"hook_event_name": "PostToolUse"}, nil, HookContext{})
+	if err != nil || len(out) != 0 {
+		t.Errorf("nil result = %v, %v", out, err)
+	}
+
+	stop := opts.Hooks[string(HookEventStop)][0].(HookMatcher)
+	out, err = stop.Hooks[0](map[string]any{"hook_event_name": "Stop", "stop_hook_active": true}, nil, HookContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["continue"] != false || out["stopReason"] != "done" || out["hookSpecificOutput"] != nil {
+		t.Errorf("stop output = %v", out)
+	}
+}
+
+func TestTypedHookRejectsWrongEventInput(t *testing.T) {
+	opts := NewOptions(OnUserPromptSubmit(func(ctx context.Context, in *UserPromptSubmitHookInput) (*UserPromptSubmitDecision, error) {
+		t.Error("callback should not run")
+		return nil, nil
+	}))
+	matcher := opts.Hooks[string(HookEventUserPromptSubmit)][0].(HookMatcher)
+	_, err := matcher.Hooks[0](map[string]any{"hook_event_name": "Stop"}, nil, HookContext{})
+	if err == nil || !strings.Contains(err.Error(), "UserPromptSubmit hook received Stop input") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestNewTypedHookMatcherRejectsMismatchedTypes(t *testing.T) {
+	_, err := NewTypedHookMatcher(HookEventPostToolUse, "",
+		func(ctx context.Context, in *PostToolUseHookInput) (*PreToolUseDecision, error) { return nil, nil })
+	if err == nil || !strings.Contains(err.Error(), "PostToolUse hook output must be HookResult[PostToolUseHookSpecificOutput]") {
+		t.Errorf("output mismatch err = %v", err)
+	}
+
+	_, err = NewTypedHookMatcher(HookEventStop, "",
+		func(ctx context.Context, in *PreToolUseHookInput) (*StopDecision, error) { return nil, nil })
+	if err == nil || !strings.Contains(err.Error(), "Stop hook input must be StopHookInput") {
+		t.Errorf("input mismatch err = %v", err)
+	}
+
+	m, err := NewTypedHookMatcher(HookEventSubagentStart, "reviewer",
+		func(ctx context.Context, in *SubagentStartHookInput) (*SubagentStartDecision, error) {
+			return &SubagentStartDecision{HookSpecificOutput: &SubagentStartHookSpecificOutput{AdditionalContext: "be strict"}}, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := m.Hooks[0](map[string]any{"hook_event_name": "SubagentStart", "agent_type": "reviewer"}, nil, HookContext{})
+	specific, _ := out["hookSpecificOutput"].(map[string]any)
+	if err != nil || specific["hookEventName"] != "SubagentStart" || specific["additionalContext"] != "be strict" {
+		t.Errorf("output = %v, %v", out, err)
+	}
+}
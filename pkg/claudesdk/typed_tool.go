@@ -0,0 +1,135 @@
+package claudesdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/mcp"
+)
+
+// TypedToolHandler handles a tool call with decoded, validated arguments.
+type TypedToolHandler[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// TypedTool creates a ToolDef whose InputSchema is derived from the In
+// struct and whose handler receives decoded arguments.
+//
+// Schema derivation follows these struct tags:
+//   - json: property name; omitempty (or a pointer type) makes it optional
+//   - required:"true"/"false": overrides the json-derived requiredness
+//   - description: property description
+//   - enum:"a,b,c": allowed values, parsed to the field's type
+//   - minimum, maximum, minLength, maxLength, minItems, maxItems, pattern:
+//     the JSON Schema keywords of the same name
+//
+// Arguments are validated against the derived schema and decoded into In,
+// regardless of the server's argument validation setting; failures are
+// reported to Claude as is_error results without calling handler.
+//
+// The result is converted by Out's type:
+//   - string: a single TextContent
+//   - []ToolContent: returned as is
+//   - a struct (or pointer to one): advertised as outputSchema and returned
+//     as structuredContent plus a TextContent with its JSON encoding
+//   - anything else: a TextContent with its JSON encoding
+//
+// Example:
+//
+//	type searchIn struct {
+//	    Query string `json:"query" description:"Search terms"`
+//	    Sort  string `json:"sort,omitempty" enum:"relevance,date"`
+//	}
+//	type searchOut struct {
+//	    Hits []string `json:"hits"`
+//	}
+//	search := TypedTool("search", "Search the docs",
+//	    func(ctx context.Context, in searchIn) (searchOut, error) {
+//	        return searchOut{Hits: find(in.Query, in.Sort)}, nil
+//	    })
+//	server := CreateSDKMcpServer("docs", "1.0.0", search)
+func TypedTool[In, Out any](name, description string, handler TypedToolHandler[In, Out]) *ToolDef {
+	inType := reflect.TypeOf((*In)(nil)).Elem()
+	outType := reflect.TypeOf((*Out)(nil)).Elem()
+
+	inputSchema := mcp.SchemaForType(inType)
+	var outputSchema interface{}
+	structured := isStructType(outType)
+	if structured {
+		outputSchema = mcp.SchemaForType(outType)
+	}
+
+	return &ToolDef{
+		Name:         name,
+		Description:  description,
+		InputSchema:  inputSchema,
+		OutputSchema: outputSchema,
+		Handler: func(ctx context.Context, args map[string]interface{}) ([]ToolContent, error) {
+			in, err := decodeToolArguments[In](inputSchema, args)
+			if err != nil {
+				return nil, NewToolError(err.Error())
+			}
+			out, err := handler(ctx, in)
+			if err != nil {
+				return nil, err
+			}
+			return typedToolResult(out, structured)
+		},
+	}
+}
+
+func isStructType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// decodeToolArguments validates args against schema and decodes them into
+// In.
+func decodeToolArguments[In any](schema map[string]interface{}, args map[string]interface{}) (In, error) {
+	var in In
+	if err := mcp.ValidateSchema(schema, args); err != nil {
+		return in, err
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return in, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return in, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return in, nil
+}
+
+func typedToolResult(out any, structured bool) ([]ToolContent, error) {
+	switch v := out.(type) {
+	case string:
+		return []ToolContent{NewTextContent(v)}, nil
+	case []ToolContent:
+		return v, nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(out); err != nil {
+		return nil, fmt.Errorf("failed to encode tool result: %w", err)
+	}
+	text := strings.TrimSuffix(buf.String(), "\n")
+
+	contents := []ToolContent{NewTextContent(text)}
+	if structured {
+		// Re-decode so structuredContent is the plain JSON object the
+		// outputSchema describes, independent of custom marshalers.
+		var value any
+		if err := json.Unmarshal(buf.Bytes(), &value); err != nil {
+			return nil, fmt.Errorf("failed to encode tool result: %w", err)
+		}
+		contents = append(contents, NewStructuredContent(value))
+	}
+	return contents, nil
+}
@@ -0,0 +1,187 @@
+package claudesdk
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type searchInput struct {
+	Query string  `json:"query" description:"Search terms"`
+	Sort  string  `json:"sort,omitempty" enum:"relevance,date"`
+	Limit int     `json:"limit,omitempty" enum:"10,50"`
+	Tag   *string `json:"tag" required:"true"`
+}
+
+type searchOutput struct {
+	Hits  []string `json:"hits"`
+	Total int      `json:"total"`
+}
+
+func callSDKTool(t *testing.T, server *McpSdkServerConfig, method string, params map[string]any) map[string]any {
+	t.Helper()
+	req, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	resp, err := server.Instance.HandleJSONRPC(context.Background(), req)
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	var out struct {
+		Result map[string]any `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return out.Result
+}
+
+func TestTypedToolSchemasAndStructuredResult(t *testing.T) {
+	var got searchInput
+	search := TypedTool("search", "Search the docs", func(ctx context.Context, in searchInput) (searchOutput, error) {
+		got = in
+		return searchOutput{Hits: []string{"a.md"}, Total: 1}, nil
+	})
+	server := CreateSDKMcpServer("docs", "1.0.0", search)
+
+	tools := callSDKTool(t, server, "tools/list", nil)["tools"].([]any)
+	tool := tools[0].(map[string]any)
+	input := tool["inputSchema"].(map[string]any)
+	props := input["properties"].(map[string]any)
+	if props["query"].(map[string]any)["description"] != "Search terms" {
+		t.Errorf("query schema = %v", props["query"])
+	}
+	if enum := props["sort"].(map[string]any)["enum"].([]any); len(enum) != 2 || enum[1] != "date" {
+		t.Errorf("sort enum = %v", enum)
+	}
+	if enum := props["limit"].(map[string]any)["enum"].([]any); enum[0] != float64(10) {
+		t.Errorf("limit enum = %v", enum)
+	}
+	required := input["required"].([]any)
+	if len(required) != 2 || required[0] != "query" || required[1] != "tag" {
+		t.Errorf("required = %v", required)
+	}
+	output, ok := tool["outputSchema"].(map[string]any)
+	if !ok || output["properties"].(map[string]any)["hits"] == nil {
+		t.Errorf("outputSchema = %v", tool["outputSchema"])
+	}
+
+	result := callSDKTool(t, server, "tools/call", map[string]any{
+		"name":      "search",
+		"arguments": map[string]any{"query": "hooks", "sort": "date", "limit": 50, "tag": "go"},
+	})
+	if result["is_error"] != nil {
+		t.Fatalf("unexpected error result: %v", result)
+	}
+	if got.Query != "hooks" || got.Sort != "date" || got.Limit != 50 || got.Tag == nil || *got.Tag != "go" {
+		t.Errorf("decoded input = %+v", got)
+	}
+	structured := result["structuredContent"].(map[string]any)
+	if structured["total"] != float64(1) {
+		t.Errorf("structuredContent = %v", structured)
+	}
+	content := result["content"].([]any)
+	if len(content) != 1 || content[0].(map[string]any)["text"] != `{"hits":["a.md"],"total":1}` {
+		t.Errorf("content = %v", content)
+	}
+}
+
+func TestTypedToolRejectsInvalidArguments(t *testing.T) {
+	called := false
+	search := TypedTool("search", "", func(ctx context.Context, in searchInput) (string, error) {
+		called = true
+		return "ok", nil
+	})
+	server := CreateSDKMcpServer("docs", "1.0.0", search)
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"missing required", map[string]any{"query": "x"}, `missing required property "tag"`},
+		{"enum", map[string]any{"query": "x", "tag": "t", "sort": "random"}, `sort: must be one of`},
+		{"wrong type", map[string]any{"query": 3, "tag": "t"}, "invalid arguments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callSDKTool(t, server, "tools/call", map[string]any{"name": "search", "arguments": tt.args})
+			text := result["content"].([]any)[0].(map[string]any)["text"].(string)
+			if result["is_error"] != true || !strings.Contains(text, tt.want) {
+				t.Errorf("result = %v, want %q", result, tt.want)
+			}
+		})
+	}
+	if called {
+		t.Error("handler should not run for invalid arguments")
+	}
+
+	tools := callSDKTool(t, server, "tools/list", nil)["tools"].([]any)
+	if _, ok := tools[0].(map[string]any)["outputSchema"]; ok {
+		t.Error("string output should not advertise an outputSchema")
+	}
+	result := callSDKTool(t, server, "tools/call", map[string]any{"name": "search", "arguments": map[string]any{"query": "x", "tag": "t"}})
+	if result["content"].([]any)[0].(map[string]any)["text"] != "ok" || result["structuredContent"] != nil {
+		t.Errorf("result = %v", result)
+	}
+}
+
+func TestTypedToolAcceptsEncodedGoTypes(t *testing.T) {
+	type eventInput struct {
+		When time.Time `json:"when"`
+		Data []byte    `json:"data"`
+		Any  any       `json:"any"`
+	}
+	var got eventInput
+	record := TypedTool("record", "", func(ctx context.Context, in eventInput) (string, error) {
+		got = in
+		return "ok", nil
+	})
+	server := CreateSDKMcpServer("events", "1.0.0", record)
+
+	result := callSDKTool(t, server, "tools/call", map[string]any{"name": "record", "arguments": map[string]any{
+		"when": "2026-10-18T09:30:00Z",
+		"data": "aGk=",
+		"any":  "scalar",
+	}})
+	if result["is_error"] == true {
+		t.Fatalf("result = %v", result)
+	}
+	if !got.When.Equal(time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)) || string(got.Data) != "hi" || got.Any != "scalar" {
+		t.Errorf("decoded input = %+v", got)
+	}
+}
+
+type treeInput struct {
+	Name     string       `json:"name"`
+	Children []*treeInput `json:"children,omitempty"`
+}
+
+func TestTypedToolAcceptsRecursiveAndEmbeddedInput(t *testing.T) {
+	type paging struct {
+		Limit int `json:"limit"`
+	}
+	type request struct {
+		paging
+		Tree   treeInput `json:"tree"`
+		Cursor *string   `json:"cursor"`
+	}
+	var got request
+	walk := TypedTool("walk", "", func(ctx context.Context, in request) (string, error) {
+		got = in
+		return "ok", nil
+	})
+	server := CreateSDKMcpServer("trees", "1.0.0", walk)
+
+	result := callSDKTool(t, server, "tools/call", map[string]any{"name": "walk", "arguments": map[string]any{
+		"limit":  2,
+		"tree":   map[string]any{"name": "root", "children": []any{map[string]any{"name": "leaf"}}},
+		"cursor": nil,
+	}})
+	if result["is_error"] == true {
+		t.Fatalf("result = %v", result)
+	}
+	if got.Limit != 2 || len(got.Tree.Children) != 1 || got.Tree.Children[0].Name != "leaf" || got.Cursor != nil {
+		t.Errorf("decoded input = %+v", got)
+	}
+}
@@ -0,0 +1,330 @@
+// Package claudesdktest unit tests hooks and CanUseTool callbacks without
+// running the CLI.
+//
+// A Harness builds the hook_callback and can_use_tool control requests the
+// CLI would send for a tool call, dispatches them through the SDK's
+// HookProcessor and ControlProtocol exactly as a connected client does,
+// and returns the serialized control_response for assertions. Hook
+// matchers are applied the way the CLI applies them, so a hook registered
+// for "Edit|Write" only runs for those tools.
+//
+// Example:
+//
+//	func TestNoForcePush(t *testing.T) {
+//	    h := claudesdktest.New(claudesdk.OnPreToolUse("Bash", blockForcePush))
+//	    defer h.Close()
+//
+//	    result, err := h.PreToolUse(claudesdktest.Bash("git push --force"))
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//	    result.ExpectDecision(t, claudesdk.PermissionDecisionDeny)
+//
+//	    result, _ = h.PreToolUse(claudesdktest.Read("/repo/main.go"))
+//	    result.ExpectNoHooks(t)
+//	}
+package claudesdktest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/query"
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+	"github.com/jonnyquan/claude-agent-sdk-go/pkg/claudesdk"
+)
+
+// Config sets the session the harness simulates.
+type Config struct {
+	// SessionID defaults to "test-session".
+	SessionID string
+	// Cwd defaults to the current directory.
+	Cwd string
+	// TranscriptPath defaults to <tmp>/<SessionID>.jsonl.
+	TranscriptPath string
+	// PermissionMode defaults to "default".
+	PermissionMode string
+	// Timeout bounds each request; zero means 5 seconds. A request that
+	// times out is cancelled with control_cancel_request like the CLI does.
+	Timeout time.Duration
+}
+
+// Harness drives hooks and the CanUseTool callback of a set of options.
+type Harness struct {
+	config   Config
+	protocol *query.ControlProtocol
+	hooks    map[string][]shared.HookMatcherConfig
+	cancel   context.CancelFunc
+
+	mu      sync.Mutex
+	seq     int
+	waiting map[string]chan []byte
+}
+
+// New creates a harness for the hooks and CanUseTool callback configured
+// by opts.
+func New(opts ...claudesdk.Option) *Harness {
+	return NewWithConfig(Config{}, opts...)
+}
+
+// NewWithConfig creates a harness simulating the session in config.
+func NewWithConfig(config Config, opts ...claudesdk.Option) *Harness {
+	if config.SessionID == "" {
+		config.SessionID = "test-session"
+	}
+	if config.Cwd == "" {
+		config.Cwd, _ = os.Getwd()
+	}
+	if config.TranscriptPath == "" {
+		config.TranscriptPath = filepath.Join(os.TempDir(), config.SessionID+".jsonl")
+	}
+	if config.PermissionMode == "" {
+		config.PermissionMode = "default"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+
+	options := claudesdk.NewOptions(opts...)
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Harness{
+		config:  config,
+		cancel:  cancel,
+		waiting: make(map[string]chan []byte),
+	}
+	processor := query.NewHookProcessor(ctx, options)
+	h.hooks = processor.BuildInitializeConfig()
+
+	var sdkMCPServers map[string]shared.McpSDKServer
+	for name, cfg := range options.McpServers {
+		if sdkCfg, ok := cfg.(*shared.McpSdkServerConfig); ok && sdkCfg.Instance != nil {
+			if sdkMCPServers == nil {
+				sdkMCPServers = make(map[string]shared.McpSDKServer)
+			}
+			sdkMCPServers[name] = sdkCfg.Instance
+		}
+	}
+	h.protocol = query.NewControlProtocol(ctx, processor, h.write, sdkMCPServers)
+	return h
+}
+
+// Close releases the harness.
+func (h *Harness) Close() error {
+	h.cancel()
+	return h.protocol.Close()
+}
+
+// write receives the SDK's outbound messages and hands control responses
+// to the request waiting for them.
+func (h *Harness) write(data []byte) error {
+	var response shared.ControlResponse
+	if err := json.Unmarshal(data, &response); err != nil || response.Type != shared.ControlTypeResponse {
+		return nil
+	}
+	h.mu.Lock()
+	ch, ok := h.waiting[response.Response.RequestID]
+	delete(h.waiting, response.Response.RequestID)
+	h.mu.Unlock()
+	if ok {
+		ch <- []byte(strings.TrimRight(string(data), "\n"))
+	}
+	return nil
+}
+
+// Send dispatches one control request (the "request" object, including
+// its subtype) and returns the SDK's response. If ctx ends first the
+// request is cancelled with control_cancel_request and the cause of ctx is
+// returned.
+func (h *Harness) Send(ctx context.Context, request map[string]any) (*Response, error) {
+	h.mu.Lock()
+	h.seq++
+	requestID := fmt.Sprintf("req_%d", h.seq)
+	ch := make(chan []byte, 1)
+	h.waiting[requestID] = ch
+	h.mu.Unlock()
+
+	data, err := json.Marshal(map[string]any{
+		"type":       shared.ControlTypeRequest,
+		"request_id": requestID,
+		"request":    request,
+	})
+	if err == nil {
+		err = h.protocol.HandleIncomingMessage(shared.ControlTypeRequest, data)
+	}
+	if err != nil {
+		h.forget(requestID)
+		return nil, err
+	}
+
+	select {
+	case raw := <-ch:
+		return decodeResponse(requestID, data, raw)
+	case <-ctx.Done():
+		h.forget(requestID)
+		cancel, _ := json.Marshal(map[string]any{"type": shared.ControlTypeCancelRequest, "request_id": requestID})
+		_ = h.protocol.HandleIncomingMessage(shared.ControlTypeCancelRequest, cancel)
+		return nil, context.Cause(ctx)
+	}
+}
+
+func (h *Harness) forget(requestID string) {
+	h.mu.Lock()
+	delete(h.waiting, requestID)
+	h.mu.Unlock()
+}
+
+func (h *Harness) send(request map[string]any) (*Response, error) {
+	ctx, cancel := context.WithTimeoutCause(context.Background(), h.config.Timeout,
+		fmt.Errorf("claudesdktest: no response within %s", h.config.Timeout))
+	defer cancel()
+	return h.Send(ctx, request)
+}
+
+// toolUseID returns the call's tool_use_id, generating one if unset.
+func (h *Harness) toolUseID(call ToolCall) string {
+	if call.ToolUseID != "" {
+		return call.ToolUseID
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seq++
+	return fmt.Sprintf("toolu_test_%03d", h.seq)
+}
+
+// CanUseToolRequest returns the can_use_tool request the CLI sends for
+// call, with the permission suggestions, prompt title, display name and
+// description it would include. Edit it and pass it to Send to test other
+// shapes.
+func (h *Harness) CanUseToolRequest(call ToolCall) map[string]any {
+	title, displayName, description := permissionPrompt(call)
+	request := map[string]any{
+		"subtype":      shared.ControlSubtypeCanUseTool,
+		"tool_name":    call.ToolName,
+		"input":        call.Input,
+		"tool_use_id":  h.toolUseID(call),
+		"title":        title,
+		"display_name": displayName,
+	}
+	if description != "" {
+		request["description"] = description
+	}
+	if suggestions := permissionSuggestions(call); suggestions != nil {
+		request["permission_suggestions"] = suggestions
+	}
+	if call.AgentID != "" {
+		request["agent_id"] = call.AgentID
+	}
+	return request
+}
+
+// CanUseTool asks the CanUseTool callback about call.
+func (h *Harness) CanUseTool(call ToolCall) (*PermissionResponse, error) {
+	response, err := h.send(h.CanUseToolRequest(call))
+	if err != nil {
+		return nil, err
+	}
+	return &PermissionResponse{Response: response}, nil
+}
+
+// HookInput returns the input the CLI sends to hooks for event: the
+// session fields and hook_event_name, overlaid with fields.
+func (h *Harness) HookInput(event claudesdk.HookEvent, fields map[string]any) map[string]any {
+	input := map[string]any{
+		"session_id":      h.config.SessionID,
+		"transcript_path": h.config.TranscriptPath,
+		"cwd":             h.config.Cwd,
+		"permission_mode": h.config.PermissionMode,
+		"hook_event_name": string(event),
+	}
+	for k, v := range fields {
+		input[k] = v
+	}
+	return input
+}
+
+// toolHookInput returns the hook input for a tool event.
+func (h *Harness) toolHookInput(event claudesdk.HookEvent, call ToolCall, fields map[string]any) map[string]any {
+	input := h.HookInput(event, map[string]any{
+		"tool_name":   call.ToolName,
+		"tool_input":  call.Input,
+		"tool_use_id": h.toolUseID(call),
+	})
+	if call.AgentID != "" {
+		input["agent_id"] = call.AgentID
+	}
+	for k, v := range fields {
+		input[k] = v
+	}
+	return input
+}
+
+// PreToolUse runs the PreToolUse hooks matching call.
+func (h *Harness) PreToolUse(call ToolCall) (*HookResult, error) {
+	return h.RunHook(claudesdk.HookEventPreToolUse, h.toolHookInput(claudesdk.HookEventPreToolUse, call, nil))
+}
+
+// PostToolUse runs the PostToolUse hooks matching call. A nil response
+// uses DefaultToolResponse.
+func (h *Harness) PostToolUse(call ToolCall, response any) (*HookResult, error) {
+	if response == nil {
+		response = DefaultToolResponse(call)
+	}
+	return h.RunHook(claudesdk.HookEventPostToolUse, h.toolHookInput(claudesdk.HookEventPostToolUse, call, map[string]any{
+		"tool_response": response,
+	}))
+}
+
+// PostToolUseFailure runs the PostToolUseFailure hooks matching call.
+func (h *Harness) PostToolUseFailure(call ToolCall, errorMessage string, interrupted bool) (*HookResult, error) {
+	return h.RunHook(claudesdk.HookEventPostToolUseFailure, h.toolHookInput(claudesdk.HookEventPostToolUseFailure, call, map[string]any{
+		"error":        errorMessage,
+		"is_interrupt": interrupted,
+	}))
+}
+
+// PermissionRequest runs the PermissionRequest hooks matching call.
+func (h *Harness) PermissionRequest(call ToolCall) (*HookResult, error) {
+	fields := map[string]any{}
+	if suggestions := permissionSuggestions(call); suggestions != nil {
+		fields["permission_suggestions"] = suggestions
+	}
+	input := h.toolHookInput(claudesdk.HookEventPermissionRequest, call, fields)
+	delete(input, "tool_use_id")
+	return h.RunHook(claudesdk.HookEventPermissionRequest, input)
+}
+
+// RunHook sends input to every hook callback whose matcher matches it, in
+// registration order, as hook_callback requests. Build input with
+// HookInput, e.g. h.HookInput(claudesdk.HookEventSessionStart,
+// map[string]any{"source": "startup"}).
+func (h *Harness) RunHook(event claudesdk.HookEvent, input map[string]any) (*HookResult, error) {
+	result := &HookResult{Event: event, Input: input}
+	value, filtered := shared.HookMatchValue(event, input)
+	for _, matcher := range h.hooks[string(event)] {
+		if filtered && !shared.HookMatcherMatches(matcher.Matcher, value) {
+			continue
+		}
+		for _, callbackID := range matcher.HookCallbackIDs {
+			request := map[string]any{
+				"subtype":     shared.ControlSubtypeHookCallback,
+				"callback_id": callbackID,
+				"input":       input,
+			}
+			if toolUseID, ok := input["tool_use_id"].(string); ok {
+				request["tool_use_id"] = toolUseID
+			}
+			response, err := h.send(request)
+			if err != nil {
+				return nil, err
+			}
+			result.Responses = append(result.Responses, response)
+		}
+	}
+	return result, nil
+}
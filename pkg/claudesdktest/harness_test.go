@@ -0,0 +1,165 @@
+package claudesdktest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/pkg/claudesdk"
+)
+
+func TestPreToolUseMatchersAndDecisions(t *testing.T) {
+	var seen []string
+	h := New(
+		claudesdk.OnPreToolUse("Edit|Write", func(_ context.Context, in *claudesdk.PreToolUseHookInput) (*claudesdk.PreToolUseDecision, error) {
+			seen = append(seen, in.ToolName)
+			if path, _ := in.ToolInput["file_path"].(string); strings.HasPrefix(path, "/etc/") {
+				return claudesdk.NewPreToolUseDecision(claudesdk.PermissionDecisionDeny, "system files are read-only", nil), nil
+			}
+			return nil, nil
+		}),
+		claudesdk.OnPreToolUse(`mcp__github__.*`, func(_ context.Context, in *claudesdk.PreToolUseHookInput) (*claudesdk.PreToolUseDecision, error) {
+			seen = append(seen, in.ToolName)
+			return claudesdk.NewPreToolUseDecision(claudesdk.PermissionDecisionAllow, "", nil), nil
+		}),
+	)
+	defer h.Close()
+
+	result, err := h.PreToolUse(Write("/etc/hosts", "127.0.0.1 example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.ExpectDecision(t, claudesdk.PermissionDecisionDeny)
+	if result.Reason() != "system files are read-only" {
+		t.Fatalf("unexpected reason %q", result.Reason())
+	}
+	if input := result.Input; input["session_id"] != "test-session" || input["tool_use_id"] == "" || input["hook_event_name"] != "PreToolUse" {
+		t.Fatalf("unrealistic hook input %v", input)
+	}
+
+	result, _ = h.PreToolUse(Edit("/repo/main.go", "a", "b"))
+	result.ExpectDecision(t, "")
+
+	result, _ = h.PreToolUse(MCP("github", "get_issue", map[string]any{"number": 1}))
+	result.ExpectDecision(t, claudesdk.PermissionDecisionAllow)
+
+	for _, call := range []ToolCall{Bash("ls"), Read("/repo/main.go"), WebFetch("https://go.dev", "summarize"), MCP("gitlab", "get_issue", nil)} {
+		result, _ = h.PreToolUse(call)
+		result.ExpectNoHooks(t)
+	}
+	if strings.Join(seen, ",") != "Write,Edit,mcp__github__get_issue" {
+		t.Fatalf("unexpected hooks ran: %v", seen)
+	}
+}
+
+func TestPostToolUseAndSessionHooks(t *testing.T) {
+	var response any
+	h := New(
+		claudesdk.OnPostToolUse("Bash", func(_ context.Context, in *claudesdk.PostToolUseHookInput) (*claudesdk.PostToolUseDecision, error) {
+			response = in.ToolResponse
+			return &claudesdk.PostToolUseDecision{HookSpecificOutput: &claudesdk.PostToolUseHookSpecificOutput{AdditionalContext: "checked"}}, nil
+		}),
+		claudesdk.OnSessionStart("resume", func(context.Context, *claudesdk.SessionStartHookInput) (*claudesdk.SessionStartDecision, error) {
+			return nil, errors.New("resume not allowed")
+		}),
+	)
+	defer h.Close()
+
+	call := Bash("go test ./...")
+	call.ToolUseID = "toolu_fixed"
+	result, err := h.PostToolUse(call, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AdditionalContext() != "checked" || !result.Continue() {
+		t.Fatalf("unexpected outputs %v", result.Outputs())
+	}
+	if stdout, ok := response.(map[string]any)["stdout"]; !ok || stdout != "" {
+		t.Fatalf("expected a realistic Bash response, got %v", response)
+	}
+	if !strings.Contains(string(result.Responses[0].Request), `"tool_use_id":"toolu_fixed"`) {
+		t.Fatalf("expected the tool_use_id on the request, got %s", result.Responses[0].Request)
+	}
+
+	result, _ = h.RunHook(claudesdk.HookEventSessionStart, h.HookInput(claudesdk.HookEventSessionStart, map[string]any{"source": "startup"}))
+	result.ExpectNoHooks(t)
+	result, _ = h.RunHook(claudesdk.HookEventSessionStart, h.HookInput(claudesdk.HookEventSessionStart, map[string]any{"source": "resume"}))
+	result.Responses[0].ExpectError(t, "resume not allowed")
+}
+
+func TestCanUseToolResponses(t *testing.T) {
+	h := New(claudesdk.WithCanUseTool(func(toolName string, input map[string]any, permCtx claudesdk.ToolPermissionContext) (claudesdk.PermissionResult, error) {
+		switch toolName {
+		case "Bash":
+			return claudesdk.NewPermissionAllow(input, permCtx.Suggestions), nil
+		case "WebFetch":
+			return claudesdk.NewPermissionDeny("no network: "+*permCtx.Title, true), nil
+		}
+		return nil, errors.New("unexpected tool " + toolName)
+	}))
+	defer h.Close()
+
+	response, err := h.CanUseTool(Bash("npm test -- --watch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.ExpectAllow(t)
+	response.ExpectPayload(t, `{
+		"behavior": "allow",
+		"updatedInput": {"command": "npm test -- --watch", "description": "Run npm test"},
+		"updatedPermissions": [{
+			"type": "addRules",
+			"rules": [{"toolName": "Bash", "ruleContent": "npm test:*"}],
+			"behavior": "allow",
+			"destination": "localSettings"
+		}]
+	}`)
+	if updates := response.UpdatedPermissions(); len(updates) != 1 || *updates[0].Rules[0].RuleContent != "npm test:*" {
+		t.Fatalf("unexpected updates %+v", updates)
+	}
+
+	response, _ = h.CanUseTool(WebFetch("https://example.com/a", "read"))
+	response.ExpectDeny(t, "Claude wants to fetch example.com")
+	if !response.Interrupt() {
+		t.Fatal("expected interrupt")
+	}
+
+	response, _ = h.CanUseTool(Read("/repo/main.go"))
+	response.ExpectError(t, "unexpected tool Read")
+
+	var envelope struct {
+		Request map[string]any `json:"request"`
+	}
+	if err := json.Unmarshal(response.Request, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if envelope.Request["subtype"] != "can_use_tool" || envelope.Request["display_name"] != "Read file" || envelope.Request["permission_suggestions"] == nil {
+		t.Fatalf("unrealistic can_use_tool request %s", response.Request)
+	}
+}
+
+func TestTimeoutCancelsRequest(t *testing.T) {
+	aborted := make(chan error, 1)
+	h := NewWithConfig(Config{Timeout: 50 * time.Millisecond}, claudesdk.WithCanUseTool(
+		func(_ string, _ map[string]any, permCtx claudesdk.ToolPermissionContext) (claudesdk.PermissionResult, error) {
+			<-permCtx.Signal.Done()
+			aborted <- permCtx.Signal.Reason()
+			return claudesdk.NewPermissionDeny("aborted", false), nil
+		}))
+	defer h.Close()
+
+	if _, err := h.CanUseTool(Bash("sleep 100")); err == nil || !strings.Contains(err.Error(), "no response within") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	select {
+	case reason := <-aborted:
+		if !errors.Is(reason, claudesdk.ErrAbortCancelled) {
+			t.Fatalf("expected the callback to see a cancel, got %v", reason)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("callback was not aborted")
+	}
+}
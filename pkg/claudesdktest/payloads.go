@@ -0,0 +1,183 @@
+package claudesdktest
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// ToolCall is a tool invocation as Claude would issue it.
+type ToolCall struct {
+	ToolName string
+	Input    map[string]any
+	// ToolUseID is generated by the harness when empty.
+	ToolUseID string
+	// AgentID is set for calls made by a subagent.
+	AgentID string
+}
+
+// Bash is a Bash tool call.
+func Bash(command string) ToolCall {
+	return ToolCall{ToolName: "Bash", Input: map[string]any{
+		"command":     command,
+		"description": "Run " + firstWords(command, 2),
+	}}
+}
+
+// Edit is an Edit tool call replacing oldString with newString.
+func Edit(filePath, oldString, newString string) ToolCall {
+	return ToolCall{ToolName: "Edit", Input: map[string]any{
+		"file_path":  filePath,
+		"old_string": oldString,
+		"new_string": newString,
+	}}
+}
+
+// Write is a Write tool call.
+func Write(filePath, content string) ToolCall {
+	return ToolCall{ToolName: "Write", Input: map[string]any{
+		"file_path": filePath,
+		"content":   content,
+	}}
+}
+
+// Read is a Read tool call.
+func Read(filePath string) ToolCall {
+	return ToolCall{ToolName: "Read", Input: map[string]any{
+		"file_path": filePath,
+	}}
+}
+
+// WebFetch is a WebFetch tool call.
+func WebFetch(rawURL, prompt string) ToolCall {
+	return ToolCall{ToolName: "WebFetch", Input: map[string]any{
+		"url":    rawURL,
+		"prompt": prompt,
+	}}
+}
+
+// MCP is a call to tool on an MCP server, named mcp__<server>__<tool>.
+func MCP(server, tool string, arguments map[string]any) ToolCall {
+	if arguments == nil {
+		arguments = map[string]any{}
+	}
+	return ToolCall{ToolName: "mcp__" + server + "__" + tool, Input: arguments}
+}
+
+// DefaultToolResponse returns a tool_response shaped like the CLI's for
+// call, as sent in PostToolUse.
+func DefaultToolResponse(call ToolCall) any {
+	filePath, _ := call.Input["file_path"].(string)
+	switch call.ToolName {
+	case "Bash":
+		return map[string]any{"stdout": "", "stderr": "", "interrupted": false, "isImage": false}
+	case "Read":
+		return map[string]any{"type": "text", "file": map[string]any{
+			"filePath": filePath, "content": "", "numLines": 0, "startLine": 1, "totalLines": 0,
+		}}
+	case "Edit":
+		return map[string]any{
+			"filePath":        filePath,
+			"oldString":       call.Input["old_string"],
+			"newString":       call.Input["new_string"],
+			"structuredPatch": []any{},
+			"userModified":    false,
+			"replaceAll":      false,
+		}
+	case "Write":
+		return map[string]any{"type": "create", "filePath": filePath, "content": call.Input["content"], "structuredPatch": []any{}}
+	case "WebFetch":
+		rawURL, _ := call.Input["url"].(string)
+		return map[string]any{"bytes": 0, "code": 200, "codeText": "OK", "result": "", "durationMs": 0, "url": rawURL}
+	}
+	if strings.HasPrefix(call.ToolName, "mcp__") {
+		return []any{map[string]any{"type": "text", "text": ""}}
+	}
+	return map[string]any{}
+}
+
+// permissionPrompt returns the title, display name and description the CLI
+// shows for call.
+func permissionPrompt(call ToolCall) (title, displayName, description string) {
+	filePath, _ := call.Input["file_path"].(string)
+	base := filepath.Base(filePath)
+	switch call.ToolName {
+	case "Bash":
+		command, _ := call.Input["command"].(string)
+		description, _ = call.Input["description"].(string)
+		return "Claude wants to run " + command, "Run command", description
+	case "Edit":
+		return "Claude wants to edit " + base, "Edit file", filePath
+	case "Write":
+		return "Claude wants to write " + base, "Write file", filePath
+	case "Read":
+		return "Claude wants to read " + base, "Read file", filePath
+	case "WebFetch":
+		rawURL, _ := call.Input["url"].(string)
+		return "Claude wants to fetch " + hostOf(rawURL), "Fetch URL", rawURL
+	}
+	if server, tool, ok := strings.Cut(strings.TrimPrefix(call.ToolName, "mcp__"), "__"); ok && strings.HasPrefix(call.ToolName, "mcp__") {
+		return fmt.Sprintf("Claude wants to use %s from %s", tool, server), "MCP tool", ""
+	}
+	return "Claude wants to use " + call.ToolName, call.ToolName, ""
+}
+
+// permissionSuggestions returns the suggestions the CLI offers for call,
+// in wire format.
+func permissionSuggestions(call ToolCall) []any {
+	addRule := func(ruleContent string) []any {
+		rule := map[string]any{"toolName": call.ToolName}
+		if ruleContent != "" {
+			rule["ruleContent"] = ruleContent
+		}
+		return []any{map[string]any{
+			"type":        "addRules",
+			"rules":       []any{rule},
+			"behavior":    "allow",
+			"destination": "localSettings",
+		}}
+	}
+	switch call.ToolName {
+	case "Bash":
+		command, _ := call.Input["command"].(string)
+		return addRule(commandPrefix(command) + ":*")
+	case "Edit", "Write":
+		return []any{map[string]any{"type": "setMode", "mode": "acceptEdits", "destination": "session"}}
+	case "Read":
+		filePath, _ := call.Input["file_path"].(string)
+		return addRule(filepath.Dir(filePath) + "/**")
+	case "WebFetch":
+		rawURL, _ := call.Input["url"].(string)
+		return addRule("domain:" + hostOf(rawURL))
+	}
+	if strings.HasPrefix(call.ToolName, "mcp__") {
+		return addRule("")
+	}
+	return nil
+}
+
+// commandPrefix returns the command and its subcommand, if any.
+func commandPrefix(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) > 1 && !strings.HasPrefix(fields[1], "-") {
+		return fields[0] + " " + fields[1]
+	}
+	return firstWords(command, 1)
+}
+
+func firstWords(s string, n int) string {
+	fields := strings.Fields(s)
+	if len(fields) > n {
+		fields = fields[:n]
+	}
+	return strings.Join(fields, " ")
+}
+
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Hostname()
+}
@@ -0,0 +1,283 @@
+package claudesdktest
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/internal/shared"
+	"github.com/jonnyquan/claude-agent-sdk-go/pkg/claudesdk"
+)
+
+// Response is the SDK's control_response to one request.
+type Response struct {
+	RequestID string
+	// Request and Raw are the serialized control_request sent and
+	// control_response written back.
+	Request []byte
+	Raw     []byte
+	// Subtype is "success" or "error".
+	Subtype string
+	// Payload is the response object of a success; Error the message of
+	// an error.
+	Payload map[string]any
+	Error   string
+}
+
+func decodeResponse(requestID string, request, raw []byte) (*Response, error) {
+	var envelope shared.ControlResponse
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, err
+	}
+	return &Response{
+		RequestID: requestID,
+		Request:   request,
+		Raw:       raw,
+		Subtype:   envelope.Response.Subtype,
+		Payload:   envelope.Response.Response,
+		Error:     envelope.Response.Error,
+	}, nil
+}
+
+// Err returns the error response as an error, or nil for a success.
+func (r *Response) Err() error {
+	if r.Subtype == shared.ControlSubtypeError {
+		return errors.New(r.Error)
+	}
+	return nil
+}
+
+// ExpectSuccess fails t unless the response is a success.
+func (r *Response) ExpectSuccess(t testing.TB) {
+	t.Helper()
+	if r.Subtype != shared.ControlSubtypeSuccess {
+		t.Fatalf("expected a success response, got %s", r.Raw)
+	}
+}
+
+// ExpectError fails t unless the response is an error containing substr.
+func (r *Response) ExpectError(t testing.TB, substr string) {
+	t.Helper()
+	if r.Subtype != shared.ControlSubtypeError || !strings.Contains(r.Error, substr) {
+		t.Fatalf("expected an error response containing %q, got %s", substr, r.Raw)
+	}
+}
+
+// ExpectPayload fails t unless the response payload equals want, a JSON
+// document compared semantically.
+func (r *Response) ExpectPayload(t testing.TB, want string) {
+	t.Helper()
+	var wantValue, gotValue any
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid expected payload: %v", err)
+	}
+	got, _ := json.Marshal(r.Payload)
+	_ = json.Unmarshal(got, &gotValue)
+	if r.Subtype != shared.ControlSubtypeSuccess || !reflect.DeepEqual(gotValue, wantValue) {
+		t.Fatalf("unexpected response payload\n got: %s\nwant: %s", r.Raw, want)
+	}
+}
+
+// PermissionResponse is the SDK's answer to a can_use_tool request.
+type PermissionResponse struct {
+	*Response
+}
+
+// Behavior is "allow" or "deny".
+func (r *PermissionResponse) Behavior() string {
+	behavior, _ := r.Payload["behavior"].(string)
+	return behavior
+}
+
+// Message is the deny message.
+func (r *PermissionResponse) Message() string {
+	message, _ := r.Payload["message"].(string)
+	return message
+}
+
+// Interrupt reports whether a deny stops the run.
+func (r *PermissionResponse) Interrupt() bool {
+	interrupt, _ := r.Payload["interrupt"].(bool)
+	return interrupt
+}
+
+// UpdatedInput is the input an allow replaces the tool's with, if any.
+func (r *PermissionResponse) UpdatedInput() map[string]any {
+	input, _ := r.Payload["updatedInput"].(map[string]any)
+	return input
+}
+
+// UpdatedPermissions are the permission updates an allow applies.
+func (r *PermissionResponse) UpdatedPermissions() []claudesdk.PermissionUpdate {
+	raw, _ := r.Payload["updatedPermissions"].([]any)
+	updates := make([]claudesdk.PermissionUpdate, 0, len(raw))
+	for _, item := range raw {
+		if data, ok := item.(map[string]any); ok {
+			updates = append(updates, claudesdk.PermissionUpdateFromDict(data))
+		}
+	}
+	return updates
+}
+
+// ExpectAllow fails t unless the tool call was allowed.
+func (r *PermissionResponse) ExpectAllow(t testing.TB) {
+	t.Helper()
+	if r.Behavior() != string(shared.PermissionBehaviorAllow) {
+		t.Fatalf("expected allow, got %s", r.Raw)
+	}
+}
+
+// ExpectDeny fails t unless the tool call was denied with a message
+// containing substr.
+func (r *PermissionResponse) ExpectDeny(t testing.TB, substr string) {
+	t.Helper()
+	if r.Behavior() != string(shared.PermissionBehaviorDeny) || !strings.Contains(r.Message(), substr) {
+		t.Fatalf("expected deny containing %q, got %s", substr, r.Raw)
+	}
+}
+
+// HookResult holds the responses of every hook callback that ran for one
+// event.
+type HookResult struct {
+	Event claudesdk.HookEvent
+	// Input is the hook input sent to each callback.
+	Input map[string]any
+	// Responses are in callback order; empty when no matcher matched.
+	Responses []*Response
+}
+
+// Err returns the first error response as an error.
+func (r *HookResult) Err() error {
+	for _, response := range r.Responses {
+		if err := response.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Outputs returns the hook outputs of the successful callbacks.
+func (r *HookResult) Outputs() []map[string]any {
+	var outputs []map[string]any
+	for _, response := range r.Responses {
+		if response.Subtype == shared.ControlSubtypeSuccess {
+			outputs = append(outputs, response.Payload)
+		}
+	}
+	return outputs
+}
+
+// decisionRank orders decisions the way the CLI combines them: any deny
+// wins, then defer, then ask, then allow.
+var decisionRank = map[string]int{
+	shared.PermissionDecisionAllow: 1,
+	shared.PermissionDecisionAsk:   2,
+	shared.PermissionDecisionDefer: 3,
+	shared.PermissionDecisionDeny:  4,
+}
+
+// Decision combines the callbacks' permission decisions: PreToolUse's
+// permissionDecision, PermissionRequest's decision.behavior, or a legacy
+// top-level "block" (deny) or "approve" (allow). It is "" when no callback
+// decided.
+func (r *HookResult) Decision() string {
+	decision, _ := r.decide()
+	return decision
+}
+
+// Reason is the reason given with the winning decision.
+func (r *HookResult) Reason() string {
+	_, reason := r.decide()
+	return reason
+}
+
+func (r *HookResult) decide() (decision, reason string) {
+	for _, output := range r.Outputs() {
+		d, why := outputDecision(output)
+		if decisionRank[d] > decisionRank[decision] {
+			decision, reason = d, why
+		}
+	}
+	return decision, reason
+}
+
+func outputDecision(output map[string]any) (decision, reason string) {
+	specific, _ := output["hookSpecificOutput"].(map[string]any)
+	if d, ok := specific["permissionDecision"].(string); ok && d != "" {
+		reason, _ = specific["permissionDecisionReason"].(string)
+		return d, reason
+	}
+	if nested, ok := specific["decision"].(map[string]any); ok {
+		d, _ := nested["behavior"].(string)
+		reason, _ = nested["message"].(string)
+		return d, reason
+	}
+	reason, _ = output["reason"].(string)
+	switch output["decision"] {
+	case "block":
+		return shared.PermissionDecisionDeny, reason
+	case "approve":
+		return shared.PermissionDecisionAllow, reason
+	}
+	return "", ""
+}
+
+// UpdatedInput is the last updatedInput a callback returned, if any.
+func (r *HookResult) UpdatedInput() map[string]any {
+	var updated map[string]any
+	for _, output := range r.Outputs() {
+		specific, _ := output["hookSpecificOutput"].(map[string]any)
+		if input, ok := specific["updatedInput"].(map[string]any); ok {
+			updated = input
+		}
+		if nested, ok := specific["decision"].(map[string]any); ok {
+			if input, ok := nested["updatedInput"].(map[string]any); ok {
+				updated = input
+			}
+		}
+	}
+	return updated
+}
+
+// AdditionalContext joins the additionalContext of every callback.
+func (r *HookResult) AdditionalContext() string {
+	var parts []string
+	for _, output := range r.Outputs() {
+		specific, _ := output["hookSpecificOutput"].(map[string]any)
+		if text, ok := specific["additionalContext"].(string); ok && text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Continue is false when any callback asked to stop the run.
+func (r *HookResult) Continue() bool {
+	for _, output := range r.Outputs() {
+		if proceed, ok := output["continue"].(bool); ok && !proceed {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpectDecision fails t unless the combined decision is decision.
+func (r *HookResult) ExpectDecision(t testing.TB, decision string) {
+	t.Helper()
+	if err := r.Err(); err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	if got := r.Decision(); got != decision {
+		t.Fatalf("expected %s decision %q, got %q (reason %q)", r.Event, decision, got, r.Reason())
+	}
+}
+
+// ExpectNoHooks fails t if any callback ran.
+func (r *HookResult) ExpectNoHooks(t testing.TB) {
+	t.Helper()
+	if len(r.Responses) > 0 {
+		t.Fatalf("expected no %s hooks to run, %d did", r.Event, len(r.Responses))
+	}
+}
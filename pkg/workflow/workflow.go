@@ -0,0 +1,642 @@
+// Package workflow chains several Claude runs into a dependency graph.
+//
+// Each Step is a single Query (or Client) invocation with its own options,
+// agents and output schema. Step prompts are text/template strings rendered
+// against the workflow input and the results of earlier steps, so the output
+// of a "plan" step can feed an "implement" step without manual plumbing.
+// Steps whose dependencies are satisfied run in parallel; a step can resume
+// or fork the session of a previous step.
+//
+// Example:
+//
+//	wf, err := workflow.New([]workflow.Step{
+//	    {
+//	        Name:         "plan",
+//	        Prompt:       "Write a plan for: {{.Input}}",
+//	        OutputSchema: planSchema,
+//	    },
+//	    {
+//	        Name:       "implement",
+//	        Prompt:     "Implement this plan: {{json .Steps.plan.Output}}",
+//	        ResumeFrom: "plan",
+//	        Fork:       true,
+//	    },
+//	    {
+//	        Name:      "review",
+//	        Prompt:    "Review the change: {{.Steps.implement.Text}}",
+//	        DependsOn: []string{"implement"},
+//	    },
+//	})
+//	res, err := wf.Run(ctx, "add a --verbose flag")
+//	plan, err := workflow.Output[Plan](res, "plan")
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/pkg/claudesdk"
+)
+
+// ErrStepSkipped is reported on steps that never ran because a dependency
+// failed or the workflow was cancelled.
+var ErrStepSkipped = errors.New("step skipped")
+
+// Runner executes one rendered step prompt and returns its message stream.
+// claudesdk.Query has this signature; see also ClientRunner.
+type Runner func(ctx context.Context, prompt string, opts ...claudesdk.Option) (claudesdk.MessageIterator, error)
+
+// Step is a node in the workflow graph.
+type Step struct {
+	// Name identifies the step. It must be unique and is the key used in
+	// templates ({{.Steps.<name>}}) and in Result.Steps. A prompt may only
+	// reference the steps it depends on.
+	Name string
+
+	// Prompt is a text/template rendered against TemplateData.
+	Prompt string
+
+	// Options are applied after the workflow-wide options.
+	Options []claudesdk.Option
+
+	// Agents configures custom agents for this step.
+	Agents map[string]claudesdk.AgentDefinition
+
+	// OutputSchema, when set, requests structured output with this JSON
+	// schema. The decoded value is available as StepResult.Output.
+	OutputSchema map[string]any
+
+	// DependsOn lists steps that must succeed before this one runs.
+	DependsOn []string
+
+	// ResumeFrom resumes the session of the named step. It implies a
+	// dependency on that step.
+	ResumeFrom string
+
+	// Fork forks the resumed session instead of continuing it, so sibling
+	// steps can branch from the same point independently.
+	Fork bool
+
+	// Runner overrides the workflow runner for this step.
+	Runner Runner
+}
+
+// StepResult is the outcome of one step.
+type StepResult struct {
+	Name string
+
+	// Prompt is the rendered prompt that was sent.
+	Prompt string
+
+	// Text is the final result text.
+	Text string
+
+	// Output is the structured output, when OutputSchema was set.
+	Output any
+
+	SessionID string
+	Result    *claudesdk.ResultMessage
+	Messages  []claudesdk.Message
+	CostUSD   float64
+	Duration  time.Duration
+	Err       error
+}
+
+// Result is the outcome of a workflow run.
+type Result struct {
+	// Steps holds every step's result, keyed by step name.
+	Steps map[string]*StepResult
+
+	// Order lists step names in completion order.
+	Order []string
+
+	TotalCostUSD float64
+}
+
+// Step returns the named step result, or nil.
+func (r *Result) Step(name string) *StepResult {
+	if r == nil {
+		return nil
+	}
+	return r.Steps[name]
+}
+
+// Output decodes the structured output of a step into T.
+func Output[T any](r *Result, step string) (T, error) {
+	var zero T
+	sr := r.Step(step)
+	if sr == nil {
+		return zero, fmt.Errorf("workflow: unknown step %q", step)
+	}
+	if sr.Err != nil {
+		return zero, fmt.Errorf("workflow: step %q failed: %w", step, sr.Err)
+	}
+	data, err := json.Marshal(sr.Output)
+	if err != nil {
+		return zero, err
+	}
+	if err := json.Unmarshal(data, &zero); err != nil {
+		return zero, fmt.Errorf("workflow: decode output of step %q: %w", step, err)
+	}
+	return zero, nil
+}
+
+// TemplateData is the value step prompts are rendered against.
+type TemplateData struct {
+	Input any
+	Steps map[string]*StepResult
+}
+
+// Option configures a Workflow.
+type Option func(*Workflow)
+
+// WithOptions sets options applied to every step before the step's own.
+func WithOptions(opts ...claudesdk.Option) Option {
+	return func(w *Workflow) {
+		w.options = append(w.options, opts...)
+	}
+}
+
+// WithRunner sets the default step runner. The default is claudesdk.Query.
+func WithRunner(runner Runner) Option {
+	return func(w *Workflow) {
+		w.runner = runner
+	}
+}
+
+// WithMaxParallel bounds how many steps run at once. Values < 1 mean no limit.
+func WithMaxParallel(n int) Option {
+	return func(w *Workflow) {
+		w.maxParallel = n
+	}
+}
+
+// WithContinueOnError keeps running independent steps after a step fails.
+// By default the first failure cancels the rest of the workflow.
+func WithContinueOnError(enabled bool) Option {
+	return func(w *Workflow) {
+		w.continueOnError = enabled
+	}
+}
+
+// Workflow is a validated, reusable step graph.
+type Workflow struct {
+	steps           []Step
+	prompts         map[string]*template.Template
+	deps            map[string][]string
+	options         []claudesdk.Option
+	runner          Runner
+	maxParallel     int
+	continueOnError bool
+}
+
+// New validates the steps and returns a Workflow. Step names must be unique,
+// dependencies must exist, the graph must be acyclic, prompts must parse and
+// every step a prompt references must be one of its dependencies, so a
+// prompt never depends on which unrelated step happened to finish first.
+func New(steps []Step, opts ...Option) (*Workflow, error) {
+	w := &Workflow{
+		steps:   steps,
+		prompts: make(map[string]*template.Template, len(steps)),
+		deps:    make(map[string][]string, len(steps)),
+		runner:  claudesdk.Query,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	for _, s := range steps {
+		if s.Name == "" {
+			return nil, fmt.Errorf("workflow: step name cannot be empty")
+		}
+		if _, dup := w.deps[s.Name]; dup {
+			return nil, fmt.Errorf("workflow: duplicate step %q", s.Name)
+		}
+		deps := append([]string(nil), s.DependsOn...)
+		if s.ResumeFrom != "" && !contains(deps, s.ResumeFrom) {
+			deps = append(deps, s.ResumeFrom)
+		}
+		if s.Fork && s.ResumeFrom == "" {
+			return nil, fmt.Errorf("workflow: step %q sets Fork without ResumeFrom", s.Name)
+		}
+		w.deps[s.Name] = deps
+
+		tmpl, err := template.New(s.Name).
+			Option("missingkey=error").
+			Funcs(template.FuncMap{"json": toJSON}).
+			Parse(s.Prompt)
+		if err != nil {
+			return nil, fmt.Errorf("workflow: step %q prompt: %w", s.Name, err)
+		}
+		w.prompts[s.Name] = tmpl
+	}
+	for name, deps := range w.deps {
+		for _, dep := range deps {
+			if _, ok := w.deps[dep]; !ok {
+				return nil, fmt.Errorf("workflow: step %q depends on unknown step %q", name, dep)
+			}
+		}
+	}
+	if cycle := w.findCycle(); cycle != nil {
+		return nil, fmt.Errorf("workflow: dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+	for _, s := range steps {
+		refs, err := stepReferences(w.prompts[s.Name])
+		if err != nil {
+			return nil, fmt.Errorf("workflow: step %q prompt: %w", s.Name, err)
+		}
+		for _, ref := range refs {
+			if !contains(w.deps[s.Name], ref) {
+				return nil, fmt.Errorf("workflow: step %q prompt references step %q, which is not in its DependsOn", s.Name, ref)
+			}
+		}
+	}
+	return w, nil
+}
+
+// Run executes the workflow. Per-step failures are reported on the step
+// results; the returned error wraps the first step failure, or is ctx's
+// error if the run was cancelled. The Result is returned in both cases.
+func (w *Workflow) Run(ctx context.Context, input any) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := &Result{Steps: make(map[string]*StepResult, len(w.steps))}
+	var (
+		mu       sync.Mutex
+		firstErr error
+		done     = make(map[string]chan struct{}, len(w.steps))
+		wg       sync.WaitGroup
+		sem      chan struct{}
+	)
+	if w.maxParallel > 0 {
+		sem = make(chan struct{}, w.maxParallel)
+	}
+	for _, s := range w.steps {
+		done[s.Name] = make(chan struct{})
+	}
+
+	finish := func(sr *StepResult) {
+		mu.Lock()
+		result.Steps[sr.Name] = sr
+		result.Order = append(result.Order, sr.Name)
+		result.TotalCostUSD += sr.CostUSD
+		if sr.Err != nil && !errors.Is(sr.Err, ErrStepSkipped) && firstErr == nil {
+			firstErr = fmt.Errorf("workflow: step %q: %w", sr.Name, sr.Err)
+			if !w.continueOnError {
+				cancel()
+			}
+		}
+		mu.Unlock()
+		close(done[sr.Name])
+	}
+
+	for _, s := range w.steps {
+		wg.Add(1)
+		go func(s Step) {
+			defer wg.Done()
+
+			for _, dep := range w.deps[s.Name] {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+					finish(&StepResult{Name: s.Name, Err: fmt.Errorf("%w: %v", ErrStepSkipped, ctx.Err())})
+					return
+				}
+			}
+			mu.Lock()
+			data := TemplateData{Input: input, Steps: make(map[string]*StepResult, len(result.Steps))}
+			var failedDep string
+			for name, sr := range result.Steps {
+				data.Steps[name] = sr
+			}
+			for _, dep := range w.deps[s.Name] {
+				if result.Steps[dep].Err != nil {
+					failedDep = dep
+					break
+				}
+			}
+			mu.Unlock()
+			if failedDep != "" {
+				finish(&StepResult{Name: s.Name, Err: fmt.Errorf("%w: dependency %q failed", ErrStepSkipped, failedDep)})
+				return
+			}
+
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					finish(&StepResult{Name: s.Name, Err: fmt.Errorf("%w: %v", ErrStepSkipped, ctx.Err())})
+					return
+				}
+			}
+			finish(w.runStep(ctx, s, data))
+		}(s)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return result, firstErr
+	}
+	return result, ctx.Err()
+}
+
+func (w *Workflow) runStep(ctx context.Context, s Step, data TemplateData) *StepResult {
+	sr := &StepResult{Name: s.Name}
+	start := time.Now()
+	defer func() { sr.Duration = time.Since(start) }()
+
+	var buf bytes.Buffer
+	if err := w.prompts[s.Name].Execute(&buf, data); err != nil {
+		sr.Err = fmt.Errorf("render prompt: %w", err)
+		return sr
+	}
+	sr.Prompt = buf.String()
+
+	opts := make([]claudesdk.Option, 0, len(w.options)+len(s.Options)+4)
+	opts = append(opts, w.options...)
+	if s.Agents != nil {
+		opts = append(opts, claudesdk.WithAgents(s.Agents))
+	}
+	if s.OutputSchema != nil {
+		opts = append(opts, claudesdk.WithOutputFormat(map[string]interface{}{
+			"type":   "json_schema",
+			"schema": s.OutputSchema,
+		}))
+	}
+	if s.ResumeFrom != "" {
+		parent := data.Steps[s.ResumeFrom]
+		if parent == nil || parent.SessionID == "" {
+			sr.Err = fmt.Errorf("step %q has no session to resume", s.ResumeFrom)
+			return sr
+		}
+		opts = append(opts, claudesdk.WithResume(parent.SessionID), claudesdk.WithForkSession(s.Fork))
+	}
+	opts = append(opts, s.Options...)
+
+	runner := s.Runner
+	if runner == nil {
+		runner = w.runner
+	}
+	iter, err := runner(ctx, sr.Prompt, opts...)
+	if err != nil {
+		sr.Err = err
+		return sr
+	}
+	defer iter.Close()
+
+	for {
+		msg, err := iter.Next(ctx)
+		if err != nil {
+			if !errors.Is(err, claudesdk.ErrNoMoreMessages) {
+				sr.Err = err
+			}
+			break
+		}
+		sr.Messages = append(sr.Messages, msg)
+		if rm, ok := msg.(*claudesdk.ResultMessage); ok {
+			sr.Result = rm
+		}
+	}
+	if sr.Result == nil {
+		if sr.Err == nil {
+			sr.Err = fmt.Errorf("step ended without a result message")
+		}
+		return sr
+	}
+
+	sr.SessionID = sr.Result.SessionID
+	sr.Output = sr.Result.StructuredOutput
+	if sr.Result.Result != nil {
+		sr.Text = *sr.Result.Result
+	}
+	if sr.Result.TotalCostUSD != nil {
+		sr.CostUSD = *sr.Result.TotalCostUSD
+	}
+	if sr.Err == nil && sr.Result.IsError {
+		sr.Err = fmt.Errorf("step result %s", sr.Result.Subtype)
+	}
+	return sr
+}
+
+// ClientRunner runs a step on a fresh streaming Client instead of a one-shot
+// Query. Use it for steps that need Client-only features such as
+// claudesdk.WithCanUseTool.
+func ClientRunner(ctx context.Context, prompt string, opts ...claudesdk.Option) (claudesdk.MessageIterator, error) {
+	client := claudesdk.NewClient(opts...)
+	if err := client.Connect(ctx); err != nil {
+		return nil, err
+	}
+	if err := client.Query(ctx, prompt); err != nil {
+		_ = client.Disconnect()
+		return nil, err
+	}
+	return &clientStepIterator{client: client, iter: client.ReceiveResponse(ctx)}, nil
+}
+
+// clientStepIterator disconnects the client once the response is consumed.
+type clientStepIterator struct {
+	client claudesdk.Client
+	iter   claudesdk.MessageIterator
+	once   sync.Once
+}
+
+func (it *clientStepIterator) Next(ctx context.Context) (claudesdk.Message, error) {
+	return it.iter.Next(ctx)
+}
+
+func (it *clientStepIterator) Close() error {
+	var err error
+	it.once.Do(func() {
+		_ = it.iter.Close()
+		err = it.client.Disconnect()
+	})
+	return err
+}
+
+func (w *Workflow) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(w.deps))
+	names := make([]string, 0, len(w.deps))
+	for name := range w.deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var stack []string
+	var visit func(string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			for i, n := range stack {
+				if n == name {
+					return append(append([]string(nil), stack[i:]...), name)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range w.deps[name] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// stepReferences returns the step names tmpl reads from TemplateData.Steps,
+// as .Steps.<name>, $.Steps.<name> or index .Steps "<name>". Any other use
+// of .Steps, such as ranging over it, is an error because its result would
+// depend on which steps have finished.
+func stepReferences(tmpl *template.Template) ([]string, error) {
+	var refs []string
+	var walk func(node parse.Node, dotIsData bool) error
+	stepsField := func(node parse.Node, dotIsData bool) bool {
+		switch n := node.(type) {
+		case *parse.FieldNode:
+			return dotIsData && len(n.Ident) == 1 && n.Ident[0] == "Steps"
+		case *parse.VariableNode:
+			return len(n.Ident) == 2 && n.Ident[0] == "$" && n.Ident[1] == "Steps"
+		}
+		return false
+	}
+	walk = func(node parse.Node, dotIsData bool) error {
+		switch n := node.(type) {
+		case nil:
+			return nil
+		case *parse.ListNode:
+			if n == nil {
+				return nil
+			}
+			for _, child := range n.Nodes {
+				if err := walk(child, dotIsData); err != nil {
+					return err
+				}
+			}
+		case *parse.ActionNode:
+			return walk(n.Pipe, dotIsData)
+		case *parse.IfNode:
+			return walkBranch(&n.BranchNode, dotIsData, dotIsData, walk)
+		case *parse.RangeNode:
+			return walkBranch(&n.BranchNode, false, dotIsData, walk)
+		case *parse.WithNode:
+			return walkBranch(&n.BranchNode, false, dotIsData, walk)
+		case *parse.TemplateNode:
+			return walk(n.Pipe, dotIsData)
+		case *parse.PipeNode:
+			if n == nil {
+				return nil
+			}
+			for _, cmd := range n.Cmds {
+				if err := walk(cmd, dotIsData); err != nil {
+					return err
+				}
+			}
+		case *parse.CommandNode:
+			if len(n.Args) >= 3 {
+				if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "index" && stepsField(n.Args[1], dotIsData) {
+					name, ok := n.Args[2].(*parse.StringNode)
+					if !ok {
+						return fmt.Errorf("index of .Steps must be a constant step name")
+					}
+					refs = append(refs, name.Text)
+					for _, arg := range n.Args[3:] {
+						if err := walk(arg, dotIsData); err != nil {
+							return err
+						}
+					}
+					return nil
+				}
+			}
+			for _, arg := range n.Args {
+				if err := walk(arg, dotIsData); err != nil {
+					return err
+				}
+			}
+		case *parse.ChainNode:
+			return walk(n.Node, dotIsData)
+		case *parse.FieldNode:
+			if dotIsData && n.Ident[0] == "Steps" {
+				if len(n.Ident) == 1 {
+					return fmt.Errorf(".Steps must be followed by a step name")
+				}
+				refs = append(refs, n.Ident[1])
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" && n.Ident[1] == "Steps" {
+				if len(n.Ident) == 2 {
+					return fmt.Errorf("$.Steps must be followed by a step name")
+				}
+				refs = append(refs, n.Ident[2])
+			}
+		}
+		return nil
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		if err := walk(t.Tree.Root, true); err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
+
+// walkBranch walks an if, range or with node. The body sees dot as
+// TemplateData only when bodyDotIsData is set; the pipeline and else
+// branch see the enclosing dot.
+func walkBranch(n *parse.BranchNode, bodyDotIsData, dotIsData bool, walk func(parse.Node, bool) error) error {
+	if err := walk(n.Pipe, dotIsData); err != nil {
+		return err
+	}
+	if err := walk(n.List, bodyDotIsData); err != nil {
+		return err
+	}
+	if n.ElseList == nil {
+		return nil
+	}
+	return walk(n.ElseList, dotIsData)
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
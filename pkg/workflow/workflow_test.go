@@ -0,0 +1,268 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonnyquan/claude-agent-sdk-go/pkg/claudesdk"
+)
+
+type sliceIterator struct {
+	msgs []claudesdk.Message
+	pos  int
+}
+
+func (it *sliceIterator) Next(context.Context) (claudesdk.Message, error) {
+	if it.pos >= len(it.msgs) {
+		return nil, claudesdk.ErrNoMoreMessages
+	}
+	msg := it.msgs[it.pos]
+	it.pos++
+	return msg, nil
+}
+
+func (it *sliceIterator) Close() error { return nil }
+
+type call struct {
+	prompt  string
+	options *claudesdk.Options
+}
+
+// fakeRunner answers every prompt with a result whose text echoes the
+// prompt and whose session ID is "session-<n>".
+type fakeRunner struct {
+	mu     sync.Mutex
+	calls  []call
+	output map[string]any
+	fail   map[string]bool
+	delay  time.Duration
+}
+
+func (f *fakeRunner) run(ctx context.Context, prompt string, opts ...claudesdk.Option) (claudesdk.MessageIterator, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, call{prompt: prompt, options: claudesdk.NewOptions(opts...)})
+	n := len(f.calls)
+	f.mu.Unlock()
+
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	for key := range f.fail {
+		if strings.Contains(prompt, key) {
+			return nil, errors.New("runner failed")
+		}
+	}
+	text := "done: " + prompt
+	cost := 0.25
+	return &sliceIterator{msgs: []claudesdk.Message{&claudesdk.ResultMessage{
+		MessageType:      claudesdk.MessageTypeResult,
+		Subtype:          "success",
+		SessionID:        "session-" + string(rune('0'+n)),
+		Result:           &text,
+		TotalCostUSD:     &cost,
+		StructuredOutput: f.output,
+	}}}, nil
+}
+
+func (f *fakeRunner) callFor(prefix string) *call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.calls {
+		if strings.HasPrefix(f.calls[i].prompt, prefix) {
+			return &f.calls[i]
+		}
+	}
+	return nil
+}
+
+func TestWorkflowTemplatesResumeAndOutput(t *testing.T) {
+	runner := &fakeRunner{output: map[string]any{"steps": []any{"a", "b"}}}
+	wf, err := New([]Step{
+		{
+			Name:         "plan",
+			Prompt:       "plan {{.Input}}",
+			OutputSchema: map[string]any{"type": "object"},
+		},
+		{
+			Name:       "implement",
+			Prompt:     "implement {{json .Steps.plan.Output}}",
+			ResumeFrom: "plan",
+			Fork:       true,
+		},
+	}, WithRunner(runner.run))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	res, err := wf.Run(context.Background(), "feature")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	plan := runner.callFor("plan")
+	if plan == nil || plan.prompt != "plan feature" {
+		t.Fatalf("plan call = %+v", plan)
+	}
+	if plan.options.OutputFormat["type"] != "json_schema" {
+		t.Errorf("plan OutputFormat = %v", plan.options.OutputFormat)
+	}
+
+	impl := runner.callFor("implement")
+	if impl == nil || impl.prompt != `implement {"steps":["a","b"]}` {
+		t.Fatalf("implement call = %+v", impl)
+	}
+	if impl.options.Resume == nil || *impl.options.Resume != res.Step("plan").SessionID || !impl.options.ForkSession {
+		t.Errorf("implement did not fork plan session: resume=%v fork=%v", impl.options.Resume, impl.options.ForkSession)
+	}
+
+	type planOutput struct {
+		Steps []string `json:"steps"`
+	}
+	out, err := Output[planOutput](res, "plan")
+	if err != nil || len(out.Steps) != 2 {
+		t.Errorf("Output = %+v, %v", out, err)
+	}
+	if res.TotalCostUSD != 0.5 {
+		t.Errorf("TotalCostUSD = %v, want 0.5", res.TotalCostUSD)
+	}
+	if len(res.Order) != 2 || res.Order[0] != "plan" {
+		t.Errorf("Order = %v", res.Order)
+	}
+}
+
+func TestWorkflowRunsIndependentStepsInParallel(t *testing.T) {
+	runner := &fakeRunner{delay: 50 * time.Millisecond}
+	wf, err := New([]Step{
+		{Name: "a", Prompt: "a"},
+		{Name: "b", Prompt: "b"},
+		{Name: "c", Prompt: "c {{.Steps.a.Text}} {{.Steps.b.Text}}", DependsOn: []string{"a", "b"}},
+	}, WithRunner(runner.run))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	start := time.Now()
+	res, err := wf.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 140*time.Millisecond {
+		t.Errorf("elapsed %v, independent steps did not run in parallel", elapsed)
+	}
+	if got := res.Step("c").Prompt; got != "c done: a done: b" {
+		t.Errorf("c prompt = %q", got)
+	}
+}
+
+func TestWorkflowFailureSkipsDependents(t *testing.T) {
+	runner := &fakeRunner{fail: map[string]bool{"bad": true}}
+	wf, err := New([]Step{
+		{Name: "first", Prompt: "bad"},
+		{Name: "second", Prompt: "second", DependsOn: []string{"first"}},
+	}, WithRunner(runner.run))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	res, err := wf.Run(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), `"first"`) {
+		t.Fatalf("Run err = %v, want failure of first", err)
+	}
+	if !errors.Is(res.Step("second").Err, ErrStepSkipped) {
+		t.Errorf("second err = %v, want ErrStepSkipped", res.Step("second").Err)
+	}
+	if runner.callFor("second") != nil {
+		t.Error("dependent step ran after its dependency failed")
+	}
+}
+
+func TestWorkflowCancellation(t *testing.T) {
+	runner := &fakeRunner{delay: time.Second}
+	wf, err := New([]Step{{Name: "slow", Prompt: "slow"}}, WithRunner(runner.run))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	res, err := wf.Run(ctx, nil)
+	if err == nil {
+		t.Fatal("expected error from cancelled workflow")
+	}
+	if !errors.Is(res.Step("slow").Err, context.DeadlineExceeded) {
+		t.Errorf("slow err = %v", res.Step("slow").Err)
+	}
+}
+
+func TestNewValidatesGraph(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []Step
+		want  string
+	}{
+		{"duplicate", []Step{{Name: "a"}, {Name: "a"}}, "duplicate step"},
+		{"unknown dependency", []Step{{Name: "a", DependsOn: []string{"x"}}}, "unknown step"},
+		{"cycle", []Step{
+			{Name: "a", DependsOn: []string{"b"}},
+			{Name: "b", DependsOn: []string{"a"}},
+		}, "cycle"},
+		{"fork without resume", []Step{{Name: "a", Fork: true}}, "Fork without ResumeFrom"},
+		{"bad template", []Step{{Name: "a", Prompt: "{{.Input"}}, "prompt"},
+		{"reference without dependency", []Step{
+			{Name: "a"},
+			{Name: "b"},
+			{Name: "c", Prompt: "{{.Steps.a.Text}} {{.Steps.b.Text}}", DependsOn: []string{"a"}},
+		}, `references step "b"`},
+		{"variable reference", []Step{
+			{Name: "a"},
+			{Name: "b", Prompt: "{{with .Input}}{{$.Steps.a.Text}}{{end}}"},
+		}, `references step "a"`},
+		{"index reference", []Step{
+			{Name: "a"},
+			{Name: "b", Prompt: `{{(index .Steps "a").Text}}`},
+		}, `references step "a"`},
+		{"reference in defined template", []Step{
+			{Name: "a"},
+			{Name: "b", Prompt: `{{define "x"}}{{.Steps.a.Text}}{{end}}{{template "x" .}}`},
+		}, `references step "a"`},
+		{"range over steps", []Step{
+			{Name: "a"},
+			{Name: "b", Prompt: "{{range .Steps}}{{.Text}}{{end}}", DependsOn: []string{"a"}},
+		}, "must be followed by a step name"},
+		{"dynamic index", []Step{
+			{Name: "a", Prompt: "{{index .Steps .Input}}"},
+		}, "constant step name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.steps)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("New err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAcceptsReferencesToDependencies(t *testing.T) {
+	_, err := New([]Step{
+		{Name: "plan"},
+		{Name: "draft"},
+		{
+			Name:       "implement",
+			Prompt:     `{{.Steps.plan.Text}} {{index .Steps "draft" | json}} {{with .Input}}{{.Steps}}{{end}}`,
+			DependsOn:  []string{"draft"},
+			ResumeFrom: "plan",
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+}